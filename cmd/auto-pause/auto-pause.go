@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -38,14 +39,18 @@ var (
 	runtimePaused   bool
 	version         = "0.0.1"
 
-	runtime  = flag.String("container-runtime", "docker", "Container runtime to use for (un)pausing")
-	interval = flag.Duration("interval", time.Minute*1, "Interval of inactivity for pause to occur")
+	runtime    = flag.String("container-runtime", "docker", "Container runtime to use for (un)pausing")
+	interval   = flag.Duration("interval", time.Minute*1, "Interval of inactivity for pause to occur")
+	components = flag.String("components", "", "Comma separated list of the components to pause, all of the kube-system containers if empty. The kubelet is always stopped, so its liveness probes do not kill the paused containers, which makes the node NotReady: kube-controller-manager or kube-apiserver must be paused too, otherwise the pods of the node are evicted")
+	warmup     = flag.Duration("warmup", 0, "Minimum time after start before the first pause, so the workloads still initializing are not interrupted")
+
+	started = time.Now()
 )
 
 func main() {
 	flag.Parse()
 
-	tickerChannel := time.NewTicker(*interval)
+	tickerChannel := time.NewTicker(pauseDelay())
 
 	// Check current state
 	alreadyPaused()
@@ -61,7 +66,7 @@ func main() {
 				tickerChannel.Stop()
 				log.Println("Got request")
 				runUnpause()
-				tickerChannel.Reset(*interval)
+				tickerChannel.Reset(pauseDelay())
 
 				done <- struct{}{}
 			}
@@ -73,6 +78,15 @@ func main() {
 	log.Fatal(http.ListenAndServe("0.0.0.0:8080", nil))
 }
 
+// pauseDelay returns how long to wait before pausing: the interval of inactivity,
+// or longer if the warmup after start is not over by then
+func pauseDelay() time.Duration {
+	if left := *warmup - time.Since(started); left > *interval {
+		return left
+	}
+	return *interval
+}
+
 // handler echoes the Path component of the requested URL.
 func handler(w http.ResponseWriter, _ *http.Request) {
 	unpauseRequests <- struct{}{}
@@ -96,7 +110,11 @@ func runPause() {
 		exit.Error(reason.InternalNewRuntime, "Failed runtime", err)
 	}
 
-	uids, err := cluster.Pause(cr, r, []string{"kube-system"})
+	var pausable []string
+	if *components != "" {
+		pausable = strings.Split(*components, ",")
+	}
+	uids, err := cluster.PauseComponents(cr, r, pausable)
 	if err != nil {
 		exit.Error(reason.GuestPause, "Pause", err)
 	}
@@ -17,12 +17,20 @@ limitations under the License.
 package config
 
 import (
-	"net"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/minikube/pkg/addons"
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/config"
@@ -30,260 +38,568 @@ import (
 	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
-	"k8s.io/minikube/pkg/minikube/service"
 	"k8s.io/minikube/pkg/minikube/style"
 )
 
 var posResponses = []string{"yes", "y"}
 var negResponses = []string{"no", "n"}
 
-var addonsConfigureCmd = &cobra.Command{
-	Use:   "configure ADDON_NAME",
-	Short: "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list",
-	Long:  "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list",
-	Run: func(_ *cobra.Command, args []string) {
-		if len(args) != 1 {
-			exit.Message(reason.Usage, "usage: minikube addons configure ADDON_NAME")
-		}
-
-		profile := ClusterFlagValue()
+var (
+	registryCredsNamespace          string
+	registryCredsHealthCheckTimeout time.Duration
+	registryCredsDockerServerFlag   string
+	registryCredsDockerUserFlag     string
+	registryCredsPasswordStdin      bool
+	registryCredsShow               bool
+	registryCredsRefreshInterval    time.Duration
+	registryCredsImportDockerConfig string
+	configureTimeout                time.Duration
+	listBackups                     bool
+	restoreBackup                   string
+	configureSet                    []string
+)
 
-		addon := args[0]
-		// allows for additional prompting of information when enabling addons
-		switch addon {
-		case "registry-creds":
+// addonConfigurator configures an addon in two phases, so invalid input never leaves the addon partially configured
+type addonConfigurator interface {
+	// Validate gathers and checks all the input, without changing the profile or the cluster
+	Validate(profile string) error
+	// Apply changes the profile and the cluster, it is only called once Validate succeeded
+	Apply(ctx context.Context, profile string) (applyResult, error)
+}
 
-			// Default values
-			awsAccessID := "changeme"
-			awsAccessKey := "changeme"
-			awsSessionToken := ""
-			awsRegion := "changeme"
-			awsAccount := "changeme"
-			awsRole := "changeme"
-			gcrApplicationDefaultCredentials := "changeme"
-			dockerServer := "changeme"
-			dockerUser := "changeme"
-			dockerPass := "changeme"
-			gcrURL := "https://gcr.io"
-			acrURL := "changeme"
-			acrClientID := "changeme"
-			acrPassword := "changeme"
-
-			enableAWSECR := AskForYesNoConfirmation("\nDo you want to enable AWS Elastic Container Registry?", posResponses, negResponses)
-			if enableAWSECR {
-				awsAccessID = AskForStaticValue("-- Enter AWS Access Key ID: ")
-				awsAccessKey = AskForStaticValue("-- Enter AWS Secret Access Key: ")
-				awsSessionToken = AskForStaticValueOptional("-- (Optional) Enter AWS Session Token: ")
-				awsRegion = AskForStaticValue("-- Enter AWS Region: ")
-				awsAccount = AskForStaticValue("-- Enter 12 digit AWS Account ID (Comma separated list): ")
-				awsRole = AskForStaticValueOptional("-- (Optional) Enter ARN of AWS role to assume: ")
-			}
+// applyResult tells printConfigureNextStep what Apply did, and so what is left to do for the new configuration to take effect
+type applyResult struct {
+	// unchanged is set when nothing was applied, as the configuration is already in place or the user declined it
+	unchanged bool
+	// clusterStopped is set when the addon is configured while the cluster is not running
+	clusterStopped bool
+	// ingressCertExpiry is the expiry of the regenerated self-signed cert of the ingress addon
+	ingressCertExpiry time.Time
+	// ingressServicesExposed is set once TCP or UDP services have been exposed through the ingress controller
+	ingressServicesExposed bool
+	// ingressBackendChanged is set once the default backend of the ingress controller has been set or removed
+	ingressBackendChanged bool
+	// uiIngressHost is the host of the ingress exposing a UI addon
+	uiIngressHost string
+}
 
-			enableGCR := AskForYesNoConfirmation("\nDo you want to enable Google Container Registry?", posResponses, negResponses)
-			if enableGCR {
-				gcrPath := AskForStaticValue("-- Enter path to credentials (e.g. /home/user/.config/gcloud/application_default_credentials.json):")
-				gcrchangeURL := AskForYesNoConfirmation("-- Do you want to change the GCR URL (Default https://gcr.io)?", posResponses, negResponses)
+// configureAddon applies the configuration of the addon once all of its input is valid
+func configureAddon(profile string, c addonConfigurator) applyResult {
+	r, err := runConfigurator(profile, c)
+	if err != nil {
+		exitConfigure("failed to configure the addon", err)
+	}
+	return r
+}
 
-				if gcrchangeURL {
-					gcrURL = AskForStaticValue("-- Enter GCR URL (e.g. https://asia.gcr.io):")
-				}
+// runConfigurator validates then applies the configuration of the addon, the errors of Validate are ErrInvalidInput
+func runConfigurator(profile string, c addonConfigurator) (applyResult, error) {
+	if err := c.Validate(profile); err != nil {
+		return applyResult{}, classifyError(ErrInvalidInput, err)
+	}
+	ctx, cancel := clusterContext()
+	defer cancel()
+	return c.Apply(ctx, profile)
+}
 
-				// Read file from disk
-				dat, err := os.ReadFile(gcrPath)
+// clusterContext returns the context of the cluster interactions of configure, canceled on interrupt
+// and bounded by --timeout. It is created once the input has been gathered, so the time spent at the prompts does not count.
+func clusterContext() (context.Context, context.CancelFunc) {
+	if configureTimeout > 0 {
+		return context.WithTimeout(configureCtx, configureTimeout)
+	}
+	return context.WithCancel(configureCtx)
+}
 
-				if err != nil {
-					out.FailureT("Error reading {{.path}}: {{.error}}", out.V{"path": gcrPath, "error": err})
-				} else {
-					gcrApplicationDefaultCredentials = string(dat)
-				}
-			}
+// exitConfigure exits because a configure flow failed, telling apart the --timeout expiring and an interrupt,
+// and with the reason of the failure class of err otherwise
+func exitConfigure(msg string, err error) {
+	emitConfigureEvent("configure-failed", "", err)
+	if errors.Is(err, ErrInvalidInput) {
+		exit.Message(reason.Usage, "Invalid configuration: {{.error}}", out.V{"error": err})
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		exit.Message(reason.InternalAddonConfigure, "Timed out after {{.timeout}} waiting for the cluster: {{.error}}", out.V{"timeout": configureTimeout, "error": err})
+	}
+	if errors.Is(err, context.Canceled) {
+		exit.Message(reason.InternalAddonConfigure, "Interrupted while configuring the addon: {{.error}}", out.V{"error": err})
+	}
+	exit.Error(configureErrorReason(err), msg, err)
+}
 
-			enableDR := AskForYesNoConfirmation("\nDo you want to enable Docker Registry?", posResponses, negResponses)
-			if enableDR {
-				dockerServer = AskForStaticValue("-- Enter docker registry server url: ")
-				dockerUser = AskForStaticValue("-- Enter docker registry username: ")
-				dockerPass = AskForPasswordValue("-- Enter docker registry password: ")
-			}
+// configurableAddons lists the addons with a configure flow of their own, see the switch of addonsConfigureCmd
+var configurableAddons = []string{"registry-creds", "dashboard", "storage-provisioner", "gcp-auth", "efk", "metallb", "ingress", "registry-aliases", "auto-pause", "metrics-server", "cloud-spanner", "headlamp", "yakd", namespaceDefaultsTarget, nodeDefaultsTarget, corednsTarget}
 
-			enableACR := AskForYesNoConfirmation("\nDo you want to enable Azure Container Registry?", posResponses, negResponses)
-			if enableACR {
-				acrURL = AskForStaticValue("-- Enter Azure Container Registry (ACR) URL: ")
-				acrClientID = AskForStaticValue("-- Enter client ID (service principal ID) to access ACR: ")
-				acrPassword = AskForPasswordValue("-- Enter service principal password to access Azure Container Registry: ")
-			}
+// configurableAddonNames returns the sorted names of the addons which can be configured
+func configurableAddonNames() []string {
+	names := append([]string{}, configurableAddons...)
+	sort.Strings(names)
+	return names
+}
 
-			namespace := "kube-system"
-
-			// Create ECR Secret
-			err := service.CreateSecret(
-				profile,
-				namespace,
-				"registry-creds-ecr",
-				map[string]string{
-					"AWS_ACCESS_KEY_ID":     awsAccessID,
-					"AWS_SECRET_ACCESS_KEY": awsAccessKey,
-					"AWS_SESSION_TOKEN":     awsSessionToken,
-					"aws-account":           awsAccount,
-					"aws-region":            awsRegion,
-					"aws-assume-role":       awsRole,
-				},
-				map[string]string{
-					"app":                           "registry-creds",
-					"cloud":                         "ecr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
-			if err != nil {
-				out.FailureT("ERROR creating `registry-creds-ecr` secret: {{.error}}", out.V{"error": err})
-			}
+// pathFlags are the flags of configure whose value is the path of a file or a URL, they are never read as @FILE
+var pathFlags = map[string]bool{
+	"answers":              true,
+	"docker-ca-cert":       true,
+	"events":               true,
+	"file":                 true,
+	"import-docker-config": true,
+	"post-config-hook":     true,
+	"restore-backup":       true,
+	"save-answers":         true,
+}
 
-			// Create GCR Secret
-			err = service.CreateSecret(
-				profile,
-				namespace,
-				"registry-creds-gcr",
-				map[string]string{
-					"application_default_credentials.json": gcrApplicationDefaultCredentials,
-					"gcrurl":                               gcrURL,
-				},
-				map[string]string{
-					"app":                           "registry-creds",
-					"cloud":                         "gcr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
+// resolveFileFlags replaces the value of the string flags given as @FILE by the content of the file
+func resolveFileFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.Visit(func(f *pflag.Flag) {
+		if err != nil || pathFlags[f.Name] || f.Value.Type() != "string" {
+			return
+		}
+		v, rerr := resolveFileValue(f.Value.String())
+		if rerr == nil && v != f.Value.String() {
+			rerr = f.Value.Set(v)
+		}
+		if rerr != nil {
+			err = fmt.Errorf("--%s: %w", f.Name, rerr)
+		}
+	})
+	return err
+}
 
-			if err != nil {
-				out.FailureT("ERROR creating `registry-creds-gcr` secret: {{.error}}", out.V{"error": err})
+// printConfigureNextStep tells what is left to do for the new configuration of the addon to take effect.
+// If the addon is disabled and interactive is set, it offers to enable the addon now.
+func printConfigureNextStep(profile, addon string, interactive bool, r applyResult) {
+	_, cfg := mustload.Partial(profile)
+	a, ok := assets.Addons[addon]
+	if !ok {
+		return
+	}
+	if !a.IsEnabled(cfg) && !offerToEnableAddon(profile, addon, interactive) {
+		out.Styled(style.Tip, "Enable the addon to use the new configuration: minikube -p {{.profile}} addons enable {{.name}}", out.V{"profile": profile, "name": addon})
+		return
+	}
+	if r.clusterStopped {
+		out.Styled(style.Tip, "Start the cluster to apply the new configuration: minikube -p {{.profile}} start", out.V{"profile": profile})
+		return
+	}
+	switch addon {
+	case "registry-creds":
+		// the controller reads the credentials from its environment, which is only set when the pod starts
+		out.Styled(style.Tip, "Restart the registry-creds controller to load the new credentials, then restart the pods which failed to pull their images: kubectl --context {{.profile}} -n kube-system rollout restart deployment registry-creds", out.V{"profile": profile})
+	case "ingress":
+		if r.ingressBackendChanged {
+			if cfg.KubernetesConfig.CustomIngressBackend == "" {
+				out.Styled(style.Tip, "The requests matching no ingress rule are served by the default backend of the controller again")
+				return
 			}
+			out.Styled(style.Tip, "The requests matching no ingress rule are now served by {{.backend}}", out.V{"backend": cfg.KubernetesConfig.CustomIngressBackend})
+			return
+		}
+		if r.ingressServicesExposed {
+			out.Styled(style.Tip, "The services are reachable on their ports of the cluster IP: minikube -p {{.profile}} ip", out.V{"profile": profile})
+			return
+		}
+		if !r.ingressCertExpiry.IsZero() {
+			out.Styled(style.Tip, "The ingress controller serves the new self-signed cert, valid until {{.expiry}}", out.V{"expiry": r.ingressCertExpiry.Format(time.RFC1123)})
+			return
+		}
+		if cfg.KubernetesConfig.CustomIngressController != "" {
+			out.Styled(style.Tip, "The {{.controller}} controller has been restarted with the custom cert", out.V{"controller": cfg.KubernetesConfig.CustomIngressController})
+			return
+		}
+		out.Styled(style.Tip, "Re-enable the addon so its controller serves the custom cert: minikube -p {{.profile}} addons enable ingress", out.V{"profile": profile})
+	case "metallb":
+		out.Styled(style.Tip, "Services of type LoadBalancer now get their external IP from the new range: kubectl --context {{.profile}} get services -A", out.V{"profile": profile})
+	case "dashboard":
+		out.Styled(style.Tip, "Open the dashboard with: minikube -p {{.profile}} dashboard", out.V{"profile": profile})
+	case "metrics-server":
+		out.Styled(style.Tip, "The new settings are used from the next scrape on: kubectl --context {{.profile}} top pods -A", out.V{"profile": profile})
+	case "auto-pause":
+		if cfg.AutoPauseWarmup > 0 {
+			out.Styled(style.Tip, "The cluster is paused once it has been idle for {{.interval}}, and not before it has been running for {{.warmup}}", out.V{"interval": cfg.AutoPauseInterval, "warmup": cfg.AutoPauseWarmup})
+			return
+		}
+		out.Styled(style.Tip, "The cluster is paused once it has been idle for {{.interval}}", out.V{"interval": cfg.AutoPauseInterval})
+	case "registry-aliases":
+		out.Styled(style.Tip, "Restart the pods resolving the aliases to use the new ones")
+	case "storage-provisioner":
+		out.Styled(style.Tip, "The new reclaim policy and path only apply to the volumes provisioned from now on")
+	case "efk":
+		out.Styled(style.Tip, "Restart fluentd to ship the logs to the new endpoint: kubectl --context {{.profile}} -n kube-system delete pods -l k8s-app=fluentd-es", out.V{"profile": profile})
+	case "gcp-auth":
+		out.Styled(style.Tip, "Pods created from now on get their credentials from the webhook running the new image: kubectl --context {{.profile}} -n gcp-auth get pods", out.V{"profile": profile})
+	case "cloud-spanner":
+		out.Styled(style.Tip, "Pods get the emulator endpoint and the default project and instance from the cloud-spanner-emulator ConfigMap, eg. with envFrom: kubectl --context {{.profile}} get configmap cloud-spanner-emulator -o yaml", out.V{"profile": profile})
+	case "headlamp", "yakd":
+		out.Styled(style.Tip, "Open http://{{.host}} once {{.host}} resolves to the IP of the cluster (minikube -p {{.profile}} ip), eg. with the ingress-dns addon or /etc/hosts", out.V{"host": r.uiIngressHost, "profile": profile})
+	}
+}
 
-			// Create Docker Secret
-			err = service.CreateSecret(
-				profile,
-				namespace,
-				"registry-creds-dpr",
-				map[string]string{
-					"DOCKER_PRIVATE_REGISTRY_SERVER":   dockerServer,
-					"DOCKER_PRIVATE_REGISTRY_USER":     dockerUser,
-					"DOCKER_PRIVATE_REGISTRY_PASSWORD": dockerPass,
-				},
-				map[string]string{
-					"app":                           "registry-creds",
-					"cloud":                         "dpr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
-
-			if err != nil {
-				out.WarningT("ERROR creating `registry-creds-dpr` secret")
-			}
+// offerToEnableAddon warns that the configuration of the disabled addon is saved but not used,
+// and offers to enable the addon if interactive is set. It returns whether the addon was enabled.
+func offerToEnableAddon(profile, addon string, interactive bool) bool {
+	out.WarningT("The {{.name}} addon is disabled, its configuration is saved but won't take effect until it is enabled", out.V{"name": addon})
+	if !interactive || configureQuiet || !AskForYesNoConfirmation("Do you want to enable "+addon+" now?", posResponses, negResponses) {
+		return false
+	}
+	if err := configureClient.SetAddonEnabled(profile, addon); err != nil {
+		out.FailureT("Failed to enable {{.name}}: {{.error}}", out.V{"name": addon, "error": err})
+		return false
+	}
+	recordApplied("enabled the " + addon + " addon")
+	out.Step(style.AddonEnable, "The '{{.addonName}}' addon is enabled", out.V{"addonName": addon})
+	return true
+}
 
-			// Create Azure Container Registry Secret
-			err = service.CreateSecret(
-				profile,
-				namespace,
-				"registry-creds-acr",
-				map[string]string{
-					"ACR_URL":       acrURL,
-					"ACR_CLIENT_ID": acrClientID,
-					"ACR_PASSWORD":  acrPassword,
-				},
-				map[string]string{
-					"app":                           "registry-creds",
-					"cloud":                         "acr",
-					"kubernetes.io/minikube-addons": "registry-creds",
-				})
+// validateNamespacedName checks if s is a "namespace/name" reference, eg. the ingress cert secret,
+// made of a DNS-1123 namespace and a DNS-1123 name separated by a single slash
+func validateNamespacedName(s string) error {
+	namespace, name, found := strings.Cut(s, "/")
+	if !found || strings.Contains(name, "/") {
+		return fmt.Errorf("expected a single slash between the namespace and the name, eg. kube-system/mkcert")
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid name %q: %s", name, strings.Join(errs, ", "))
+	}
+	return nil
+}
 
-			if err != nil {
-				out.WarningT("ERROR creating `registry-creds-acr` secret")
-			}
+// registryAliasPattern matches a registry alias, a hostname made of one or more labels with an optional :port
+var registryAliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9-_]*[a-zA-Z0-9_])?(\.[a-zA-Z0-9_]([a-zA-Z0-9-_]*[a-zA-Z0-9_])?)*(:[0-9]+)?$`)
+
+// validateRegistryAlias checks a single registry alias, eg. "registry.dev", "localhost" or "registry:5000"
+func validateRegistryAlias(alias string) error {
+	if !registryAliasPattern.MatchString(alias) {
+		return fmt.Errorf("invalid registry alias %q", alias)
+	}
+	if _, port, found := strings.Cut(alias, ":"); found {
+		if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("invalid port of registry alias %q", alias)
+		}
+	}
+	return nil
+}
 
-		case "metallb":
-			_, cfg := mustload.Partial(profile)
+// validateRegistryAliases checks each of the registry aliases separated by space,
+// once $VAR and ${VAR} have been expanded from the environment
+func validateRegistryAliases(s string) error {
+	expanded, err := expandEnv(s)
+	if err != nil {
+		return err
+	}
+	aliases := strings.Fields(expanded)
+	if len(aliases) == 0 {
+		return fmt.Errorf("expected registry aliases separated by space")
+	}
+	for _, alias := range aliases {
+		if err := validateRegistryAlias(alias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			validator := func(s string) bool {
-				return net.ParseIP(s) != nil
-			}
+// registryAliasHosts returns the hosts of the registry aliases separated by space, as they are added to /etc/hosts
+// of the nodes, which do not resolve ports
+func registryAliasHosts(s string) (string, error) {
+	expanded, err := expandEnv(s)
+	if err != nil {
+		return "", err
+	}
+	var hosts []string
+	for _, alias := range strings.Fields(expanded) {
+		host, _, _ := strings.Cut(alias, ":")
+		hosts = append(hosts, host)
+	}
+	return strings.Join(hosts, " "), nil
+}
 
-			cfg.KubernetesConfig.LoadBalancerStartIP = AskForStaticValidatedValue("-- Enter Load Balancer Start IP: ", validator)
+// expandEnv replaces $VAR and ${VAR} with the values of the environment, failing on undefined variables
+func expandEnv(s string) (string, error) {
+	var missing []string
+	expanded := os.Expand(s, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
 
-			cfg.KubernetesConfig.LoadBalancerEndIP = AskForStaticValidatedValue("-- Enter Load Balancer End IP: ", validator)
+// configureProfile returns the profile to configure. Without an explicit profile, the sole existing profile is
+// picked, or the user chooses one when there are several.
+func configureProfile() string {
+	if viper.IsSet(config.ProfileName) {
+		return ClusterFlagValue()
+	}
+	profiles, err := config.ListValidProfiles()
+	if err != nil || len(profiles) == 0 {
+		return ClusterFlagValue()
+	}
+	if len(profiles) == 1 {
+		name := profiles[0].Name
+		out.Styled(style.Notice, "Using the only existing profile {{.profile}}", out.V{"profile": name})
+		return name
+	}
+	names := []string{}
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	return AskForChoice("-- Which profile do you want to configure? ", names)
+}
 
-			if err := config.SaveProfile(profile, cfg); err != nil {
-				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+var addonsConfigureCmd = &cobra.Command{
+	Use:   "configure ADDON_NAME",
+	Short: "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list",
+	Long:  "Configures the addon w/ADDON_NAME within minikube (example: minikube addons configure registry-creds). For a list of available addons use: minikube addons list. Use namespace-defaults as ADDON_NAME to set the default CPU and memory of the containers of a namespace, and optionally its quota. Use node-defaults as ADDON_NAME to set or remove the labels and taints of the nodes. Use coredns as ADDON_NAME to add host entries and forward rules to CoreDNS. Configuring headlamp or yakd exposes their UI through an ingress with a chosen host. Any answer to a prompt, --set value or flag value, eg. a password, is read from a file when given as @FILE, a value starting with a literal @ is given as @@VALUE. The metallb, registry-aliases, auto-pause, metrics-server and cloud-spanner addons, and --set or --edit, can be configured while the cluster is stopped: the configuration is applied on the next start.",
+	ValidArgsFunction: func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var names []string
+		for _, name := range configurableAddonNames() {
+			if strings.HasPrefix(name, toComplete) {
+				names = append(names, name)
 			}
-
-			// Re-enable metallb addon in order to generate template manifest files with Load Balancer Start/End IP
-			if err := addons.EnableOrDisableAddon(cfg, "metallb", "true"); err != nil {
-				out.ErrT(style.Fatal, "Failed to configure metallb IP {{.profile}}", out.V{"profile": profile})
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		handleConfigureInterrupt()
+		if err := resolveFileFlags(cmd.Flags()); err != nil {
+			exit.Message(reason.Usage, "Invalid flag value: {{.error}}", out.V{"error": err})
+		}
+		if configureList {
+			if len(args) > 1 {
+				exit.Message(reason.Usage, "usage: minikube addons configure [ADDON_NAME] --list")
 			}
-		case "ingress":
-			_, cfg := mustload.Partial(profile)
-
-			validator := func(s string) bool {
-				format := regexp.MustCompile("^.+/.+$")
-				return format.MatchString(s)
+			only := ""
+			if len(args) == 1 {
+				only = args[0]
 			}
-
-			customCert := AskForStaticValidatedValue("-- Enter custom cert (format is \"namespace/secret\"): ", validator)
-			if cfg.KubernetesConfig.CustomIngressCert != "" {
-				overwrite := AskForYesNoConfirmation("A custom cert for ingress has already been set. Do you want overwrite it?", posResponses, negResponses)
-				if !overwrite {
-					break
-				}
+			printConfigurableAddons(only, configureListOutput)
+			return
+		}
+		if configureQuiet {
+			out.SetSilent(true)
+		}
+		if err := setupConfigureEvents(configureEvents); err != nil {
+			exit.Message(reason.Usage, "Invalid --events: {{.error}}", out.V{"error": err})
+		}
+		configureClient = undoClient{configureClient}
+		if configureEventSink != nil {
+			configureClient = eventingClient{configureClient}
+		}
+		if postConfigHook != "" && !validateOnly {
+			if err := validatePostConfigHook(postConfigHook); err != nil {
+				exit.Message(reason.Usage, "Invalid --post-config-hook: {{.error}}", out.V{"error": err})
 			}
-
-			cfg.KubernetesConfig.CustomIngressCert = customCert
-
-			if err := config.SaveProfile(profile, cfg); err != nil {
-				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+		}
+		if saveAnswersFlag != "" && !validateOnly {
+			if err := validateAnswerProfileName(saveAnswersFlag); err != nil {
+				exit.Message(reason.Usage, "Invalid --save-answers: {{.error}}", out.V{"error": err})
 			}
-		case "registry-aliases":
-			_, cfg := mustload.Partial(profile)
-			validator := func(s string) bool {
-				format := regexp.MustCompile(`^([a-zA-Z0-9-_]+\.[a-zA-Z0-9-_]+)+(\ [a-zA-Z0-9-_]+\.[a-zA-Z0-9-_]+)*$`)
-				return format.MatchString(s)
+		}
+		if validateOnly && configureFile != "" {
+			f, err := loadAddonsConfigFile(configureFile)
+			if err != nil {
+				exit.Message(reason.Usage, "Invalid configuration file: {{.error}}", out.V{"error": err})
 			}
-			registryAliases := AskForStaticValidatedValue("-- Enter registry aliases separated by space: ", validator)
-			cfg.KubernetesConfig.RegistryAliases = registryAliases
-
-			if err := config.SaveProfile(profile, cfg); err != nil {
-				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+			reportConfigureValidation(validateAddonsConfigFile(f))
+			return
+		}
+		if validateOnly {
+			if len(args) != 1 {
+				exit.Message(reason.Usage, "usage: minikube addons configure ADDON_NAME")
 			}
-			addon := assets.Addons["registry-aliases"]
-			if addon.IsEnabled(cfg) {
-				// Re-enable registry-aliases addon in order to generate template manifest files with custom hosts
-				if err := addons.EnableOrDisableAddon(cfg, "registry-aliases", "true"); err != nil {
-					out.ErrT(style.Fatal, "Failed to configure registry-aliases {{.profile}}", out.V{"profile": profile})
-				}
+			reportConfigureValidation(validateConfigureFlags(args[0]))
+			return
+		}
+		if answersFlag != "" {
+			if err := loadAnswerProfile(answersFlag); err != nil {
+				exit.Message(reason.Usage, "Invalid --answers: {{.error}}", out.V{"error": err})
 			}
-		case "auto-pause":
-			_, cfg := mustload.Partial(profile)
-			intervalInput := AskForStaticValue("-- Enter interval time of auto-pause-interval (ex. 1m0s): ")
-			intervalTime, err := time.ParseDuration(intervalInput)
+		}
+		if multiProfile() {
+			if err := validateMultiProfileFlags(); err != nil {
+				exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+			}
+			profiles, err := targetProfiles()
 			if err != nil {
-				out.ErrT(style.Fatal, "Interval is an invalid duration: {{.error}}", out.V{"error": err})
+				exit.Message(reason.Usage, "Unable to configure the profiles: {{.error}}", out.V{"error": err})
 			}
-			if intervalTime != intervalTime.Abs() || intervalTime.String() == "0s" {
-				out.ErrT(style.Fatal, "Interval must be greater than 0s")
+			configureMultipleProfiles(profiles, args)
+			return
+		}
+		profile := configureProfile()
+		setConfigureEventScope(profile, "")
+		if listBackups {
+			listProfileBackups(profile)
+			return
+		}
+		if restoreBackup != "" {
+			restoreProfileBackup(profile, restoreBackup)
+			return
+		}
+
+		if configureFile != "" {
+			configureFromFile(profile, configureFile)
+			return
+		}
+
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "usage: minikube addons configure ADDON_NAME")
+		}
+
+		addon := args[0]
+		setConfigureEventScope(profile, addon)
+		running := checkClusterRunning(profile, addon)
+		if running {
+			ensureNotPaused(profile)
+		}
+		if undoLast {
+			undoLastConfigure(profile, addon, running)
+			return
+		}
+		warnAddonConflicts(profile, addon)
+		if configureEdit {
+			if len(configureSet) > 0 {
+				exit.Message(reason.Usage, "--edit and --set can not be used together")
 			}
-			cfg.AutoPauseInterval = intervalTime
-			if err := config.SaveProfile(profile, cfg); err != nil {
-				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+			if !editAddonConfig(profile, addon) {
+				return
 			}
-			addon := assets.Addons["auto-pause"]
-			if addon.IsEnabled(cfg) {
-				// Re-enable auto-pause addon in order to update interval time
-				if err := addons.EnableOrDisableAddon(cfg, "auto-pause", "true"); err != nil {
-					out.ErrT(style.Fatal, "Failed to configure auto-pause {{.profile}}", out.V{"profile": profile})
-				}
+			emitConfigureEvent("configured", addon, nil)
+			saveUndoRecord(profile, addon)
+			out.SuccessT("{{.name}} was successfully configured", out.V{"name": addon})
+			printConfigureNextStep(profile, addon, true, applyResult{clusterStopped: !running})
+			runPostConfigHook(profile, addon)
+			return
+		}
+		if len(configureSet) > 0 {
+			setAddonConfig(profile, addon, configureSet)
+			emitConfigureEvent("configured", addon, nil)
+			saveUndoRecord(profile, addon)
+			out.SuccessT("{{.name}} was successfully configured", out.V{"name": addon})
+			printConfigureNextStep(profile, addon, false, applyResult{clusterStopped: !running})
+			runPostConfigHook(profile, addon)
+			return
+		}
+		// allows for additional prompting of information when enabling addons
+		var result applyResult
+		switch addon {
+		case "registry-creds":
+			if registryCredsShow {
+				showRegistryCredsConfig(profile, registryCredsNamespace)
+				return
+			}
+			result = configureAddon(profile, &registryCredsConfigurator{})
+		case "dashboard":
+			result = configureAddon(profile, &dashboardConfigurator{})
+		case "storage-provisioner":
+			processStorageProvisionerConfig(profile)
+		case "gcp-auth":
+			processGCPAuthConfig(profile)
+		case "efk":
+			processEFKConfig(profile)
+		case namespaceDefaultsTarget:
+			result = configureAddon(profile, &namespaceDefaultsConfigurator{})
+		case nodeDefaultsTarget:
+			result = configureAddon(profile, &nodeDefaultsConfigurator{})
+		case corednsTarget:
+			result = configureAddon(profile, &corednsConfigurator{})
+		case "headlamp", "yakd":
+			result = configureAddon(profile, &uiIngressConfigurator{addon: addon})
+		case "metallb":
+			result = configureAddon(profile, &metallbConfigurator{})
+		case "ingress":
+			result = configureAddon(profile, &ingressConfigurator{})
+		case "registry-aliases":
+			_, cfg := mustload.Partial(profile)
+			registryAliases := AskForStaticCheckedValue("-- Enter registry aliases separated by space ($VAR and ${VAR} are expanded): ", validateRegistryAliases)
+			cfg.KubernetesConfig.RegistryAliases, _ = registryAliasHosts(registryAliases)
+
+			// Re-enable registry-aliases addon in order to generate template manifest files with custom hosts
+			if err := saveAndReenableAddon(profile, cfg, "registry-aliases", false); err != nil {
+				exit.Error(reason.InternalAddonConfigure, "Failed to configure registry-aliases", err)
 			}
+		case "auto-pause":
+			result = configureAddon(profile, &autoPauseConfigurator{})
+		case "metrics-server":
+			result = configureAddon(profile, &metricsServerConfigurator{})
+		case "cloud-spanner":
+			processCloudSpannerConfig(profile)
 		default:
 			out.FailureT("{{.name}} has no available configuration options", out.V{"name": addon})
 			return
 		}
+		if result.unchanged {
+			return
+		}
+		result.clusterStopped = !running
 
+		emitConfigureEvent("configured", addon, nil)
+		saveUndoRecord(profile, addon)
 		out.SuccessT("{{.name}} was successfully configured", out.V{"name": addon})
+		printConfigureNextStep(profile, addon, true, result)
+		if saveAnswersFlag != "" {
+			if err := saveAnswerProfile(saveAnswersFlag); err != nil {
+				out.FailureT("Unable to save the answers: {{.error}}", out.V{"error": err})
+			}
+		}
+		runPostConfigHook(profile, addon)
+	},
+	PostRun: func(_ *cobra.Command, args []string) {
+		// the result of several profiles is printed once they are configured, even if some failed
+		if !configureQuiet || configureList || multiProfile() {
+			return
+		}
+		addon := ""
+		if len(args) == 1 {
+			addon = args[0]
+		}
+		printConfigureResult(addon)
 	},
 }
 
 func init() {
+	addonsConfigureCmd.Flags().StringVar(&registryCredsNamespace, "namespace", "kube-system", "Namespace the registry-creds secrets are created in, it is created if it does not exist")
+	addonsConfigureCmd.Flags().DurationVar(&registryCredsHealthCheckTimeout, "health-check-timeout", 0, "If greater than 0, wait up to this long for the registry-creds controller and check its logs for credential errors")
+	addonsConfigureCmd.Flags().StringVar(&registryCredsDockerServerFlag, "docker-server", "", "Docker registry server of registry-creds, skips the prompts when set")
+	addonsConfigureCmd.Flags().StringVar(&registryCredsDockerUserFlag, "docker-user", "", "Docker registry username of registry-creds, used with --docker-server")
+	addonsConfigureCmd.Flags().BoolVar(&registryCredsPasswordStdin, "docker-password-stdin", false, "Read the docker registry password of registry-creds from stdin, used with --docker-server")
+	addonsConfigureCmd.Flags().StringVar(&registryCredsDockerCACert, "docker-ca-cert", "", "Path of the PEM CA cert of the registry given with --docker-server, trusted by registry-creds and the container runtime of the nodes")
+	addonsConfigureCmd.Flags().BoolVar(&registryCredsDockerInsecure, "docker-insecure", false, "Skip the verification of the cert of the registry given with --docker-server, in registry-creds and the container runtime of the nodes")
+	addonsConfigureCmd.Flags().DurationVar(&registryCredsRefreshInterval, "refresh-interval", 0, "How often the registry-creds controller refreshes the credentials, in whole minutes from 1m to 12h, skips the prompt when set")
+	addonsConfigureCmd.Flags().StringVar(&registryCredsImportDockerConfig, "import-docker-config", "", "Import the docker registry of registry-creds from a docker config written by docker login, eg. ~/.docker/config.json, the first one when it holds several, skips the prompts when set")
+	addonsConfigureCmd.Flags().BoolVar(&registryCredsRuntimeConfig, "runtime-registry-config", false, "Also write the config of the docker registry of registry-creds to the containerd or CRI-O registry config of the nodes, then restart the container runtime")
+	addonsConfigureCmd.Flags().BoolVar(&registryCredsShow, "show", false, "Show the registries currently configured by registry-creds, with the secrets redacted")
+	addonsConfigureCmd.Flags().BoolVar(&addons.Force, "force", false, "If true, will perform potentially dangerous operations. Currently used to accept a metallb range conflicting with the cluster network")
+	addonsConfigureCmd.Flags().DurationVar(&configureTimeout, "timeout", 0, "If greater than 0, the maximum time spent on the cluster operations of configure, not counting the prompts")
+	addonsConfigureCmd.Flags().BoolVar(&listBackups, "list-backups", false, "List the config backups of the profile written by previous configure runs")
+	addonsConfigureCmd.Flags().BoolVar(&undoLast, "undo", false, "Revert the last configuration of the addon: restore the config of the profile and the secrets as they were before it, then re-enable the addon")
+	addonsConfigureCmd.Flags().StringVar(&restoreBackup, "restore-backup", "", "Restore the config of the profile from one of the backups listed by --list-backups")
+	addonsConfigureCmd.Flags().BoolVar(&configureEdit, "edit", false, "Edit the settings of the addon in $EDITOR instead of prompting for them, the same settings as --set")
+	addonsConfigureCmd.Flags().StringArrayVar(&configureSet, "set", nil, "Set a setting of the addon as key=value instead of prompting for them, can be repeated")
+	addonsConfigureCmd.Flags().StringVar(&configureEvents, "events", "", "Send a JSON event for each change applied by configure, and its outcome, to stderr or to the http or https URL of a webhook")
+	addonsConfigureCmd.Flags().StringVar(&configureFile, "file", "", "Configure the addons listed in a YAML file, in order, once all of them are valid")
+	addonsConfigureCmd.Flags().BoolVar(&registryCredsVerify, "verify-credentials", false, "Log in to the registries of registry-creds with the entered credentials before creating the secrets, requires access to the registries")
+	addonsConfigureCmd.Flags().StringVar(&postConfigHook, "post-config-hook", "", "Executable run once an addon is successfully configured, with the addon and the profile as arguments and as the MINIKUBE_ADDON and MINIKUBE_PROFILE environment variables")
+	addonsConfigureCmd.Flags().StringVar(&answersFlag, "answers", "", "Use the answers saved with --save-answers under this name, or those of a YAML file, as the defaults of the prompts, accepted by pressing Enter")
+	addonsConfigureCmd.Flags().StringVar(&saveAnswersFlag, "save-answers", "", "Save the answers to the prompts under this name in the minikube home directory, to be used as defaults with --answers. Passwords are not saved")
+	addonsConfigureCmd.Flags().BoolVar(&configureList, "list", false, "List the configurable addons, or only ADDON_NAME, with the settings accepted by --set and whether configuring them changes the cluster")
+	addonsConfigureCmd.Flags().StringVarP(&configureListOutput, "output", "o", "list", "Output format of --list. One of: list, json")
+	addonsConfigureCmd.Flags().BoolVar(&configureQuiet, "quiet", false, "Only print the errors and, once configured, the applied changes as JSON. Nothing is prompted for: the answers are taken from --answers, and configure fails if one is missing")
+	addonsConfigureCmd.Flags().StringSliceVar(&configureProfiles, "profiles", nil, "Apply the configuration given with --set or --file to each of these comma separated profiles, going on with the next profile when one fails")
+	addonsConfigureCmd.Flags().BoolVar(&configureAllProfiles, "all-profiles", false, "Apply the configuration given with --set or --file to every valid profile, going on with the next profile when one fails")
+	addonsConfigureCmd.Flags().BoolVar(&validateOnly, "validate-only", false, "Only check the addon name and the flags, without reading or changing the profile or the cluster")
 	AddonsCmd.AddCommand(addonsConfigureCmd)
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	answersFlag     string
+	saveAnswersFlag string
+)
+
+// answerProfileNamePattern matches the name of an answer profile, used as its file name
+var answerProfileNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// promptAnswers are the answers of the prompts keyed by prompt, in the order the prompt was asked,
+// so the prompts asked in a loop (eg. each docker registry) get the answer of the same iteration
+type promptAnswers map[string][]string
+
+// answers holds the answers of the current run: the saved ones used as the defaults of the prompts,
+// and those given so far. Passwords are never saved nor offered as defaults.
+var answers struct {
+	defaults promptAnswers
+	given    promptAnswers
+}
+
+// answerKey returns the key of a prompt, without its surrounding spaces and newlines
+func answerKey(prompt string) string {
+	return strings.TrimSpace(prompt)
+}
+
+// promptDefault returns the saved answer of the prompt for the next time it is asked, if any
+func promptDefault(prompt string) string {
+	k := answerKey(prompt)
+	i := len(answers.given[k])
+	if i >= len(answers.defaults[k]) {
+		return ""
+	}
+	return answers.defaults[k][i]
+}
+
+// acceptAnswer records the accepted answer of the prompt, to be saved with --save-answers
+func acceptAnswer(prompt, answer string) {
+	if answers.given == nil {
+		answers.given = promptAnswers{}
+	}
+	k := answerKey(prompt)
+	// an answer read from a file is saved as the file, so the file is read again when the answer is used
+	if k == fileValue.prompt && answer == fileValue.value {
+		answer = fileValue.ref
+	}
+	answers.given[k] = append(answers.given[k], answer)
+}
+
+// withDefault returns the prompt showing its default before its trailing ":" or "?", eg. "-- Enter CPU request [100m]: "
+func withDefault(prompt, def string) string {
+	if def == "" {
+		return prompt
+	}
+	t := strings.TrimRight(prompt, " ")
+	if strings.HasSuffix(t, ":") || strings.HasSuffix(t, "?") {
+		return fmt.Sprintf("%s [%s]%s ", t[:len(t)-1], def, t[len(t)-1:])
+	}
+	return fmt.Sprintf("%s [%s] ", t, def)
+}
+
+// answerProfilePath returns the path of an answer profile: a YAML file if the value is a path, otherwise
+// the file of the profile of that name saved in the minikube home directory
+func answerProfilePath(nameOrPath string) string {
+	if strings.ContainsAny(nameOrPath, `/\`) || strings.HasSuffix(nameOrPath, ".yaml") || strings.HasSuffix(nameOrPath, ".yml") {
+		return expandPath(nameOrPath)
+	}
+	return localpath.MakeMiniPath("config", "answers", nameOrPath+".yaml")
+}
+
+// validateAnswerProfileName checks the name given to --save-answers, which is used as a file name
+func validateAnswerProfileName(name string) error {
+	if !answerProfileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid answer profile name %q, use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// readAnswerProfile reads the answers of an answer profile
+func readAnswerProfile(path string) (promptAnswers, error) {
+	data, err := readTextFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a := promptAnswers{}
+	if err := yaml.UnmarshalStrict(data, &a); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// loadAnswerProfile makes the answers of the profile given with --answers the defaults of the prompts
+func loadAnswerProfile(nameOrPath string) error {
+	path := answerProfilePath(nameOrPath)
+	a, err := readAnswerProfile(path)
+	if err != nil {
+		return err
+	}
+	answers.defaults = a
+	out.Styled(style.Notice, "Using the answers of {{.path}} as defaults, press Enter to accept them", out.V{"path": path})
+	return nil
+}
+
+// saveAnswerProfile saves the answers given in this run under the name given with --save-answers. The answers of the
+// prompts which were not asked, eg. those of the other addons, are kept from the existing profile.
+func saveAnswerProfile(name string) error {
+	path := answerProfilePath(name)
+	saved := promptAnswers{}
+	if _, err := os.Stat(path); err == nil {
+		if saved, err = readAnswerProfile(path); err != nil {
+			return err
+		}
+	}
+	for k, v := range answers.given {
+		saved[k] = v
+	}
+	data, err := yaml.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	out.Styled(style.Check, "Saved the answers to {{.path}}, reuse them with --answers {{.name}}", out.V{"path": path, "name": name})
+	return nil
+}
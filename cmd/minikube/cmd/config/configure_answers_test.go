@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// resetAnswers clears the answers of the run when the test ends
+func resetAnswers(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		answers.defaults = nil
+		answers.given = nil
+	})
+}
+
+func TestWithDefault(t *testing.T) {
+	tests := []struct {
+		prompt string
+		def    string
+		want   string
+	}{
+		{prompt: "-- Enter CPU request (ex. 100m): ", def: "200m", want: "-- Enter CPU request (ex. 100m) [200m]: "},
+		{prompt: "-- Which profile do you want to configure? ", def: "dev", want: "-- Which profile do you want to configure [dev]? "},
+		{prompt: "-- Enter a value ", def: "x", want: "-- Enter a value [x] "},
+		{prompt: "-- Enter CPU request (ex. 100m): ", want: "-- Enter CPU request (ex. 100m): "},
+	}
+	for _, tc := range tests {
+		if got := withDefault(tc.prompt, tc.def); got != tc.want {
+			t.Errorf("withDefault(%q, %q) = %q, want %q", tc.prompt, tc.def, got, tc.want)
+		}
+	}
+}
+
+func TestPromptDefault(t *testing.T) {
+	resetAnswers(t)
+	answers.defaults = promptAnswers{"-- Enter docker registry server url:": {"a.example.com", "b.example.com"}}
+
+	prompt := "-- Enter docker registry server url: "
+	for _, want := range []string{"a.example.com", "b.example.com", ""} {
+		if got := promptDefault(prompt); got != want {
+			t.Errorf("promptDefault(%q) = %q, want %q", prompt, got, want)
+		}
+		acceptAnswer(prompt, "c.example.com")
+	}
+	if got := promptDefault("\nDo you want to enable Docker Registry?"); got != "" {
+		t.Errorf("expected no default for a prompt without saved answers, got %q", got)
+	}
+}
+
+func TestAcceptFileAnswer(t *testing.T) {
+	resetAnswers(t)
+	t.Cleanup(func() { fileValue.prompt, fileValue.ref, fileValue.value = "", "", "" })
+
+	prompt := "-- Enter docker registry username: "
+	rememberFileValue(prompt, "@/run/secrets/user", "me")
+	acceptAnswer(prompt, "me")
+	acceptAnswer("-- Enter docker registry server url: ", "me")
+	want := promptAnswers{
+		"-- Enter docker registry username:":   {"@/run/secrets/user"},
+		"-- Enter docker registry server url:": {"me"},
+	}
+	if !reflect.DeepEqual(answers.given, want) {
+		t.Errorf("given answers %v, want %v", answers.given, want)
+	}
+}
+
+func TestSaveAnswerProfile(t *testing.T) {
+	resetAnswers(t)
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+
+	answers.given = promptAnswers{"-- Enter CPU request (ex. 100m):": {"100m"}}
+	if err := saveAnswerProfile("team"); err != nil {
+		t.Fatalf("saveAnswerProfile() returned unexpected error: %v", err)
+	}
+	answers.given = promptAnswers{"-- Enter memory request (ex. 200Mi):": {"300Mi"}}
+	if err := saveAnswerProfile("team"); err != nil {
+		t.Fatalf("saveAnswerProfile() returned unexpected error: %v", err)
+	}
+
+	if err := loadAnswerProfile("team"); err != nil {
+		t.Fatalf("loadAnswerProfile() returned unexpected error: %v", err)
+	}
+	want := promptAnswers{
+		"-- Enter CPU request (ex. 100m):":     {"100m"},
+		"-- Enter memory request (ex. 200Mi):": {"300Mi"},
+	}
+	if !reflect.DeepEqual(answers.defaults, want) {
+		t.Errorf("loaded answers %v, want %v", answers.defaults, want)
+	}
+}
+
+func TestAnswerProfilePath(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	if got, want := answerProfilePath("team"), localpath.MakeMiniPath("config", "answers", "team.yaml"); got != want {
+		t.Errorf("answerProfilePath(%q) = %q, want %q", "team", got, want)
+	}
+	path := filepath.Join(t.TempDir(), "answers.yaml")
+	if got := answerProfilePath(path); got != path {
+		t.Errorf("answerProfilePath(%q) = %q, want the path itself", path, got)
+	}
+	for _, name := range []string{"../team", "", "-team"} {
+		if err := validateAnswerProfileName(name); err == nil {
+			t.Errorf("expected an error for the answer profile name %q", name)
+		}
+	}
+}
+
+func TestSaveAnswerProfileOmitsAWSSecrets(t *testing.T) {
+	useQuiet(t)
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	answers.defaults = promptAnswers{
+		"Do you want to enable AWS Elastic Container Registry?":              {"y"},
+		"-- Enter AWS Access Key ID:":                                        {"ASIAEXAMPLE"},
+		"-- Enter AWS Secret Access Key:":                                    {"secret-key"},
+		"-- (Optional) Enter AWS Session Token:":                             {"session-token"},
+		"-- Enter AWS Region (e.g. us-east-1):":                              {"us-east-1"},
+		"-- Enter 12 digit AWS Account ID (Comma separated list):":           {"123456789012"},
+		"-- Do you want to save the AWS credentials for the other profiles?": {"n"},
+		"Do you want to enable Google Container Registry?":                   {"n"},
+		"Do you want to enable Docker Registry?":                             {"n"},
+		"Do you want to enable Azure Container Registry?":                    {"n"},
+	}
+
+	c, err := readRegistryCredsConfig()
+	if err != nil {
+		t.Fatalf("readRegistryCredsConfig() returned unexpected error: %v", err)
+	}
+	if c.awsAccessKey != "secret-key" || c.awsSessionToken != "session-token" {
+		t.Fatalf("got secret key %q and session token %q from the answers", c.awsAccessKey, c.awsSessionToken)
+	}
+	if err := saveAnswerProfile("ecr"); err != nil {
+		t.Fatalf("saveAnswerProfile() returned unexpected error: %v", err)
+	}
+
+	saved, err := readAnswerProfile(answerProfilePath("ecr"))
+	if err != nil {
+		t.Fatalf("readAnswerProfile() returned unexpected error: %v", err)
+	}
+	if got := saved["-- Enter AWS Access Key ID:"]; !reflect.DeepEqual(got, []string{"ASIAEXAMPLE"}) {
+		t.Errorf("saved access key ID %v, want [ASIAEXAMPLE]", got)
+	}
+	for _, k := range []string{"-- Enter AWS Secret Access Key:", "-- (Optional) Enter AWS Session Token:"} {
+		if v, ok := saved[k]; ok {
+			t.Errorf("expected %q not to be saved, got %v", k, v)
+		}
+	}
+}
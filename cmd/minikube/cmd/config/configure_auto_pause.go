@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// parseAutoPauseComponents parses a comma separated list of the components auto-pause may pause,
+// which must be pausable together, see cluster.CheckPauseComponents.
+func parseAutoPauseComponents(s string) ([]string, error) {
+	var components []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c != "" && !containsString(components, c) {
+			components = append(components, c)
+		}
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no component to pause")
+	}
+	if err := cluster.CheckPauseComponents(components); err != nil {
+		return nil, err
+	}
+	return components, nil
+}
+
+// validateAutoPauseWarmup checks the minimum time the cluster is kept running after it starts, 0 for none
+func validateAutoPauseWarmup(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected eg. 5m0s", s)
+	}
+	if d < 0 {
+		return fmt.Errorf("the warmup must not be negative")
+	}
+	return nil
+}
+
+// autoPauseConfigurator sets how long the cluster is idle before auto-pause pauses it, what it pauses and its warmup
+type autoPauseConfigurator struct {
+	cfg        *config.ClusterConfig
+	interval   time.Duration
+	components []string
+	warmup     time.Duration
+	// declined is set when the user did not confirm an interval shorter than the check interval
+	declined bool
+}
+
+// Validate prompts for the interval, the components to pause and the warmup
+func (a *autoPauseConfigurator) Validate(profile string) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	a.cfg = cfg
+
+	a.interval = AskForDurationValue("-- Enter interval time of auto-pause-interval (ex. 1m0s): ")
+	if a.interval < addons.AutoPauseCheckInterval {
+		out.WarningT("An interval shorter than {{.check}} may pause the cluster while the auto-pause proxy is still letting a request through", out.V{"check": addons.AutoPauseCheckInterval})
+		if !AskForYesNoConfirmation("Do you want to use this interval anyway?", posResponses, negResponses) {
+			a.declined = true
+			return nil
+		}
+	}
+	// the kubelet is always stopped, otherwise its liveness probes would kill the paused containers
+	if AskForYesNoConfirmation("\nDo you want to pause only some of the components, keeping the others running?", posResponses, negResponses) {
+		components := AskForStaticCheckedValue("-- Enter the components to pause separated by commas, including kube-controller-manager or kube-apiserver ("+strings.Join(cluster.PausableComponents, ", ")+"): ", func(s string) error {
+			_, err := parseAutoPauseComponents(s)
+			return err
+		})
+		a.components, _ = parseAutoPauseComponents(components)
+	}
+	if warmup := AskForStaticCheckedValueOptional("-- (Optional) Enter the minimum time the cluster keeps running after it starts before it is first paused (ex. 5m0s): ", validateAutoPauseWarmup); warmup != "" {
+		a.warmup, _ = time.ParseDuration(warmup)
+	}
+	return nil
+}
+
+// Apply saves the settings and re-enables auto-pause so it uses them
+func (a *autoPauseConfigurator) Apply(_ context.Context, profile string) (applyResult, error) {
+	if a.declined {
+		return applyResult{unchanged: true}, nil
+	}
+	a.cfg.AutoPauseInterval = a.interval
+	a.cfg.AutoPauseComponents = a.components
+	a.cfg.AutoPauseWarmup = a.warmup
+	if err := saveAndReenableAddon(profile, a.cfg, "auto-pause", false); err != nil {
+		return applyResult{}, err
+	}
+	return applyResult{}, nil
+}
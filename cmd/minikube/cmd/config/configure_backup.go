@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// saveProfileWithBackup backs up the current config of the profile before overwriting it
+func saveProfileWithBackup(profile string, cfg *config.ClusterConfig) error {
+	path, err := config.BackupProfile(profile)
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		out.Styled(style.Documentation, "Saved a backup of the previous config to {{.path}}", out.V{"path": path})
+		recordUndoBackup(path)
+	}
+	if err := config.SaveProfile(profile, cfg); err != nil {
+		emitConfigureEvent("profile-saved", profile, err)
+		return err
+	}
+	recordApplied("saved the config of " + profile)
+	emitConfigureEvent("profile-saved", profile, nil)
+	return nil
+}
+
+// saveAndReenableAddon saves the config of the profile, then re-enables the addon so its manifests are generated
+// from the new config. A disabled addon is only enabled if enable is true. If enabling fails, the previous config
+// of the profile is restored so it keeps matching the running addon.
+func saveAndReenableAddon(profile string, cfg *config.ClusterConfig, addon string, enable bool) error {
+	previous, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	if enable {
+		// saved as enabled like minikube addons enable does, so the addon is not reported as disabled
+		if cfg.Addons == nil {
+			cfg.Addons = map[string]bool{}
+		}
+		cfg.Addons[addon] = true
+	}
+	if err := saveProfileWithBackup(profile, cfg); err != nil {
+		return fmt.Errorf("save config %s: %w", profile, err)
+	}
+	if !enable && !assets.Addons[addon].IsEnabled(cfg) {
+		return nil
+	}
+	if err := configureClient.EnableAddon(cfg, addon); err != nil {
+		if rerr := config.SaveProfile(profile, previous); rerr != nil {
+			out.ErrT(style.Fatal, "The new config of {{.profile}} was saved but {{.name}} failed to apply it, and restoring the previous config failed: {{.error}}", out.V{"profile": profile, "name": addon, "error": rerr})
+			out.Styled(style.Tip, "Restore the previous config with: minikube -p {{.profile}} addons configure {{.name}} --list-backups", out.V{"profile": profile, "name": addon})
+			return fmt.Errorf("enable %s: %w", addon, err)
+		}
+		recordApplied("restored the previous config of " + profile)
+		out.WarningT("{{.name}} failed to apply the new config, the previous config of {{.profile}} was restored", out.V{"profile": profile, "name": addon})
+		return fmt.Errorf("enable %s: %w", addon, err)
+	}
+	return nil
+}
+
+// listProfileBackups prints the config backups of the profile
+func listProfileBackups(profile string) {
+	backups, err := config.ListProfileBackups(profile)
+	if err != nil {
+		exit.Error(reason.HostConfigLoad, "failed to list the config backups", err)
+	}
+	if len(backups) == 0 {
+		out.Styled(style.Empty, "No config backups found for {{.profile}}", out.V{"profile": profile})
+		return
+	}
+	for _, b := range backups {
+		out.Styled(style.Option, b)
+	}
+}
+
+// restoreProfileBackup replaces the config of the profile with one of its backups
+func restoreProfileBackup(profile, backup string) {
+	cfg, err := config.LoadProfileBackup(profile, backup)
+	if err != nil {
+		exit.Error(reason.HostConfigLoad, "failed to load the config backup", err)
+	}
+	if !AskForYesNoConfirmation("Do you want to replace the config of "+profile+" with "+backup+"?", posResponses, negResponses) {
+		return
+	}
+	if err := saveProfileWithBackup(profile, cfg); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to restore the config backup", err)
+	}
+	out.SuccessT("Restored the config of {{.profile}} from {{.backup}}, re-enable the affected addons to apply it", out.V{"profile": profile, "backup": backup})
+}
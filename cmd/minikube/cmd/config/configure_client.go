@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+
+	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+// clusterClient holds the cluster operations of the configure flows, so they can be tested without a cluster
+type clusterClient interface {
+	EnsureNamespace(ctx context.Context, profile, namespace string) error
+	CreateSecret(ctx context.Context, profile, namespace, name string, data, labels map[string]string) error
+	CheckSecretExists(ctx context.Context, profile, namespace, name string) (bool, error)
+	DeleteSecret(ctx context.Context, profile, namespace, name string) error
+	GetSecretData(ctx context.Context, profile, namespace, name string) (map[string]string, error)
+	GetConfigMapData(ctx context.Context, profile, namespace, name string) (map[string]string, error)
+	PatchConfigMap(ctx context.Context, profile, namespace, name string, data map[string]string) error
+	// GetServicePorts returns the ports of the service, or nil if it does not exist
+	GetServicePorts(ctx context.Context, profile, namespace, name string) ([]core.ServicePort, error)
+	GetPodLogs(ctx context.Context, profile, namespace, selector string, tailLines int64) (string, error)
+	CreateServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) (string, error)
+	// DeleteServiceAccountToken deletes the service account and the token secret created by CreateServiceAccountToken
+	DeleteServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) error
+	CreateClusterRoleBinding(ctx context.Context, profile, name, clusterRole, namespace, serviceAccount string, labels map[string]string) error
+	DeleteClusterRoleBinding(ctx context.Context, profile, name string) error
+	ExposeContainerPorts(ctx context.Context, profile, namespace, deployment string, ports []core.ContainerPort) error
+	RestartDeployment(ctx context.Context, profile, namespace, deployment string) error
+	ApplyLimitRange(ctx context.Context, profile, namespace, name string, spec core.LimitRangeSpec) error
+	ApplyResourceQuota(ctx context.Context, profile, namespace, name string, spec core.ResourceQuotaSpec) error
+	CreateIngress(ctx context.Context, profile, namespace, name string, spec networking.IngressSpec) error
+	// UpdateNodes applies the changes to the named nodes, or to all the nodes if names is empty, and returns the updated nodes
+	UpdateNodes(ctx context.Context, profile string, names []string, changes service.NodeChanges) ([]string, error)
+	// WriteNodeFiles writes the files, keyed by path, on every node then restarts the service so it reads them, unless it is empty
+	WriteNodeFiles(cc *config.ClusterConfig, files map[string]string, service string) error
+	// EnableAddon (re-)enables the addon, generating its manifests from the config
+	EnableAddon(cc *config.ClusterConfig, name string) error
+	// SetAddonEnabled enables a disabled addon like minikube addons enable, saving it as enabled in the profile
+	SetAddonEnabled(profile, name string) error
+}
+
+// configureClient is the client used by the configure flows
+var configureClient clusterClient = serviceClient{}
+
+// serviceClient is the clusterClient of a live cluster, using the service and addons packages
+type serviceClient struct{}
+
+func (serviceClient) EnsureNamespace(ctx context.Context, profile, namespace string) error {
+	return clusterError(service.EnsureNamespace(ctx, profile, namespace))
+}
+
+func (serviceClient) CreateSecret(ctx context.Context, profile, namespace, name string, data, labels map[string]string) error {
+	return clusterError(service.CreateSecret(ctx, profile, namespace, name, data, labels))
+}
+
+func (serviceClient) CheckSecretExists(ctx context.Context, profile, namespace, name string) (bool, error) {
+	exists, err := service.CheckSecretExists(ctx, profile, namespace, name)
+	return exists, clusterError(err)
+}
+
+func (serviceClient) DeleteSecret(ctx context.Context, profile, namespace, name string) error {
+	return clusterError(service.DeleteSecret(ctx, profile, namespace, name))
+}
+
+func (serviceClient) GetSecretData(ctx context.Context, profile, namespace, name string) (map[string]string, error) {
+	data, err := service.GetSecretData(ctx, profile, namespace, name)
+	return data, clusterError(err)
+}
+
+func (serviceClient) GetConfigMapData(ctx context.Context, profile, namespace, name string) (map[string]string, error) {
+	data, err := service.GetConfigMapData(ctx, profile, namespace, name)
+	return data, clusterError(err)
+}
+
+func (serviceClient) PatchConfigMap(ctx context.Context, profile, namespace, name string, data map[string]string) error {
+	return clusterError(service.PatchConfigMap(ctx, profile, namespace, name, data))
+}
+
+func (serviceClient) GetServicePorts(ctx context.Context, profile, namespace, name string) ([]core.ServicePort, error) {
+	ports, err := service.GetServicePorts(ctx, profile, namespace, name)
+	return ports, clusterError(err)
+}
+
+func (serviceClient) GetPodLogs(ctx context.Context, profile, namespace, selector string, tailLines int64) (string, error) {
+	logs, err := service.GetPodLogs(ctx, profile, namespace, selector, tailLines)
+	return logs, clusterError(err)
+}
+
+func (serviceClient) CreateServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) (string, error) {
+	token, err := service.CreateServiceAccountToken(ctx, profile, namespace, serviceAccount)
+	return token, clusterError(err)
+}
+
+func (serviceClient) DeleteServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) error {
+	return clusterError(service.DeleteServiceAccountToken(ctx, profile, namespace, serviceAccount))
+}
+
+func (serviceClient) CreateClusterRoleBinding(ctx context.Context, profile, name, clusterRole, namespace, serviceAccount string, labels map[string]string) error {
+	return clusterError(service.CreateClusterRoleBinding(ctx, profile, name, clusterRole, namespace, serviceAccount, labels))
+}
+
+func (serviceClient) DeleteClusterRoleBinding(ctx context.Context, profile, name string) error {
+	return clusterError(service.DeleteClusterRoleBinding(ctx, profile, name))
+}
+
+func (serviceClient) ExposeContainerPorts(ctx context.Context, profile, namespace, deployment string, ports []core.ContainerPort) error {
+	return clusterError(service.ExposeContainerPorts(ctx, profile, namespace, deployment, ports))
+}
+
+func (serviceClient) RestartDeployment(ctx context.Context, profile, namespace, deployment string) error {
+	return clusterError(service.RestartDeployment(ctx, profile, namespace, deployment))
+}
+
+func (serviceClient) ApplyLimitRange(ctx context.Context, profile, namespace, name string, spec core.LimitRangeSpec) error {
+	return clusterError(service.ApplyLimitRange(ctx, profile, namespace, name, spec))
+}
+
+func (serviceClient) ApplyResourceQuota(ctx context.Context, profile, namespace, name string, spec core.ResourceQuotaSpec) error {
+	return clusterError(service.ApplyResourceQuota(ctx, profile, namespace, name, spec))
+}
+
+func (serviceClient) CreateIngress(ctx context.Context, profile, namespace, name string, spec networking.IngressSpec) error {
+	return clusterError(service.CreateIngress(ctx, profile, namespace, name, spec))
+}
+
+func (serviceClient) UpdateNodes(ctx context.Context, profile string, names []string, changes service.NodeChanges) ([]string, error) {
+	updated, err := service.UpdateNodes(ctx, profile, names, changes)
+	return updated, clusterError(err)
+}
+
+func (serviceClient) WriteNodeFiles(cc *config.ClusterConfig, files map[string]string, service string) error {
+	var assetFiles []assets.CopyableFile
+	for path, content := range files {
+		assetFiles = append(assetFiles, assets.NewMemoryAssetTarget([]byte(content), path, "0644"))
+	}
+	return addons.WriteNodeFiles(cc, assetFiles, service)
+}
+
+func (serviceClient) EnableAddon(cc *config.ClusterConfig, name string) error {
+	return addons.EnableOrDisableAddon(cc, name, "true")
+}
+
+func (serviceClient) SetAddonEnabled(profile, name string) error {
+	return addons.SetAndSave(profile, name, "true")
+}
@@ -0,0 +1,649 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	deployaddons "k8s.io/minikube/deploy/addons"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+// fakeClusterClient records the cluster operations of the configure flows instead of running them
+type fakeClusterClient struct {
+	calls []string
+	// secrets holds the data of the existing secrets by namespace/name
+	secrets map[string]map[string]string
+	// labels holds the labels of the created secrets by namespace/name
+	labels map[string]map[string]string
+	// configMaps holds the data of the existing ConfigMaps by namespace/name, patched by PatchConfigMap
+	configMaps map[string]map[string]string
+	// services holds the ports of the existing services by namespace/name
+	services map[string][]core.ServicePort
+	// err is returned by the operations changing the cluster
+	err error
+	// nodeFiles holds the files written on the nodes by path
+	nodeFiles map[string]string
+	// ingresses holds the spec of the created ingresses by namespace/name
+	ingresses map[string]networking.IngressSpec
+	// failCall makes the secret operation recorded as failCall fail, eg. "CreateSecret kube-system/registry-creds-dpr"
+	failCall string
+}
+
+// secretCall records a secret operation and returns its error
+func (f *fakeClusterClient) secretCall(call string) error {
+	f.calls = append(f.calls, call)
+	if call == f.failCall {
+		return fmt.Errorf("%s failed", call)
+	}
+	return f.err
+}
+
+// useFakeClusterClient replaces the client of the configure flows with a fake for the duration of the test,
+// and stores the profiles in a temporary minikube home
+func useFakeClusterClient(t *testing.T) *fakeClusterClient {
+	f := &fakeClusterClient{secrets: map[string]map[string]string{}, labels: map[string]map[string]string{}, configMaps: map[string]map[string]string{}}
+	orig := configureClient
+	configureClient = f
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	t.Cleanup(func() {
+		configureClient = orig
+		configureProgress.applied = nil
+	})
+	return f
+}
+
+func (f *fakeClusterClient) EnsureNamespace(_ context.Context, _, namespace string) error {
+	f.calls = append(f.calls, "EnsureNamespace "+namespace)
+	return f.err
+}
+
+func (f *fakeClusterClient) CreateSecret(_ context.Context, _, namespace, name string, data, labels map[string]string) error {
+	if err := f.secretCall("CreateSecret " + namespace + "/" + name); err != nil {
+		return err
+	}
+	f.secrets[namespace+"/"+name] = data
+	f.labels[namespace+"/"+name] = labels
+	return nil
+}
+
+func (f *fakeClusterClient) CheckSecretExists(_ context.Context, _, namespace, name string) (bool, error) {
+	f.calls = append(f.calls, "CheckSecretExists "+namespace+"/"+name)
+	_, ok := f.secrets[namespace+"/"+name]
+	return ok, nil
+}
+
+func (f *fakeClusterClient) DeleteSecret(_ context.Context, _, namespace, name string) error {
+	if err := f.secretCall("DeleteSecret " + namespace + "/" + name); err != nil {
+		return err
+	}
+	delete(f.secrets, namespace+"/"+name)
+	return nil
+}
+
+func (f *fakeClusterClient) GetSecretData(_ context.Context, _, namespace, name string) (map[string]string, error) {
+	f.calls = append(f.calls, "GetSecretData "+namespace+"/"+name)
+	return f.secrets[namespace+"/"+name], nil
+}
+
+func (f *fakeClusterClient) GetConfigMapData(_ context.Context, _, namespace, name string) (map[string]string, error) {
+	f.calls = append(f.calls, "GetConfigMapData "+namespace+"/"+name)
+	return f.configMaps[namespace+"/"+name], nil
+}
+
+func (f *fakeClusterClient) GetServicePorts(_ context.Context, _, namespace, name string) ([]core.ServicePort, error) {
+	f.calls = append(f.calls, "GetServicePorts "+namespace+"/"+name)
+	return f.services[namespace+"/"+name], nil
+}
+
+func (f *fakeClusterClient) PatchConfigMap(_ context.Context, _, namespace, name string, data map[string]string) error {
+	f.calls = append(f.calls, "PatchConfigMap "+namespace+"/"+name)
+	if f.err != nil {
+		return f.err
+	}
+	if f.configMaps[namespace+"/"+name] == nil {
+		f.configMaps[namespace+"/"+name] = map[string]string{}
+	}
+	for k, v := range data {
+		f.configMaps[namespace+"/"+name][k] = v
+	}
+	return nil
+}
+
+func (f *fakeClusterClient) GetPodLogs(_ context.Context, _, namespace, selector string, _ int64) (string, error) {
+	f.calls = append(f.calls, "GetPodLogs "+namespace+" "+selector)
+	return "", nil
+}
+
+func (f *fakeClusterClient) CreateServiceAccountToken(_ context.Context, _, namespace, serviceAccount string) (string, error) {
+	f.calls = append(f.calls, "CreateServiceAccountToken "+namespace+"/"+serviceAccount)
+	return "token", f.err
+}
+
+func (f *fakeClusterClient) DeleteServiceAccountToken(_ context.Context, _, namespace, serviceAccount string) error {
+	f.calls = append(f.calls, "DeleteServiceAccountToken "+namespace+"/"+serviceAccount)
+	return f.err
+}
+
+func (f *fakeClusterClient) CreateClusterRoleBinding(_ context.Context, _, name, _, _, _ string, _ map[string]string) error {
+	f.calls = append(f.calls, "CreateClusterRoleBinding "+name)
+	return f.err
+}
+
+func (f *fakeClusterClient) DeleteClusterRoleBinding(_ context.Context, _, name string) error {
+	f.calls = append(f.calls, "DeleteClusterRoleBinding "+name)
+	return f.err
+}
+
+func (f *fakeClusterClient) ExposeContainerPorts(_ context.Context, _, namespace, deployment string, ports []core.ContainerPort) error {
+	for _, p := range ports {
+		f.calls = append(f.calls, fmt.Sprintf("ExposeContainerPort %s/%s %d/%s", namespace, deployment, p.ContainerPort, p.Protocol))
+	}
+	return f.err
+}
+
+func (f *fakeClusterClient) RestartDeployment(_ context.Context, _, namespace, deployment string) error {
+	f.calls = append(f.calls, "RestartDeployment "+namespace+"/"+deployment)
+	return f.err
+}
+
+func (f *fakeClusterClient) ApplyLimitRange(_ context.Context, _, namespace, name string, _ core.LimitRangeSpec) error {
+	f.calls = append(f.calls, "ApplyLimitRange "+namespace+"/"+name)
+	return f.err
+}
+
+func (f *fakeClusterClient) WriteNodeFiles(_ *config.ClusterConfig, files map[string]string, service string) error {
+	var paths []string
+	for path, content := range files {
+		paths = append(paths, path)
+		if f.nodeFiles == nil {
+			f.nodeFiles = map[string]string{}
+		}
+		f.nodeFiles[path] = content
+	}
+	sort.Strings(paths)
+	f.calls = append(f.calls, "WriteNodeFiles "+strings.Join(paths, ",")+" restart "+service)
+	return f.err
+}
+
+func (f *fakeClusterClient) UpdateNodes(_ context.Context, _ string, names []string, _ service.NodeChanges) ([]string, error) {
+	f.calls = append(f.calls, "UpdateNodes "+strings.Join(names, ","))
+	if len(names) == 0 {
+		names = []string{"minikube"}
+	}
+	return names, f.err
+}
+
+func (f *fakeClusterClient) ApplyResourceQuota(_ context.Context, _, namespace, name string, _ core.ResourceQuotaSpec) error {
+	f.calls = append(f.calls, "ApplyResourceQuota "+namespace+"/"+name)
+	return f.err
+}
+
+func (f *fakeClusterClient) CreateIngress(_ context.Context, _, namespace, name string, spec networking.IngressSpec) error {
+	f.calls = append(f.calls, "CreateIngress "+namespace+"/"+name)
+	if f.err != nil {
+		return f.err
+	}
+	if f.ingresses == nil {
+		f.ingresses = map[string]networking.IngressSpec{}
+	}
+	f.ingresses[namespace+"/"+name] = spec
+	return nil
+}
+
+func (f *fakeClusterClient) EnableAddon(_ *config.ClusterConfig, name string) error {
+	f.calls = append(f.calls, "EnableAddon "+name)
+	return f.err
+}
+
+func (f *fakeClusterClient) SetAddonEnabled(_, name string) error {
+	f.calls = append(f.calls, "SetAddonEnabled "+name)
+	return f.err
+}
+
+// loadTestProfile loads the profile saved by a configure flow
+func loadTestProfile(t *testing.T, profile string) *config.ClusterConfig {
+	t.Helper()
+	cc, err := config.DefaultLoader.LoadConfigFromFile(profile)
+	if err != nil {
+		t.Fatalf("unable to load profile: %v", err)
+	}
+	return cc
+}
+
+func TestApplyMetalLBConfig(t *testing.T) {
+	current := config.DefaultMetalLBConfig("10.0.0.1", "10.0.0.10")
+	tests := []struct {
+		description string
+		enabled     bool
+		metallb     config.MetalLBConfig
+		changed     bool
+		calls       []string
+	}{
+		{
+			description: "new range",
+			enabled:     true,
+			metallb:     config.DefaultMetalLBConfig("10.0.0.20", "10.0.0.30"),
+			changed:     true,
+			calls:       []string{"EnableAddon metallb"},
+		},
+		{
+			description: "unchanged range",
+			enabled:     true,
+			metallb:     current,
+		},
+		{
+			description: "unchanged range of a disabled addon",
+			metallb:     current,
+			changed:     true,
+			calls:       []string{"EnableAddon metallb"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			cfg := &config.ClusterConfig{Name: "metallb", Addons: map[string]bool{"metallb": tc.enabled}, MetalLB: current}
+			if err := config.SaveProfile("metallb", cfg); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			changed, err := applyMetalLBConfig("metallb", cfg, tc.metallb)
+			if err != nil {
+				t.Fatalf("applyMetalLBConfig returned unexpected error: %v", err)
+			}
+			if changed != tc.changed {
+				t.Errorf("applyMetalLBConfig() = %t, want %t", changed, tc.changed)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			if !tc.changed {
+				return
+			}
+			if saved := loadTestProfile(t, "metallb"); !reflect.DeepEqual(saved.MetalLB, tc.metallb) {
+				t.Errorf("saved metallb config %+v, want %+v", saved.MetalLB, tc.metallb)
+			}
+		})
+	}
+}
+
+func TestApplyIngressConfig(t *testing.T) {
+	tests := []struct {
+		description string
+		secrets     map[string]map[string]string
+		cert        string
+	}{
+		{
+			description: "existing secret",
+			secrets:     map[string]map[string]string{"kube-system/mkcert": {"tls.crt": "crt"}},
+			cert:        "kube-system/mkcert",
+		},
+		{
+			description: "missing secret",
+			cert:        "default/missing",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			for k, v := range tc.secrets {
+				f.secrets[k] = v
+			}
+			cfg := &config.ClusterConfig{Name: "ingress", KubernetesConfig: config.KubernetesConfig{CustomIngressController: "old/controller"}}
+
+			if err := applyIngressConfig(context.Background(), "ingress", cfg, tc.cert, ""); err != nil {
+				t.Fatalf("applyIngressConfig returned unexpected error: %v", err)
+			}
+
+			// a missing secret is only reported, the cert is saved anyway
+			if want := []string{"CheckSecretExists " + tc.cert}; !reflect.DeepEqual(f.calls, want) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, want)
+			}
+			saved := loadTestProfile(t, "ingress")
+			if saved.KubernetesConfig.CustomIngressCert != tc.cert || saved.KubernetesConfig.CustomIngressController != "" {
+				t.Errorf("saved cert %q of controller %q, want %q of the addon controller", saved.KubernetesConfig.CustomIngressCert, saved.KubernetesConfig.CustomIngressController, tc.cert)
+			}
+		})
+	}
+}
+
+func TestApplyIngressServices(t *testing.T) {
+	tests := []struct {
+		description string
+		tcp         map[string]string
+		udp         map[string]string
+		err         error
+		calls       []string
+		wantErr     bool
+	}{
+		{
+			description: "tcp and udp services",
+			tcp:         map[string]string{"6379": "default/redis:6379", "5432": "db/postgres:5432"},
+			udp:         map[string]string{"53": "kube-system/kube-dns:dns"},
+			calls: []string{
+				"PatchConfigMap ingress-nginx/tcp-services",
+				"PatchConfigMap ingress-nginx/udp-services",
+				"ExposeContainerPort ingress-nginx/ingress-nginx-controller 5432/TCP",
+				"ExposeContainerPort ingress-nginx/ingress-nginx-controller 6379/TCP",
+				"ExposeContainerPort ingress-nginx/ingress-nginx-controller 53/UDP",
+			},
+		},
+		{
+			description: "udp services only",
+			udp:         map[string]string{"53": "kube-system/kube-dns:dns"},
+			calls: []string{
+				"PatchConfigMap ingress-nginx/udp-services",
+				"ExposeContainerPort ingress-nginx/ingress-nginx-controller 53/UDP",
+			},
+		},
+		{
+			description: "ConfigMap update fails",
+			tcp:         map[string]string{"6379": "default/redis:6379"},
+			err:         fmt.Errorf("connection refused"),
+			calls:       []string{"PatchConfigMap ingress-nginx/tcp-services"},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			f.err = tc.err
+
+			err := applyIngressServices(context.Background(), "ingress", tc.tcp, tc.udp)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("applyIngressServices() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+		})
+	}
+}
+
+func TestRegistryCredsApply(t *testing.T) {
+	c := defaultRegistryCredsConfig()
+	c.docker = dockerRegistry{server: "ghcr.io", user: "other", password: "secret", caCert: "-----BEGIN CERTIFICATE-----"}
+	c.enable("dpr")
+	c.gcr = gcrRegistry{credentials: `{"type": "service_account"}`, url: "https://europe-docker.pkg.dev"}
+	c.enable("gcr")
+	tests := []struct {
+		description string
+		existing    []string
+		err         error
+		calls       []string
+		wantErr     bool
+	}{
+		{
+			description: "secrets of the enabled registries created",
+			calls: []string{
+				"EnsureNamespace kube-system",
+				"GetSecretData kube-system/registry-creds-ecr",
+				"GetSecretData kube-system/registry-creds-gcr",
+				"GetSecretData kube-system/registry-creds-acr",
+				"GetSecretData kube-system/registry-creds-dpr",
+				"CreateSecret kube-system/registry-creds-gcr",
+				"CreateSecret kube-system/registry-creds-dpr",
+			},
+		},
+		{
+			description: "secrets of the disabled registries deleted",
+			existing:    []string{"registry-creds-ecr"},
+			calls: []string{
+				"EnsureNamespace kube-system",
+				"GetSecretData kube-system/registry-creds-ecr",
+				"GetSecretData kube-system/registry-creds-gcr",
+				"GetSecretData kube-system/registry-creds-acr",
+				"GetSecretData kube-system/registry-creds-dpr",
+				"DeleteSecret kube-system/registry-creds-ecr",
+				"CreateSecret kube-system/registry-creds-gcr",
+				"CreateSecret kube-system/registry-creds-dpr",
+			},
+		},
+		{
+			description: "namespace not created",
+			err:         fmt.Errorf("unreachable"),
+			calls:       []string{"EnsureNamespace kube-system"},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			for _, name := range tc.existing {
+				f.secrets["kube-system/"+name] = map[string]string{}
+			}
+			f.err = tc.err
+
+			r := &registryCredsConfigurator{config: c}
+			if _, err := r.Apply(context.Background(), "registry-creds"); (err != nil) != tc.wantErr {
+				t.Errorf("Apply() expected error %t but got %v", tc.wantErr, err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := f.secrets["kube-system/registry-creds-dpr"]["DOCKER_PRIVATE_REGISTRY_SERVER"]; got != "ghcr.io" {
+				t.Errorf("registry-creds-dpr server = %q, want %q", got, "ghcr.io")
+			}
+			if got := f.labels["kube-system/registry-creds-dpr"]["cloud"]; got != "dpr" {
+				t.Errorf("registry-creds-dpr cloud label = %q, want %q", got, "dpr")
+			}
+			if got := f.secrets["kube-system/registry-creds-gcr"]["gcrurl"]; got != "https://europe-docker.pkg.dev" {
+				t.Errorf("registry-creds-gcr gcrurl = %q, want %q", got, "https://europe-docker.pkg.dev")
+			}
+			checkRegistryCredsManifestReads(t, f.secrets, "registry-creds-gcr", "registry-creds-dpr")
+			for _, name := range []string{"registry-creds-ecr", "registry-creds-acr"} {
+				if _, ok := f.secrets["kube-system/"+name]; ok {
+					t.Errorf("%s secret exists, want it deleted", name)
+				}
+			}
+		})
+	}
+}
+
+// registryCredsManifestKeys returns the keys of the registry-creds secrets read by the controller manifest,
+// by secret name: those of the secretKeyRef env vars and of the items of the secret volumes
+func registryCredsManifestKeys(t *testing.T) map[string][]string {
+	t.Helper()
+	b, err := deployaddons.RegistryCredsAssets.ReadFile("registry-creds/registry-creds-rc.yaml.tmpl")
+	if err != nil {
+		t.Fatalf("unable to read the registry-creds manifest: %v", err)
+	}
+	keys := map[string][]string{}
+	secret := ""
+	for _, line := range strings.Split(string(b), "\n") {
+		field, value, ok := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "- "), ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch field {
+		case "name", "secretName":
+			if strings.HasPrefix(value, "registry-creds-") {
+				secret = value
+			}
+		case "key":
+			keys[secret] = append(keys[secret], value)
+		}
+	}
+	return keys
+}
+
+// checkRegistryCredsManifestReads fails the test unless the controller manifest reads each key of the secrets
+func checkRegistryCredsManifestReads(t *testing.T, secrets map[string]map[string]string, names ...string) {
+	t.Helper()
+	keys := registryCredsManifestKeys(t)
+	for _, name := range names {
+		data, ok := secrets["kube-system/"+name]
+		if !ok {
+			t.Errorf("%s secret does not exist", name)
+			continue
+		}
+		for key := range data {
+			if !containsString(keys[name], key) {
+				t.Errorf("the registry-creds manifest does not read %s of the %s secret", key, name)
+			}
+		}
+	}
+}
+
+func TestApplyRegistryCredsRefresh(t *testing.T) {
+	tests := []struct {
+		description string
+		current     time.Duration
+		calls       []string
+	}{
+		{description: "new interval", calls: []string{"EnableAddon registry-creds"}},
+		{description: "unchanged interval", current: 30 * time.Minute},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			cc := &config.ClusterConfig{Name: "refresh", Addons: map[string]bool{"registry-creds": true}, RegistryCredsRefresh: tc.current}
+			if err := config.SaveProfile("refresh", cc); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			if err := applyRegistryCredsRefresh("refresh", 30*time.Minute); err != nil {
+				t.Fatalf("applyRegistryCredsRefresh() returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			if got := loadTestProfile(t, "refresh").RegistryCredsRefresh; got != 30*time.Minute {
+				t.Errorf("RegistryCredsRefresh = %s, want 30m", got)
+			}
+		})
+	}
+}
+
+func TestRegistryCredsApplyRollback(t *testing.T) {
+	f := useFakeClusterClient(t)
+	previous := map[string]string{"AWS_ACCESS_KEY_ID": "old"}
+	f.secrets["kube-system/registry-creds-ecr"] = previous
+	f.failCall = "CreateSecret kube-system/registry-creds-dpr"
+
+	c := defaultRegistryCredsConfig()
+	c.gcr = gcrRegistry{credentials: `{"type": "service_account"}`, url: "https://gcr.io"}
+	c.docker = dockerRegistry{server: "registry.dev", user: "user", password: "password"}
+	c.enable("gcr")
+	c.enable("dpr")
+	r := &registryCredsConfigurator{config: c}
+	_, err := r.Apply(context.Background(), "registry-creds")
+	if !errors.Is(err, ErrSecretCreate) {
+		t.Fatalf("Apply() = %v, expected an ErrSecretCreate error", err)
+	}
+
+	var changes []string
+	for _, call := range f.calls {
+		if !strings.HasPrefix(call, "GetSecretData ") {
+			changes = append(changes, call)
+		}
+	}
+	want := []string{
+		"EnsureNamespace kube-system",
+		"DeleteSecret kube-system/registry-creds-ecr",
+		"CreateSecret kube-system/registry-creds-gcr",
+		"CreateSecret kube-system/registry-creds-dpr",
+		// rollback, in reverse order
+		"DeleteSecret kube-system/registry-creds-gcr",
+		"CreateSecret kube-system/registry-creds-ecr",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("unexpected cluster changes %v, want %v", changes, want)
+	}
+	if got := f.secrets["kube-system/registry-creds-ecr"]; !reflect.DeepEqual(got, previous) {
+		t.Errorf("registry-creds-ecr = %v, want it restored to %v", got, previous)
+	}
+	if _, ok := f.secrets["kube-system/registry-creds-gcr"]; ok {
+		t.Errorf("registry-creds-gcr secret exists, want it rolled back")
+	}
+}
+
+func TestSaveAndReenableAddon(t *testing.T) {
+	tests := []struct {
+		description string
+		enabled     bool
+		enable      bool
+		err         error
+		calls       []string
+		interval    time.Duration
+	}{
+		{
+			description: "enabled addon",
+			enabled:     true,
+			calls:       []string{"EnableAddon auto-pause"},
+			interval:    2 * time.Minute,
+		},
+		{
+			description: "disabled addon",
+			interval:    2 * time.Minute,
+		},
+		{
+			description: "enable disabled addon",
+			enable:      true,
+			calls:       []string{"EnableAddon auto-pause"},
+			interval:    2 * time.Minute,
+		},
+		{
+			description: "failed to enable",
+			enabled:     true,
+			err:         fmt.Errorf("apply failed"),
+			calls:       []string{"EnableAddon auto-pause"},
+			interval:    time.Minute,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			f.err = tc.err
+			cfg := &config.ClusterConfig{Name: "auto-pause", Addons: map[string]bool{"auto-pause": tc.enabled}, AutoPauseInterval: time.Minute}
+			if err := config.SaveProfile("auto-pause", cfg); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			cfg.AutoPauseInterval = 2 * time.Minute
+			if err := saveAndReenableAddon("auto-pause", cfg, "auto-pause", tc.enable); (err != nil) != (tc.err != nil) {
+				t.Errorf("saveAndReenableAddon() expected error %t but got %v", tc.err != nil, err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			// the previous config is restored when the addon failed to apply the new one
+			saved := loadTestProfile(t, "auto-pause")
+			if saved.AutoPauseInterval != tc.interval {
+				t.Errorf("saved interval %s, want %s", saved.AutoPauseInterval, tc.interval)
+			}
+			if want := tc.enabled || tc.enable; saved.Addons["auto-pause"] != want {
+				t.Errorf("saved auto-pause as enabled %t, want %t", saved.Addons["auto-pause"], want)
+			}
+		})
+	}
+}
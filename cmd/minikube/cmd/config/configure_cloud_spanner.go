@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+// the ports of the cloud-spanner emulator when they are not set, as in its manifest
+const (
+	defaultCloudSpannerGRPCPort = 9010
+	defaultCloudSpannerHTTPPort = 9020
+)
+
+var (
+	// gcpProjectID matches a Google Cloud project ID: 6 to 30 lowercase letters, digits and hyphens,
+	// starting with a letter and not ending with a hyphen
+	gcpProjectID = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	// spannerInstanceID matches a Cloud Spanner instance ID: 2 to 64 lowercase letters, digits and hyphens,
+	// starting with a letter and not ending with a hyphen
+	spannerInstanceID = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}[a-z0-9]$`)
+)
+
+// validateCloudSpannerPort checks a port of the cloud-spanner emulator
+func validateCloudSpannerPort(s string) error {
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port %q, must be between 1 and 65535", s)
+	}
+	return nil
+}
+
+// validateCloudSpannerPorts checks the gRPC and REST APIs of the emulator do not listen on the same port,
+// counting the default of a port which is not set
+func validateCloudSpannerPorts(c config.CloudSpannerConfig) error {
+	grpcPort, httpPort := c.GRPCPort, c.HTTPPort
+	if grpcPort == 0 {
+		grpcPort = defaultCloudSpannerGRPCPort
+	}
+	if httpPort == 0 {
+		httpPort = defaultCloudSpannerHTTPPort
+	}
+	if grpcPort == httpPort {
+		return fmt.Errorf("the gRPC and REST APIs of the emulator can not both listen on port %d", grpcPort)
+	}
+	return nil
+}
+
+// validateGCPProjectID checks the default project of the cloud-spanner emulator
+func validateGCPProjectID(s string) error {
+	if !gcpProjectID.MatchString(s) {
+		return fmt.Errorf("invalid project ID %q: must be 6 to 30 lowercase letters, digits or hyphens, start with a letter and not end with a hyphen", s)
+	}
+	return nil
+}
+
+// validateSpannerInstanceID checks the default instance of the cloud-spanner emulator
+func validateSpannerInstanceID(s string) error {
+	if !spannerInstanceID.MatchString(s) {
+		return fmt.Errorf("invalid instance ID %q: must be 2 to 64 lowercase letters, digits or hyphens, start with a letter and not end with a hyphen", s)
+	}
+	return nil
+}
+
+// askForCloudSpannerConfig prompts for the ports of the emulator, which must differ, and the default project and instance
+func askForCloudSpannerConfig() config.CloudSpannerConfig {
+	var c config.CloudSpannerConfig
+	c.GRPCPort, _ = strconv.Atoi(AskForStaticCheckedValue("-- Enter the gRPC port of the emulator (ex. 9010): ", validateCloudSpannerPort))
+	httpPort := AskForStaticCheckedValue("-- Enter the REST port of the emulator (ex. 9020): ", func(s string) error {
+		if err := validateCloudSpannerPort(s); err != nil {
+			return err
+		}
+		if s == strconv.Itoa(c.GRPCPort) {
+			return fmt.Errorf("port %s is already used by the gRPC API", s)
+		}
+		return nil
+	})
+	c.HTTPPort, _ = strconv.Atoi(httpPort)
+	c.Project = AskForStaticCheckedValueOptional("-- (Optional) Enter the default project of the clients (ex. test-project): ", validateGCPProjectID)
+	c.Instance = AskForStaticCheckedValueOptional("-- (Optional) Enter the default instance of the clients (ex. test-instance): ", validateSpannerInstanceID)
+	return c
+}
+
+// processCloudSpannerConfig prompts for the config of the cloud-spanner emulator, then re-enables the addon
+// if it is enabled so the emulator is deployed with it
+func processCloudSpannerConfig(profile string) {
+	_, cfg := mustload.Partial(profile)
+	cfg.CloudSpanner = askForCloudSpannerConfig()
+	if err := saveAndReenableAddon(profile, cfg, "cloud-spanner", false); err != nil {
+		exit.Error(reason.InternalAddonConfigure, "Failed to configure cloud-spanner", err)
+	}
+}
+
+// cloudSpannerPortKey is the --set key of a port of the cloud-spanner emulator, field returns the setting on the profile
+func cloudSpannerPortKey(description string, field func(cc *config.ClusterConfig) *int) addonConfigKey {
+	return addonConfigKey{
+		kind:        "port",
+		description: description,
+		validate:    validateCloudSpannerPort,
+		set:         func(cc *config.ClusterConfig, v string) { *field(cc), _ = strconv.Atoi(v) },
+		get: func(cc *config.ClusterConfig) string {
+			if *field(cc) == 0 {
+				return ""
+			}
+			return strconv.Itoa(*field(cc))
+		},
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestValidateCloudSpannerSettings(t *testing.T) {
+	tests := []struct {
+		description string
+		validate    func(string) error
+		value       string
+		valid       bool
+	}{
+		{description: "port", validate: validateCloudSpannerPort, value: "9010", valid: true},
+		{description: "port 0", validate: validateCloudSpannerPort, value: "0", valid: false},
+		{description: "port not a number", validate: validateCloudSpannerPort, value: "grpc", valid: false},
+		{description: "project", validate: validateGCPProjectID, value: "test-project", valid: true},
+		{description: "project too short", validate: validateGCPProjectID, value: "test", valid: false},
+		{description: "project ending with a hyphen", validate: validateGCPProjectID, value: "test-project-", valid: false},
+		{description: "project with uppercase", validate: validateGCPProjectID, value: "Test-project", valid: false},
+		{description: "instance", validate: validateSpannerInstanceID, value: "dev", valid: true},
+		{description: "instance starting with a digit", validate: validateSpannerInstanceID, value: "1dev", valid: false},
+		{description: "instance too short", validate: validateSpannerInstanceID, value: "d", valid: false},
+	}
+	for _, tc := range tests {
+		if err := tc.validate(tc.value); (err == nil) != tc.valid {
+			t.Errorf("%s: validating %q = %v, want valid %t", tc.description, tc.value, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateCloudSpannerPorts(t *testing.T) {
+	tests := []struct {
+		config config.CloudSpannerConfig
+		valid  bool
+	}{
+		{config: config.CloudSpannerConfig{}, valid: true},
+		{config: config.CloudSpannerConfig{GRPCPort: 19010, HTTPPort: 19020}, valid: true},
+		{config: config.CloudSpannerConfig{GRPCPort: 9020}, valid: false},
+		{config: config.CloudSpannerConfig{HTTPPort: 9010}, valid: false},
+		{config: config.CloudSpannerConfig{GRPCPort: 8080, HTTPPort: 8080}, valid: false},
+	}
+	for _, tc := range tests {
+		if err := validateCloudSpannerPorts(tc.config); (err == nil) != tc.valid {
+			t.Errorf("validateCloudSpannerPorts(%+v) = %v, want valid %t", tc.config, err, tc.valid)
+		}
+	}
+}
+
+func TestCloudSpannerKeys(t *testing.T) {
+	cc := &config.ClusterConfig{}
+	keys := addonConfigKeys["cloud-spanner"]
+	if got := keys["grpc-port"].get(cc); got != "" {
+		t.Errorf("expected no gRPC port before it is set, got %q", got)
+	}
+	keys["grpc-port"].set(cc, "19010")
+	keys["project"].set(cc, "test-project")
+	want := config.CloudSpannerConfig{GRPCPort: 19010, Project: "test-project"}
+	if !reflect.DeepEqual(cc.CloudSpanner, want) {
+		t.Errorf("cloud-spanner config = %+v, want %+v", cc.CloudSpanner, want)
+	}
+	if got := keys["grpc-port"].get(cc); got != "19010" {
+		t.Errorf("gRPC port = %q, want 19010", got)
+	}
+}
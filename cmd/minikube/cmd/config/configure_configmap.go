@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/minikube/pkg/addons"
+)
+
+// askConfigMapValues prompts for the configurable keys of the ConfigMap
+func askConfigMapValues(cm addons.ConfigurableConfigMap) map[string]string {
+	data := map[string]string{}
+	for _, v := range cm.Values {
+		data[v.Key] = AskForStaticValidatedValue(v.Prompt, v.Validate)
+	}
+	return data
+}
+
+// applyConfigMapValues patches the addon ConfigMap with the values, then restarts the deployment reading it so it picks them up
+func applyConfigMapValues(ctx context.Context, profile string, cm addons.ConfigurableConfigMap, data map[string]string) error {
+	if err := configureClient.PatchConfigMap(ctx, profile, cm.Namespace, cm.Name, data); err != nil {
+		return fmt.Errorf("patch the %s/%s ConfigMap: %w", cm.Namespace, cm.Name, err)
+	}
+	recordApplied("updated the " + cm.Namespace + "/" + cm.Name + " ConfigMap")
+	if err := configureClient.RestartDeployment(ctx, profile, cm.Namespace, cm.Deployment); err != nil {
+		return fmt.Errorf("restart %s: %w", cm.Deployment, err)
+	}
+	recordApplied("restarted the " + cm.Namespace + "/" + cm.Deployment + " deployment")
+	return nil
+}
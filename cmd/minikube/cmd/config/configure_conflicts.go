@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sort"
+
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// addonConflict is a pair of addons which can't work together in the same cluster
+type addonConflict struct {
+	addons [2]string
+	reason string
+}
+
+// addonConflicts lists the addons which can't work together, in either order
+var addonConflicts = []addonConflict{
+	{[2]string{"ingress", "kong"}, "both are ingress controllers, they would serve the same ingresses and compete for ports 80 and 443"},
+	{[2]string{"ingress", "ambassador"}, "both are ingress controllers, they would serve the same ingresses and compete for ports 80 and 443"},
+	{[2]string{"kong", "ambassador"}, "both are ingress controllers, they would serve the same ingresses and compete for ports 80 and 443"},
+	{[2]string{"default-storageclass", "storage-provisioner-rancher"}, "both mark their storage class as the default one, so the volumes may be provisioned by either"},
+	{[2]string{"default-storageclass", "storage-provisioner-gluster"}, "both mark their storage class as the default one, so the volumes may be provisioned by either"},
+	{[2]string{"storage-provisioner-rancher", "storage-provisioner-gluster"}, "both mark their storage class as the default one, so the volumes may be provisioned by either"},
+	{[2]string{"nvidia-device-plugin", "nvidia-gpu-device-plugin"}, "both advertise the same GPUs to the kubelet, nvidia-gpu-device-plugin is deprecated"},
+}
+
+// conflictingAddons returns the addons enabled in cc which conflict with addon, with the reason of each conflict
+func conflictingAddons(cc *config.ClusterConfig, addon string) map[string]string {
+	conflicts := map[string]string{}
+	for _, c := range addonConflicts {
+		other := ""
+		switch addon {
+		case c.addons[0]:
+			other = c.addons[1]
+		case c.addons[1]:
+			other = c.addons[0]
+		default:
+			continue
+		}
+		if a, ok := assets.Addons[other]; ok && a.IsEnabled(cc) {
+			conflicts[other] = c.reason
+		}
+	}
+	return conflicts
+}
+
+// warnAddonConflicts warns about the enabled addons of the profile conflicting with addon, and how to resolve each conflict
+func warnAddonConflicts(profile, addon string) {
+	cc, err := config.Load(profile)
+	if err != nil {
+		klog.Warningf("unable to load profile %s to check the addons conflicting with %s: %v", profile, addon, err)
+		return
+	}
+	conflicts := conflictingAddons(cc, addon)
+	var others []string
+	for other := range conflicts {
+		others = append(others, other)
+	}
+	sort.Strings(others)
+	for _, other := range others {
+		reason := conflicts[other]
+		out.WarningT("The {{.addon}} addon conflicts with the enabled {{.other}} addon: {{.reason}}", out.V{"addon": addon, "other": other, "reason": reason})
+		out.Styled(style.Tip, "Only keep one of them enabled, eg. minikube -p {{.profile}} addons disable {{.other}}", out.V{"profile": profile, "other": other})
+	}
+}
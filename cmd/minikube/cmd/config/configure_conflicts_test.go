@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestAddonConflictsAreKnownAddons(t *testing.T) {
+	for _, c := range addonConflicts {
+		for _, a := range c.addons {
+			if _, ok := assets.Addons[a]; !ok {
+				t.Errorf("conflict %v lists the unknown addon %q", c.addons, a)
+			}
+		}
+	}
+}
+
+func TestConflictingAddons(t *testing.T) {
+	tests := []struct {
+		description string
+		addon       string
+		enabled     map[string]bool
+		want        []string
+	}{
+		{
+			description: "no conflicting addon enabled",
+			addon:       "ingress",
+			enabled:     map[string]bool{"metallb": true, "kong": false},
+		},
+		{
+			description: "conflicts in both orders",
+			addon:       "ingress",
+			enabled:     map[string]bool{"kong": true, "ambassador": true},
+			want:        []string{"ambassador", "kong"},
+		},
+		{
+			description: "addon listed second",
+			addon:       "storage-provisioner-rancher",
+			enabled:     map[string]bool{"default-storageclass": true},
+			want:        []string{"default-storageclass"},
+		},
+		{
+			description: "addon without conflicts",
+			addon:       "metrics-server",
+			enabled:     map[string]bool{"kong": true, "ingress": true},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			var got []string
+			for other, reason := range conflictingAddons(&config.ClusterConfig{Addons: tc.enabled}, tc.addon) {
+				if reason == "" {
+					t.Errorf("conflict with %s has no reason", other)
+				}
+				got = append(got, other)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("conflictingAddons(%s) = %v, want %v", tc.addon, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// corednsTarget is given to addons configure instead of an addon name to add custom host entries and forward rules
+// to the Corefile of CoreDNS. It is not an addon, nothing is saved in the profile.
+const corednsTarget = "coredns"
+
+// corednsMarker ends each line the coredns flow adds to the Corefile, so that the next run replaces them
+const corednsMarker = "# minikube addons configure coredns"
+
+// corednsHost is a static entry of the hosts plugin, resolving the names to the IP
+type corednsHost struct {
+	ip    string
+	names []string
+}
+
+// corednsForward forwards the queries of a zone to other DNS servers, eg. those of a corporate domain
+type corednsForward struct {
+	zone    string
+	servers []string
+}
+
+// corednsEntries holds the custom entries of the Corefile, an empty value removes those previously added
+type corednsEntries struct {
+	hosts    []corednsHost
+	forwards []corednsForward
+}
+
+// parseCorednsHost parses a host entry made of an IP followed by the host names, as in /etc/hosts
+func parseCorednsHost(s string) (corednsHost, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return corednsHost{}, fmt.Errorf("expected an IP followed by host names, got %q", s)
+	}
+	if net.ParseIP(fields[0]) == nil {
+		return corednsHost{}, fmt.Errorf("invalid IP %q", fields[0])
+	}
+	for _, name := range fields[1:] {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return corednsHost{}, fmt.Errorf("invalid host name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+	return corednsHost{ip: fields[0], names: fields[1:]}, nil
+}
+
+// parseCorednsServers parses the IPs of the DNS servers a zone is forwarded to, separated by spaces
+func parseCorednsServers(s string) ([]string, error) {
+	servers := strings.Fields(s)
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("expected at least one DNS server")
+	}
+	for _, server := range servers {
+		if net.ParseIP(server) == nil {
+			return nil, fmt.Errorf("invalid DNS server IP %q", server)
+		}
+	}
+	return servers, nil
+}
+
+// validateCorednsZone checks the zone is a domain, the root zone is the one of the cluster and can not be forwarded
+func validateCorednsZone(s string) error {
+	if errs := validation.IsDNS1123Subdomain(s); len(errs) > 0 {
+		return fmt.Errorf("invalid domain %q: %s", s, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validate checks the entries, and that a host name or a zone is not given twice
+func (e corednsEntries) validate() error {
+	names := map[string]bool{}
+	for _, h := range e.hosts {
+		if _, err := parseCorednsHost(h.ip + " " + strings.Join(h.names, " ")); err != nil {
+			return err
+		}
+		for _, name := range h.names {
+			if names[name] {
+				return fmt.Errorf("host name %q is given more than once", name)
+			}
+			names[name] = true
+		}
+	}
+	zones := map[string]bool{}
+	for _, f := range e.forwards {
+		if err := validateCorednsZone(f.zone); err != nil {
+			return err
+		}
+		if _, err := parseCorednsServers(strings.Join(f.servers, " ")); err != nil {
+			return err
+		}
+		if zones[f.zone] {
+			return fmt.Errorf("domain %q is forwarded more than once", f.zone)
+		}
+		zones[f.zone] = true
+	}
+	return nil
+}
+
+// patchCorefile replaces the entries previously added to the Corefile with the given ones. The host entries go to the
+// hosts block of the main server block, which is created before its forward plugin if missing, eg. on a cluster whose
+// host.minikube.internal entry could not be added. Each forward rule gets its own server block at the end of the Corefile.
+func patchCorefile(corefile string, e corednsEntries) (string, error) {
+	var lines []string
+	for _, l := range strings.Split(corefile, "\n") {
+		if !strings.HasSuffix(l, corednsMarker) {
+			lines = append(lines, l)
+		}
+	}
+
+	if len(e.hosts) > 0 {
+		hosts, forward := -1, -1
+		for i, l := range lines {
+			t := strings.TrimSpace(l)
+			if hosts < 0 && strings.HasPrefix(t, "hosts") && strings.HasSuffix(t, "{") {
+				hosts = i
+			}
+			if forward < 0 && strings.HasPrefix(t, "forward . ") {
+				forward = i
+			}
+		}
+		if hosts < 0 {
+			if forward < 0 {
+				return "", fmt.Errorf("the Corefile has neither a hosts block nor a forward plugin to add the hosts block before")
+			}
+			indent := leadingSpaces(lines[forward])
+			lines = insertLines(lines, forward, indent+"hosts {", indent+"   fallthrough", indent+"}")
+			hosts = forward
+		}
+		indent := leadingSpaces(lines[hosts]) + "   "
+		var entries []string
+		for _, h := range e.hosts {
+			entries = append(entries, fmt.Sprintf("%s%s %s %s", indent, h.ip, strings.Join(h.names, " "), corednsMarker))
+		}
+		lines = insertLines(lines, hosts+1, entries...)
+	}
+
+	end := len(lines)
+	for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	var blocks []string
+	for _, f := range e.forwards {
+		blocks = append(blocks,
+			fmt.Sprintf("%s:53 { %s", f.zone, corednsMarker),
+			"    errors "+corednsMarker,
+			"    cache 30 "+corednsMarker,
+			fmt.Sprintf("    forward . %s %s", strings.Join(f.servers, " "), corednsMarker),
+			"} "+corednsMarker)
+	}
+	return strings.Join(insertLines(lines, end, blocks...), "\n"), nil
+}
+
+// leadingSpaces returns the indentation of the line
+func leadingSpaces(l string) string {
+	return l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+}
+
+// insertLines returns the lines with the inserted ones at index i
+func insertLines(lines []string, i int, inserted ...string) []string {
+	return append(lines[:i], append(inserted, lines[i:]...)...)
+}
+
+// corednsConfigurator patches the Corefile of CoreDNS once all the entries are valid, then restarts CoreDNS
+type corednsConfigurator struct {
+	entries corednsEntries
+}
+
+// Validate prompts for the host entries and the forward rules
+func (c *corednsConfigurator) Validate(_ string) error {
+	e := corednsEntries{}
+	prompt := "\nDo you want to add a host entry to CoreDNS?"
+	for AskForYesNoConfirmation(prompt, posResponses, negResponses) {
+		line := AskForStaticCheckedValue("-- Enter the IP followed by the host names (ex. 192.168.49.1 registry.lab): ", func(s string) error {
+			_, err := parseCorednsHost(s)
+			return err
+		})
+		h, _ := parseCorednsHost(line)
+		e.hosts = append(e.hosts, h)
+		prompt = "-- Do you want to add another host entry?"
+	}
+	prompt = "\nDo you want to forward the queries of a domain to other DNS servers?"
+	for AskForYesNoConfirmation(prompt, posResponses, negResponses) {
+		zone := AskForStaticCheckedValue("-- Enter the domain (ex. corp.example.com): ", validateCorednsZone)
+		servers := AskForStaticCheckedValue("-- Enter the IPs of its DNS servers separated by spaces (ex. 10.0.0.53): ", func(s string) error {
+			_, err := parseCorednsServers(s)
+			return err
+		})
+		f := corednsForward{zone: zone}
+		f.servers, _ = parseCorednsServers(servers)
+		e.forwards = append(e.forwards, f)
+		prompt = "-- Do you want to forward another domain?"
+	}
+	if len(e.hosts) == 0 && len(e.forwards) == 0 {
+		out.Styled(style.Notice, "No entries given, the entries previously added to CoreDNS will be removed")
+	}
+	c.entries = e
+	return e.validate()
+}
+
+// Apply patches the Corefile of the coredns ConfigMap, and restarts CoreDNS if it changed
+func (c *corednsConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	data, err := configureClient.GetConfigMapData(ctx, profile, "kube-system", "coredns")
+	if err != nil {
+		return applyResult{}, fmt.Errorf("get the coredns ConfigMap: %w", err)
+	}
+	corefile, ok := data["Corefile"]
+	if !ok {
+		return applyResult{}, fmt.Errorf("the kube-system/coredns ConfigMap has no Corefile")
+	}
+	patched, err := patchCorefile(corefile, c.entries)
+	if err != nil {
+		return applyResult{}, err
+	}
+	if patched == corefile {
+		out.Styled(style.Check, "No changes to the CoreDNS configuration of {{.profile}}", out.V{"profile": profile})
+		return applyResult{}, nil
+	}
+	if err := configureClient.PatchConfigMap(ctx, profile, "kube-system", "coredns", map[string]string{"Corefile": patched}); err != nil {
+		return applyResult{}, fmt.Errorf("patch the coredns ConfigMap: %w", err)
+	}
+	recordApplied("updated the Corefile of the kube-system/coredns ConfigMap")
+	if err := configureClient.RestartDeployment(ctx, profile, "kube-system", "coredns"); err != nil {
+		return applyResult{}, fmt.Errorf("restart coredns: %w", err)
+	}
+	recordApplied("restarted the kube-system/coredns deployment")
+	return applyResult{}, nil
+}
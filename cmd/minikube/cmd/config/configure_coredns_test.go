@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCorefile is the Corefile of a minikube cluster, with the host.minikube.internal entry
+const testCorefile = `.:53 {
+        log
+        errors
+        kubernetes cluster.local in-addr.arpa ip6.arpa {
+           pods insecure
+           fallthrough in-addr.arpa ip6.arpa
+        }
+        hosts {
+           192.168.49.1 host.minikube.internal
+           fallthrough
+        }
+        forward . /etc/resolv.conf {
+           max_concurrent 1000
+        }
+        cache 30
+}
+`
+
+func TestParseCorednsHost(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  corednsHost
+		err   bool
+	}{
+		{entry: "192.168.49.1 registry.lab", want: corednsHost{ip: "192.168.49.1", names: []string{"registry.lab"}}},
+		{entry: " fd00::1  a.lab b.lab ", want: corednsHost{ip: "fd00::1", names: []string{"a.lab", "b.lab"}}},
+		{entry: "192.168.49.1", err: true},
+		{entry: "registry.lab 192.168.49.1", err: true},
+		{entry: "192.168.49.1 Registry_Lab", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.entry, func(t *testing.T) {
+			got, err := parseCorednsHost(tc.entry)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if !tc.err && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseCorednsHost(%q) = %v, want %v", tc.entry, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCorednsEntriesValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		entries     corednsEntries
+		err         bool
+	}{
+		{description: "empty"},
+		{description: "valid", entries: corednsEntries{
+			hosts:    []corednsHost{{ip: "10.0.0.1", names: []string{"a.lab"}}},
+			forwards: []corednsForward{{zone: "corp.example.com", servers: []string{"10.0.0.53", "10.0.0.54"}}},
+		}},
+		{description: "duplicate host name", entries: corednsEntries{hosts: []corednsHost{
+			{ip: "10.0.0.1", names: []string{"a.lab"}},
+			{ip: "10.0.0.2", names: []string{"a.lab"}},
+		}}, err: true},
+		{description: "invalid server", entries: corednsEntries{forwards: []corednsForward{{zone: "corp.example.com", servers: []string{"dns.corp"}}}}, err: true},
+		{description: "no server", entries: corednsEntries{forwards: []corednsForward{{zone: "corp.example.com"}}}, err: true},
+		{description: "root zone", entries: corednsEntries{forwards: []corednsForward{{zone: ".", servers: []string{"10.0.0.53"}}}}, err: true},
+		{description: "duplicate zone", entries: corednsEntries{forwards: []corednsForward{
+			{zone: "corp.example.com", servers: []string{"10.0.0.53"}},
+			{zone: "corp.example.com", servers: []string{"10.0.0.54"}},
+		}}, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if err := tc.entries.validate(); (err != nil) != tc.err {
+				t.Errorf("expected error %t but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestPatchCorefile(t *testing.T) {
+	entries := corednsEntries{
+		hosts:    []corednsHost{{ip: "10.0.0.1", names: []string{"registry.lab", "git.lab"}}},
+		forwards: []corednsForward{{zone: "corp.example.com", servers: []string{"10.0.0.53", "10.0.0.54"}}},
+	}
+	patched, err := patchCorefile(testCorefile, entries)
+	if err != nil {
+		t.Fatalf("patchCorefile returned unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"        hosts {\n           10.0.0.1 registry.lab git.lab " + corednsMarker + "\n           192.168.49.1 host.minikube.internal\n",
+		"}\ncorp.example.com:53 { " + corednsMarker + "\n",
+		"    forward . 10.0.0.53 10.0.0.54 " + corednsMarker + "\n} " + corednsMarker + "\n",
+	} {
+		if !strings.Contains(patched, want) {
+			t.Errorf("patched Corefile does not contain %q:\n%s", want, patched)
+		}
+	}
+
+	again, err := patchCorefile(patched, entries)
+	if err != nil || again != patched {
+		t.Errorf("patching the Corefile again with the same entries changed it:\n%s\nerror: %v", again, err)
+	}
+	removed, err := patchCorefile(patched, corednsEntries{})
+	if err != nil || removed != testCorefile {
+		t.Errorf("patching the Corefile without entries did not restore it:\n%s\nerror: %v", removed, err)
+	}
+
+	noHosts := strings.Replace(testCorefile, "        hosts {\n           192.168.49.1 host.minikube.internal\n           fallthrough\n        }\n", "", 1)
+	patched, err = patchCorefile(noHosts, corednsEntries{hosts: entries.hosts})
+	if err != nil {
+		t.Fatalf("patchCorefile returned unexpected error: %v", err)
+	}
+	want := "        hosts {\n           10.0.0.1 registry.lab git.lab " + corednsMarker + "\n           fallthrough\n        }\n        forward . /etc/resolv.conf {\n"
+	if !strings.Contains(patched, want) {
+		t.Errorf("patched Corefile does not contain %q:\n%s", want, patched)
+	}
+
+	if _, err := patchCorefile(".:53 {\n}\n", corednsEntries{hosts: entries.hosts}); err == nil {
+		t.Errorf("expected an error for a Corefile without hosts block nor forward plugin")
+	}
+}
+
+func TestCorednsApply(t *testing.T) {
+	tests := []struct {
+		description string
+		entries     corednsEntries
+		calls       []string
+	}{
+		{
+			description: "new entries",
+			entries:     corednsEntries{hosts: []corednsHost{{ip: "10.0.0.1", names: []string{"registry.lab"}}}},
+			calls: []string{
+				"GetConfigMapData kube-system/coredns",
+				"PatchConfigMap kube-system/coredns",
+				"RestartDeployment kube-system/coredns",
+			},
+		},
+		{
+			description: "unchanged",
+			calls:       []string{"GetConfigMapData kube-system/coredns"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			f.configMaps["kube-system/coredns"] = map[string]string{"Corefile": testCorefile}
+
+			c := &corednsConfigurator{entries: tc.entries}
+			if _, err := c.Apply(context.Background(), "coredns"); err != nil {
+				t.Fatalf("Apply() returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+		})
+	}
+}
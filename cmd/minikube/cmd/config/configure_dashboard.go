@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+const (
+	dashboardNamespace = "kubernetes-dashboard"
+	// dashboardAdmin is the service account whose token is used to log in to the dashboard, and its cluster-admin binding
+	dashboardAdmin = "dashboard-admin"
+)
+
+// dashboardConfigurator sets the dashboard auth mode, provisioning or removing the login token, and its settings
+type dashboardConfigurator struct {
+	cfg       *config.ClusterConfig
+	tokenAuth bool
+	// settings holds the values of the dashboard settings ConfigMap, nil if they are left unchanged
+	settings map[string]string
+}
+
+// Validate prompts for the auth mode, and for the settings if the dashboard is enabled
+func (d *dashboardConfigurator) Validate(profile string) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	d.cfg = cfg
+	d.tokenAuth = AskForYesNoConfirmation("-- Do you want to require a token to log in to the dashboard instead of allowing to skip the login?", posResponses, negResponses)
+	// the settings ConfigMap is only created when the addon is enabled
+	if assets.Addons["dashboard"].IsEnabled(cfg) && AskForYesNoConfirmation("\nDo you want to change the dashboard settings?", posResponses, negResponses) {
+		d.settings = askConfigMapValues(addons.ConfigurableConfigMaps["dashboard"])
+	}
+	return nil
+}
+
+// Apply saves the auth mode and re-enables the dashboard to generate its manifests with it,
+// then creates or removes the login token and patches the settings
+func (d *dashboardConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	d.cfg.DashboardTokenAuth = d.tokenAuth
+	if err := saveAndReenableAddon(profile, d.cfg, "dashboard", false); err != nil {
+		return applyResult{}, err
+	}
+
+	if d.tokenAuth {
+		token, err := dashboardLoginToken(ctx, profile)
+		if err != nil {
+			return applyResult{}, fmt.Errorf("create the dashboard login token: %w", err)
+		}
+		recordApplied("created the " + dashboardNamespace + "/" + dashboardAdmin + " login token")
+		out.Styled(style.Tip, "Use this token to log in to the dashboard:")
+		out.Ln("%s", token)
+	} else {
+		if err := removeDashboardLoginToken(ctx, profile); err != nil {
+			return applyResult{}, fmt.Errorf("remove the dashboard login token: %w", err)
+		}
+		recordApplied("removed the " + dashboardNamespace + "/" + dashboardAdmin + " login token")
+	}
+
+	if d.settings != nil {
+		return applyResult{}, applyConfigMapValues(ctx, profile, addons.ConfigurableConfigMaps["dashboard"], d.settings)
+	}
+	return applyResult{}, nil
+}
+
+// dashboardLoginToken binds the dashboard admin service account to cluster-admin and returns its token
+func dashboardLoginToken(ctx context.Context, profile string) (string, error) {
+	if err := configureClient.EnsureNamespace(ctx, profile, dashboardNamespace); err != nil {
+		return "", err
+	}
+	labels := map[string]string{"kubernetes.io/minikube-addons": "dashboard"}
+	if err := configureClient.CreateClusterRoleBinding(ctx, profile, dashboardAdmin, "cluster-admin", dashboardNamespace, dashboardAdmin, labels); err != nil {
+		return "", err
+	}
+	return configureClient.CreateServiceAccountToken(ctx, profile, dashboardNamespace, dashboardAdmin)
+}
+
+// removeDashboardLoginToken deletes the cluster-admin binding of the dashboard admin service account, then the account and its token,
+// so a token handed out while token auth was required no longer grants access to the cluster
+func removeDashboardLoginToken(ctx context.Context, profile string) error {
+	if err := configureClient.DeleteClusterRoleBinding(ctx, profile, dashboardAdmin); err != nil {
+		return err
+	}
+	return configureClient.DeleteServiceAccountToken(ctx, profile, dashboardNamespace, dashboardAdmin)
+}
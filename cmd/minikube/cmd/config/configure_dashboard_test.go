@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestDashboardConfiguratorApply(t *testing.T) {
+	tests := []struct {
+		description string
+		tokenAuth   bool
+		settings    map[string]string
+		calls       []string
+	}{
+		{
+			description: "token auth",
+			tokenAuth:   true,
+			calls: []string{
+				"EnableAddon dashboard",
+				"EnsureNamespace kubernetes-dashboard",
+				"CreateClusterRoleBinding dashboard-admin",
+				"CreateServiceAccountToken kubernetes-dashboard/dashboard-admin",
+			},
+		},
+		{
+			description: "token auth off removes the binding, the service account and its token",
+			calls: []string{
+				"EnableAddon dashboard",
+				"DeleteClusterRoleBinding dashboard-admin",
+				"DeleteServiceAccountToken kubernetes-dashboard/dashboard-admin",
+			},
+		},
+		{
+			description: "settings are patched once the auth mode is applied",
+			settings:    map[string]string{"_global": "{}"},
+			calls: []string{
+				"EnableAddon dashboard",
+				"DeleteClusterRoleBinding dashboard-admin",
+				"DeleteServiceAccountToken kubernetes-dashboard/dashboard-admin",
+				"PatchConfigMap kubernetes-dashboard/kubernetes-dashboard-settings",
+				"RestartDeployment kubernetes-dashboard/kubernetes-dashboard",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			cfg := &config.ClusterConfig{Name: "dashboard", Addons: map[string]bool{"dashboard": true}, DashboardTokenAuth: !tc.tokenAuth}
+			if err := config.SaveProfile("dashboard", cfg); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			d := &dashboardConfigurator{cfg: cfg, tokenAuth: tc.tokenAuth, settings: tc.settings}
+			if _, err := d.Apply(context.Background(), "dashboard"); err != nil {
+				t.Fatalf("Apply returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			if saved := loadTestProfile(t, "dashboard"); saved.DashboardTokenAuth != tc.tokenAuth {
+				t.Errorf("saved token auth %t, want %t", saved.DashboardTokenAuth, tc.tokenAuth)
+			}
+		})
+	}
+}
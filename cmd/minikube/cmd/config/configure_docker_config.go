@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// defaultDockerConfigPath is where docker login stores the credentials of the registries
+const defaultDockerConfigPath = "~/.docker/config.json"
+
+// dockerConfig is the part of a docker config holding the credentials of the registries
+type dockerConfig struct {
+	Auths      map[string]dockerConfigAuth `json:"auths"`
+	CredsStore string                      `json:"credsStore"`
+}
+
+// dockerConfigAuth holds the credentials of a registry, either base64-encoded as "user:password" in auth, or in clear
+type dockerConfigAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// parseDockerConfig parses the credentials of the registries of a docker config
+func parseDockerConfig(data []byte) (dockerConfig, error) {
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing docker config: %w", err)
+	}
+	return cfg, nil
+}
+
+// servers returns the servers whose credentials are stored in the config, sorted, and the servers which were skipped
+// as the config does not hold their credentials, eg. because a credential helper stores them
+func (cfg dockerConfig) servers() ([]string, []string) {
+	var servers, skipped []string
+	for server, a := range cfg.Auths {
+		if a.Auth == "" && (a.Username == "" || a.Password == "") {
+			skipped = append(skipped, server)
+			continue
+		}
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	sort.Strings(skipped)
+	return servers, skipped
+}
+
+// registry returns the docker registry of the server with the credentials stored in the config
+func (cfg dockerConfig) registry(server string) (dockerRegistry, error) {
+	a, ok := cfg.Auths[server]
+	if !ok {
+		return dockerRegistry{}, fmt.Errorf("no credentials of %s", server)
+	}
+	user, password := a.Username, a.Password
+	if a.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return dockerRegistry{}, fmt.Errorf("invalid auth of %s: %w", server, err)
+		}
+		var found bool
+		user, password, found = strings.Cut(string(decoded), ":")
+		if !found || user == "" || password == "" {
+			return dockerRegistry{}, fmt.Errorf("invalid auth of %s: expected user:password", server)
+		}
+	}
+	if !isValidRegistryServer(server) {
+		return dockerRegistry{}, fmt.Errorf("invalid docker registry server %q", server)
+	}
+	return dockerRegistry{server: server, user: user, password: password}, nil
+}
+
+// readDockerConfig returns the docker config file and the servers whose credentials it stores,
+// warning about those which were skipped
+func readDockerConfig(path string) (dockerConfig, []string, error) {
+	path = expandPath(path)
+	// Read file from the local disk, not from the cluster node
+	data, err := readTextFile(path)
+	if err != nil {
+		return dockerConfig{}, nil, err
+	}
+	cfg, err := parseDockerConfig(data)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	servers, skipped := cfg.servers()
+	for _, server := range skipped {
+		out.WarningT("Skipping {{.server}}, its credentials are not stored in {{.path}}, eg. they are kept by a credential helper", out.V{"server": server, "path": path})
+	}
+	if len(servers) == 0 {
+		return cfg, nil, fmt.Errorf("%s does not hold the credentials of any registry", path)
+	}
+	return cfg, servers, nil
+}
+
+// dockerConfigCredsConfig returns the config of the docker registry of the docker config file given with --import-docker-config.
+// The controller reads a single docker registry, so the first server of the file is imported, saying so if it holds several.
+func dockerConfigCredsConfig(path string) (registryCredsConfig, error) {
+	c := defaultRegistryCredsConfig()
+	cfg, servers, err := readDockerConfig(path)
+	if err != nil {
+		return c, err
+	}
+	if c.docker, err = cfg.registry(servers[0]); err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(servers) > 1 {
+		out.WarningT("{{.path}} holds the credentials of {{.count}} registries, registry-creds reads a single one: importing {{.server}}", out.V{"path": path, "count": len(servers), "server": servers[0]})
+	}
+	c.enable("dpr")
+	return c, nil
+}
+
+// askDockerConfigImport offers to import a registry the user logged in to with docker login, if any.
+// The controller reads a single docker registry, so the user picks the one to import when there are several.
+func askDockerConfigImport() (dockerRegistry, bool) {
+	if checkReadableFile(expandPath(defaultDockerConfigPath)) != nil {
+		return dockerRegistry{}, false
+	}
+	if !AskForYesNoConfirmation("-- Do you want to import a registry you logged in to with docker login ("+defaultDockerConfigPath+")?", posResponses, negResponses) {
+		return dockerRegistry{}, false
+	}
+	cfg, servers, err := readDockerConfig(defaultDockerConfigPath)
+	if err != nil {
+		out.FailureT("Unable to import the docker registry: {{.error}}", out.V{"error": err})
+		return dockerRegistry{}, false
+	}
+	server := servers[0]
+	if len(servers) > 1 {
+		server = AskForChoice("-- registry-creds reads a single docker registry, which one do you want to import?", servers)
+	}
+	r, err := cfg.registry(server)
+	if err != nil {
+		out.FailureT("Unable to import the docker registry: {{.error}}", out.V{"error": err})
+		return dockerRegistry{}, false
+	}
+	out.Styled(style.Check, "Imported the credentials of {{.server}}", out.V{"server": server})
+	return r, true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDockerConfig(t *testing.T) {
+	tests := []struct {
+		description string
+		config      string
+		servers     []string
+		skipped     []string
+		err         bool
+	}{
+		{
+			description: "auth and clear credentials",
+			config: `{"auths": {
+				"registry.example.com": {"auth": "dXNlcjpwYTpzcw=="},
+				"https://index.docker.io/v1/": {"username": "hub", "password": "secret"}
+			}}`,
+			servers: []string{"https://index.docker.io/v1/", "registry.example.com"},
+		},
+		{
+			description: "credential helper",
+			config:      `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}, "ghcr.io": {}}, "credsStore": "desktop"}`,
+			servers:     []string{"registry.example.com"},
+			skipped:     []string{"ghcr.io"},
+		},
+		{description: "invalid json", config: `{"auths":`, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := parseDockerConfig([]byte(tc.config))
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if tc.err {
+				return
+			}
+			servers, skipped := cfg.servers()
+			if !reflect.DeepEqual(servers, tc.servers) {
+				t.Errorf("servers() = %v, want %v", servers, tc.servers)
+			}
+			if !reflect.DeepEqual(skipped, tc.skipped) {
+				t.Errorf("servers() skipped %v, want %v", skipped, tc.skipped)
+			}
+		})
+	}
+}
+
+func TestDockerConfigRegistry(t *testing.T) {
+	tests := []struct {
+		description string
+		config      string
+		server      string
+		want        dockerRegistry
+		err         bool
+	}{
+		{
+			description: "auth",
+			config:      `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYTpzcw=="}, "ghcr.io": {"auth": "not base64!"}}}`,
+			server:      "registry.example.com",
+			want:        dockerRegistry{server: "registry.example.com", user: "user", password: "pa:ss"},
+		},
+		{
+			description: "clear credentials",
+			config:      `{"auths": {"https://index.docker.io/v1/": {"username": "hub", "password": "secret"}}}`,
+			server:      "https://index.docker.io/v1/",
+			want:        dockerRegistry{server: "https://index.docker.io/v1/", user: "hub", password: "secret"},
+		},
+		{description: "invalid base64", config: `{"auths": {"registry.example.com": {"auth": "not base64!"}}}`, server: "registry.example.com", err: true},
+		{description: "no password", config: `{"auths": {"registry.example.com": {"auth": "dXNlcg=="}}}`, server: "registry.example.com", err: true},
+		{description: "invalid server", config: `{"auths": {"bad server": {"auth": "dXNlcjpwYXNz"}}}`, server: "bad server", err: true},
+		{description: "missing server", config: `{"auths": {}}`, server: "registry.example.com", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg, err := parseDockerConfig([]byte(tc.config))
+			if err != nil {
+				t.Fatalf("parseDockerConfig() returned unexpected error: %v", err)
+			}
+			got, err := cfg.registry(tc.server)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Errorf("registry(%q) = %v, want %v", tc.server, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDockerConfigCredsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`), 0o600); err != nil {
+		t.Fatalf("unable to write the docker config: %v", err)
+	}
+	c, err := dockerConfigCredsConfig(path)
+	if err != nil {
+		t.Fatalf("dockerConfigCredsConfig() returned unexpected error: %v", err)
+	}
+	if !c.enabled["dpr"] {
+		t.Errorf("expected the docker registries to be enabled")
+	}
+	want := dockerRegistry{server: "registry.example.com", user: "user", password: "pass"}
+	if c.docker != want {
+		t.Errorf("docker registry = %v, want %v", c.docker, want)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}, "ghcr.io": {"auth": "bWU6c2VjcmV0"}}}`), 0o600); err != nil {
+		t.Fatalf("unable to write the docker config: %v", err)
+	}
+	c, err = dockerConfigCredsConfig(path)
+	if err != nil {
+		t.Fatalf("dockerConfigCredsConfig() returned unexpected error: %v", err)
+	}
+	want = dockerRegistry{server: "ghcr.io", user: "me", password: "secret"}
+	if c.docker != want {
+		t.Errorf("docker registry of a config with several registries = %v, want %v", c.docker, want)
+	}
+	if err := c.validate(); err != nil {
+		t.Errorf("validate() of the imported config returned unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"auths": {"ghcr.io": {}}, "credsStore": "desktop"}`), 0o600); err != nil {
+		t.Fatalf("unable to write the docker config: %v", err)
+	}
+	if _, err := dockerConfigCredsConfig(path); err == nil {
+		t.Errorf("expected an error for a docker config without credentials")
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// configureEdit opens the settings of the addon in an editor instead of prompting for them, set by --edit
+var configureEdit bool
+
+// runEditor opens the file in the editor of the user and waits for it to be closed
+var runEditor = func(path string) error {
+	args := strings.Fields(editorCommand())
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand returns the editor of the user, from $KUBE_EDITOR or $EDITOR like kubectl edit
+func editorCommand() string {
+	for _, env := range []string{"KUBE_EDITOR", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// renderAddonConfig returns the settings of the addon with their current values, as the YAML file to edit
+func renderAddonConfig(addon string, cc *config.ClusterConfig) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Edit the settings of the %s addon, then save and close the editor to apply them.\n", addon)
+	fmt.Fprintf(&b, "# Lines starting with '#' are ignored, leaving the settings unchanged cancels the edit.\n")
+	values := yaml.MapSlice{}
+	for _, k := range addonConfigKeyNames(addon) {
+		values = append(values, yaml.MapItem{Key: k, Value: addonConfigKeys[addon][k].get(cc)})
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	b.Write(data)
+	return b.Bytes(), nil
+}
+
+// parseEditedAddonConfig returns the settings whose value changed in the edited file, checking every one of them
+func parseEditedAddonConfig(addon string, cc *config.ClusterConfig, edited []byte) (map[string]string, error) {
+	settings := yaml.MapSlice{}
+	if err := yaml.UnmarshalStrict(edited, &settings); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	keys := addonConfigKeys[addon]
+	changed := map[string]string{}
+	for _, item := range settings {
+		k := fmt.Sprint(item.Key)
+		v := ""
+		if item.Value != nil {
+			v = fmt.Sprint(item.Value)
+		}
+		key, ok := keys[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown key %q for %s, expected one of %s", k, addon, strings.Join(addonConfigKeyNames(addon), ", "))
+		}
+		if v == key.get(cc) {
+			continue
+		}
+		if err := key.validate(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		changed[k] = v
+	}
+	return changed, nil
+}
+
+// editAddonConfig opens the settings of the addon in an editor, opening it again with the error while the
+// edited settings are invalid, then applies the changed ones like --set. It returns whether anything changed.
+func editAddonConfig(profile, addon string) bool {
+	if _, ok := addonConfigKeys[addon]; !ok {
+		exit.Message(reason.Usage, "{{.name}} has no settings which can be edited, use minikube addons configure {{.name}} instead", out.V{"name": addon})
+	}
+	_, cfg := mustload.Partial(profile)
+
+	f, err := os.CreateTemp("", "minikube-"+addon+"-*.yaml")
+	if err != nil {
+		exit.Error(reason.InternalAddonConfigure, "Unable to create the file to edit", err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+
+	content, err := renderAddonConfig(addon, cfg)
+	if err != nil {
+		exit.Error(reason.InternalAddonConfigure, "Unable to render the settings of the addon", err)
+	}
+	for {
+		if err := os.WriteFile(f.Name(), content, 0600); err != nil {
+			exit.Error(reason.InternalAddonConfigure, "Unable to write the file to edit", err)
+		}
+		if err := runEditor(f.Name()); err != nil {
+			exit.Message(reason.Usage, "Unable to run the editor {{.editor}}: {{.error}}, set $EDITOR to the editor to use", out.V{"editor": editorCommand(), "error": err})
+		}
+		edited, err := os.ReadFile(f.Name())
+		if err != nil {
+			exit.Error(reason.InternalAddonConfigure, "Unable to read the edited file", err)
+		}
+
+		values, err := parseEditedAddonConfig(addon, cfg, edited)
+		if err == nil {
+			if len(values) == 0 {
+				out.Styled(style.Check, "Edit cancelled, no changes made to {{.name}}", out.V{"name": addon})
+				return false
+			}
+			if err := applyAddonConfigValues(profile, addon, values, false); err != nil {
+				exitConfigure("failed to configure the addon", err)
+			}
+			return true
+		}
+
+		out.FailureT("Invalid settings: {{.error}}", out.V{"error": err})
+		if !AskForYesNoConfirmation("Do you want to edit them again?", posResponses, negResponses) {
+			exit.Message(reason.Usage, "Edit cancelled, no changes made to {{.name}}", out.V{"name": addon})
+		}
+		// keep the edits, with the error on top of the file
+		content = append([]byte(fmt.Sprintf("# %s\n#\n", err)), stripErrorHeader(edited)...)
+	}
+}
+
+// stripErrorHeader removes the error added on top of the edited file by a previous attempt
+func stripErrorHeader(edited []byte) []byte {
+	lines := strings.SplitAfter(string(edited), "\n")
+	if len(lines) >= 2 && strings.HasPrefix(lines[0], "# ") && strings.TrimSpace(lines[1]) == "#" {
+		return []byte(strings.Join(lines[2:], ""))
+	}
+	return edited
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestAddonConfigKeysHaveGetters(t *testing.T) {
+	for addon, keys := range addonConfigKeys {
+		for k, key := range keys {
+			if key.get == nil {
+				t.Errorf("%s key %q has no getter, it can't be edited", addon, k)
+			}
+		}
+	}
+}
+
+func TestParseEditedAddonConfig(t *testing.T) {
+	cc := &config.ClusterConfig{AutoPauseInterval: time.Minute, AutoPauseComponents: []string{"etcd"}}
+	rendered, err := renderAddonConfig("auto-pause", cc)
+	if err != nil {
+		t.Fatalf("renderAddonConfig returned unexpected error: %v", err)
+	}
+	tests := []struct {
+		description string
+		edited      string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{description: "unchanged", edited: string(rendered), want: map[string]string{}},
+		{description: "changed interval", edited: strings.Replace(string(rendered), "1m0s", "5m", 1), want: map[string]string{"interval": "5m"}},
+		{description: "comments only", edited: "# nothing\n", want: map[string]string{}},
+		{description: "invalid interval", edited: "interval: soon\n", wantErr: true},
+		{description: "unknown key", edited: "interval: 5m\ntimeout: 5m\n", wantErr: true},
+		{description: "invalid YAML", edited: "interval: [5m\n", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := parseEditedAddonConfig("auto-pause", cc, []byte(tc.edited))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseEditedAddonConfig() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseEditedAddonConfig() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// unquoted YAML booleans are read as the value of the key
+	got, err := parseEditedAddonConfig("dashboard", &config.ClusterConfig{}, []byte("token-auth: true\n"))
+	if err != nil || got["token-auth"] != "true" {
+		t.Errorf("expected token-auth to be changed to true, got %v and error %v", got, err)
+	}
+}
+
+func TestEditAddonConfig(t *testing.T) {
+	useFakeClusterClient(t)
+	cfg := &config.ClusterConfig{Name: "edit", AutoPauseInterval: time.Minute}
+	if err := config.SaveProfile("edit", cfg); err != nil {
+		t.Fatalf("unable to save profile: %v", err)
+	}
+	orig := runEditor
+	t.Cleanup(func() { runEditor = orig })
+	runEditor = func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(strings.Replace(string(data), "interval: 1m0s", "interval: 2m", 1)), 0600)
+	}
+
+	if !editAddonConfig("edit", "auto-pause") {
+		t.Fatalf("expected the edited interval to be applied")
+	}
+	if saved := loadTestProfile(t, "edit"); saved.AutoPauseInterval != 2*time.Minute {
+		t.Errorf("saved interval %s, want 2m0s", saved.AutoPauseInterval)
+	}
+}
+
+func TestStripErrorHeader(t *testing.T) {
+	edited := "# interval: invalid\n#\n# Edit the settings\ninterval: 5m\n"
+	if got := string(stripErrorHeader([]byte(edited))); got != "# Edit the settings\ninterval: 5m\n" {
+		t.Errorf("stripErrorHeader() = %q", got)
+	}
+	if got := string(stripErrorHeader([]byte("interval: 5m\n"))); got != "interval: 5m\n" {
+		t.Errorf("stripErrorHeader() changed a file without error header: %q", got)
+	}
+}
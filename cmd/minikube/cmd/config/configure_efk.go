@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+// efkCredentialsSecret holds the user and password fluentd authenticates to the logging endpoint with
+const efkCredentialsSecret = "efk-elasticsearch-credentials"
+
+// validateLoggingEndpoint checks if s is the http or https URL of an Elasticsearch or OpenSearch endpoint,
+// eg. https://logs.example.com:9200. Credentials are given separately, they do not belong in the URL.
+func validateLoggingEndpoint(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", s, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("expected an http or https URL, got %q", s)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("missing host in %q", s)
+	}
+	if p := u.Port(); p != "" {
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid port in %q", s)
+		}
+	}
+	if u.User != nil {
+		return fmt.Errorf("credentials must not be part of the URL")
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("expected only a scheme, host and port, eg. https://logs.example.com:9200")
+	}
+	return nil
+}
+
+func processEFKConfig(profile string) {
+	_, cfg := mustload.Partial(profile)
+
+	endpoint := AskForStaticCheckedValue("-- Enter the Elasticsearch or OpenSearch endpoint the logs are shipped to (ex. https://logs.example.com:9200): ", validateLoggingEndpoint)
+	cfg.Logging.Endpoint = endpoint
+	cfg.Logging.Credentials = AskForYesNoConfirmation("-- Does the endpoint require a user and password?", posResponses, negResponses)
+
+	if cfg.Logging.Credentials {
+		data := map[string]string{
+			"user":     AskForStaticValue("-- Enter the user: "),
+			"password": AskForPasswordValue("-- Enter the password: "),
+		}
+		ctx, cancel := clusterContext()
+		defer cancel()
+		if err := configureClient.CreateSecret(ctx, profile, "kube-system", efkCredentialsSecret, data, map[string]string{"kubernetes.io/minikube-addons": "efk"}); err != nil {
+			exitConfigure("failed to create the efk credentials secret", classifyError(ErrSecretCreate, err))
+		}
+		recordApplied("created the kube-system/" + efkCredentialsSecret + " secret")
+	}
+
+	// Re-enable efk addon in order to generate template manifest files with the new endpoint
+	if err := saveAndReenableAddon(profile, cfg, "efk", false); err != nil {
+		exit.Error(reason.InternalAddonConfigure, "Failed to configure efk", err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+// The failure classes of the configure flows, tested with errors.Is. Each one is mapped to its own reason,
+// so users get a stable exit code per failure class.
+var (
+	// ErrInvalidInput is returned when the input of a configure flow is invalid
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrClusterUnavailable is returned when the cluster can not be reached
+	ErrClusterUnavailable = errors.New("cluster unavailable")
+	// ErrSecretCreate is returned when a secret of an addon can not be created
+	ErrSecretCreate = errors.New("secret create failed")
+)
+
+// configureError classifies an error of a configure flow, keeping its message
+type configureError struct {
+	class error
+	err   error
+}
+
+func (e *configureError) Error() string { return e.err.Error() }
+
+func (e *configureError) Unwrap() []error { return []error{e.class, e.err} }
+
+// classifyError returns err classified as class, or nil if err is nil
+func classifyError(class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &configureError{class: class, err: err}
+}
+
+// clusterError classifies err as ErrClusterUnavailable if it shows the API server could not be reached
+func clusterError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) || utilnet.IsConnectionRefused(err) || apierrors.IsServiceUnavailable(err) || apierrors.IsServerTimeout(err) {
+		return classifyError(ErrClusterUnavailable, err)
+	}
+	return err
+}
+
+// configureErrorReason returns the reason of the failure class of err, configure fails with its exit code
+func configureErrorReason(err error) reason.Kind {
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		return reason.Usage
+	case errors.Is(err, ErrClusterUnavailable):
+		return reason.InternalAddonConfigureUnavailable
+	case errors.Is(err, ErrSecretCreate):
+		return reason.InternalAddonConfigureSecret
+	default:
+		return reason.InternalAddonConfigure
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+func TestConfigureErrorReason(t *testing.T) {
+	tests := []struct {
+		description string
+		err         error
+		want        reason.Kind
+	}{
+		{description: "invalid input", err: classifyError(ErrInvalidInput, fmt.Errorf("bad range")), want: reason.Usage},
+		{description: "cluster unavailable", err: clusterError(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), want: reason.InternalAddonConfigureUnavailable},
+		{description: "secret create", err: classifyError(ErrSecretCreate, fmt.Errorf("forbidden")), want: reason.InternalAddonConfigureSecret},
+		{description: "secret create on unavailable cluster", err: classifyError(ErrSecretCreate, clusterError(syscall.ECONNREFUSED)), want: reason.InternalAddonConfigureUnavailable},
+		{description: "wrapped", err: fmt.Errorf("registry-creds-ecr secret: %w", classifyError(ErrSecretCreate, fmt.Errorf("forbidden"))), want: reason.InternalAddonConfigureSecret},
+		{description: "unclassified", err: fmt.Errorf("boom"), want: reason.InternalAddonConfigure},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := configureErrorReason(tc.err); got.ID != tc.want.ID {
+				t.Errorf("configureErrorReason(%v) = %s, want %s", tc.err, got.ID, tc.want.ID)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorKeepsMessage(t *testing.T) {
+	err := classifyError(ErrInvalidInput, fmt.Errorf("bad range"))
+	if err.Error() != "bad range" {
+		t.Errorf("classified error message = %q, want %q", err.Error(), "bad range")
+	}
+	if classifyError(ErrInvalidInput, nil) != nil {
+		t.Errorf("expected no error when classifying a nil error")
+	}
+	if err := clusterError(fmt.Errorf("not found")); errors.Is(err, ErrClusterUnavailable) {
+		t.Errorf("expected %v not to be classified as ErrClusterUnavailable", err)
+	}
+}
+
+// invalidConfigurator is an addonConfigurator whose input is always invalid
+type invalidConfigurator struct {
+	applied bool
+}
+
+func (c *invalidConfigurator) Validate(_ string) error { return fmt.Errorf("bad input") }
+
+func (c *invalidConfigurator) Apply(_ context.Context, _ string) (applyResult, error) {
+	c.applied = true
+	return applyResult{}, nil
+}
+
+func TestRunConfiguratorInvalidInput(t *testing.T) {
+	c := &invalidConfigurator{}
+	if _, err := runConfigurator("p1", c); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("runConfigurator() = %v, expected an ErrInvalidInput error", err)
+	}
+	if c.applied {
+		t.Errorf("Apply was called although the input is invalid")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out/register"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+// configureEvents is where configure sends its events, given by --events: stderr or the URL of a webhook
+var configureEvents string
+
+// configureEventTimeout bounds the delivery of an event to the webhook, so a slow webhook cannot hold configure
+const configureEventTimeout = 5 * time.Second
+
+// configureEventSink delivers an event, it is nil unless --events is set
+var configureEventSink func(event []byte)
+
+// configureEventScope is the profile and addon the events are about
+var configureEventScope struct {
+	profile string
+	addon   string
+}
+
+// setupConfigureEvents sets where the events are sent to, they are not sent anywhere if dest is empty
+func setupConfigureEvents(dest string) error {
+	if dest == "" {
+		return nil
+	}
+	if dest == "stderr" {
+		configureEventSink = func(event []byte) {
+			fmt.Fprintln(os.Stderr, string(event))
+		}
+		return nil
+	}
+	u, err := url.Parse(dest)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected stderr or the http or https URL of a webhook, got %q", dest)
+	}
+	configureEventSink = func(event []byte) {
+		postConfigureEvent(dest, event)
+	}
+	return nil
+}
+
+// postConfigureEvent sends the event to the webhook. Failing to deliver it is only logged, it never fails configure.
+func postConfigureEvent(webhook string, event []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), configureEventTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(event))
+	if err != nil {
+		klog.Warningf("unable to create the configure event request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		klog.Warningf("unable to send the configure event to %s: %v", webhook, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		klog.Warningf("the configure event was rejected by %s: %s", webhook, resp.Status)
+	}
+}
+
+// setConfigureEventScope sets the profile and addon of the next events
+func setConfigureEventScope(profile, addon string) {
+	configureEventScope.profile = profile
+	configureEventScope.addon = addon
+}
+
+// emitConfigureEvent sends an event recording the action on target, eg. "secret-created" on "kube-system/registry-creds-ecr",
+// and whether it failed with err
+func emitConfigureEvent(action, target string, err error) {
+	if configureEventSink == nil {
+		return
+	}
+	outcome, message := "success", ""
+	if err != nil {
+		outcome, message = "failure", err.Error()
+	}
+	event, merr := register.ConfigureEvent(configureEventScope.addon, configureEventScope.profile, action, target, outcome, message)
+	if merr != nil {
+		klog.Warningf("unable to marshal the configure event: %v", merr)
+		return
+	}
+	configureEventSink(event)
+}
+
+// eventingClient emits an event for each change the configure flows make to the cluster
+type eventingClient struct {
+	clusterClient
+}
+
+func (c eventingClient) CreateSecret(ctx context.Context, profile, namespace, name string, data, labels map[string]string) error {
+	err := c.clusterClient.CreateSecret(ctx, profile, namespace, name, data, labels)
+	emitConfigureEvent("secret-created", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) DeleteSecret(ctx context.Context, profile, namespace, name string) error {
+	err := c.clusterClient.DeleteSecret(ctx, profile, namespace, name)
+	emitConfigureEvent("secret-deleted", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) PatchConfigMap(ctx context.Context, profile, namespace, name string, data map[string]string) error {
+	err := c.clusterClient.PatchConfigMap(ctx, profile, namespace, name, data)
+	emitConfigureEvent("configmap-patched", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) CreateServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) (string, error) {
+	token, err := c.clusterClient.CreateServiceAccountToken(ctx, profile, namespace, serviceAccount)
+	emitConfigureEvent("token-created", namespace+"/"+serviceAccount, err)
+	return token, err
+}
+
+func (c eventingClient) DeleteServiceAccountToken(ctx context.Context, profile, namespace, serviceAccount string) error {
+	err := c.clusterClient.DeleteServiceAccountToken(ctx, profile, namespace, serviceAccount)
+	emitConfigureEvent("token-deleted", namespace+"/"+serviceAccount, err)
+	return err
+}
+
+func (c eventingClient) CreateClusterRoleBinding(ctx context.Context, profile, name, clusterRole, namespace, serviceAccount string, labels map[string]string) error {
+	err := c.clusterClient.CreateClusterRoleBinding(ctx, profile, name, clusterRole, namespace, serviceAccount, labels)
+	emitConfigureEvent("clusterrolebinding-created", name, err)
+	return err
+}
+
+func (c eventingClient) DeleteClusterRoleBinding(ctx context.Context, profile, name string) error {
+	err := c.clusterClient.DeleteClusterRoleBinding(ctx, profile, name)
+	emitConfigureEvent("clusterrolebinding-deleted", name, err)
+	return err
+}
+
+func (c eventingClient) ExposeContainerPorts(ctx context.Context, profile, namespace, deployment string, ports []core.ContainerPort) error {
+	err := c.clusterClient.ExposeContainerPorts(ctx, profile, namespace, deployment, ports)
+	emitConfigureEvent("ports-exposed", namespace+"/"+deployment, err)
+	return err
+}
+
+func (c eventingClient) RestartDeployment(ctx context.Context, profile, namespace, deployment string) error {
+	err := c.clusterClient.RestartDeployment(ctx, profile, namespace, deployment)
+	emitConfigureEvent("deployment-restarted", namespace+"/"+deployment, err)
+	return err
+}
+
+func (c eventingClient) ApplyLimitRange(ctx context.Context, profile, namespace, name string, spec core.LimitRangeSpec) error {
+	err := c.clusterClient.ApplyLimitRange(ctx, profile, namespace, name, spec)
+	emitConfigureEvent("limitrange-applied", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) WriteNodeFiles(cc *config.ClusterConfig, files map[string]string, service string) error {
+	err := c.clusterClient.WriteNodeFiles(cc, files, service)
+	emitConfigureEvent("node-files-written", service, err)
+	return err
+}
+
+func (c eventingClient) UpdateNodes(ctx context.Context, profile string, names []string, changes service.NodeChanges) ([]string, error) {
+	updated, err := c.clusterClient.UpdateNodes(ctx, profile, names, changes)
+	for _, name := range updated {
+		emitConfigureEvent("node-updated", name, nil)
+	}
+	if err != nil {
+		emitConfigureEvent("node-updated", strings.Join(names, ","), err)
+	}
+	return updated, err
+}
+
+func (c eventingClient) ApplyResourceQuota(ctx context.Context, profile, namespace, name string, spec core.ResourceQuotaSpec) error {
+	err := c.clusterClient.ApplyResourceQuota(ctx, profile, namespace, name, spec)
+	emitConfigureEvent("resourcequota-applied", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) CreateIngress(ctx context.Context, profile, namespace, name string, spec networking.IngressSpec) error {
+	err := c.clusterClient.CreateIngress(ctx, profile, namespace, name, spec)
+	emitConfigureEvent("ingress-created", namespace+"/"+name, err)
+	return err
+}
+
+func (c eventingClient) EnableAddon(cc *config.ClusterConfig, name string) error {
+	err := c.clusterClient.EnableAddon(cc, name)
+	emitConfigureEvent("addon-enabled", name, err)
+	return err
+}
+
+func (c eventingClient) SetAddonEnabled(profile, name string) error {
+	err := c.clusterClient.SetAddonEnabled(profile, name)
+	emitConfigureEvent("addon-enabled", name, err)
+	return err
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// configureEventData decodes the data of a configure event
+func configureEventData(t *testing.T, event []byte) map[string]string {
+	t.Helper()
+	var e struct {
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(event, &e); err != nil {
+		t.Fatalf("invalid event %s: %v", event, err)
+	}
+	if e.Type != "io.k8s.sigs.minikube.configure" {
+		t.Errorf("event type = %q, want io.k8s.sigs.minikube.configure", e.Type)
+	}
+	return e.Data
+}
+
+func TestSetupConfigureEvents(t *testing.T) {
+	tests := []struct {
+		dest string
+		sink bool
+		err  bool
+	}{
+		{dest: "", sink: false},
+		{dest: "stderr", sink: true},
+		{dest: "https://hooks.example.com/minikube", sink: true},
+		{dest: "stdout", err: true},
+		{dest: "ftp://hooks.example.com", err: true},
+		{dest: "https://", err: true},
+	}
+	defer func() { configureEventSink = nil }()
+	for _, tc := range tests {
+		configureEventSink = nil
+		err := setupConfigureEvents(tc.dest)
+		if (err != nil) != tc.err {
+			t.Errorf("setupConfigureEvents(%q) expected error %t but got %v", tc.dest, tc.err, err)
+		}
+		if (configureEventSink != nil) != tc.sink {
+			t.Errorf("setupConfigureEvents(%q) set a sink %t, want %t", tc.dest, configureEventSink != nil, tc.sink)
+		}
+	}
+}
+
+func TestEventingClient(t *testing.T) {
+	f := useFakeClusterClient(t)
+	var events [][]byte
+	configureEventSink = func(event []byte) { events = append(events, event) }
+	defer func() { configureEventSink = nil }()
+	setConfigureEventScope("events", "registry-creds")
+	c := eventingClient{f}
+
+	if err := c.CreateSecret(context.Background(), "events", "kube-system", "registry-creds-ecr", nil, nil); err != nil {
+		t.Fatalf("CreateSecret() unexpected error: %v", err)
+	}
+	f.err = fmt.Errorf("apply failed")
+	if err := c.EnableAddon(&config.ClusterConfig{}, "registry-creds"); err == nil {
+		t.Fatalf("EnableAddon() expected the error of the client")
+	}
+	// reads are not changes, they are not reported
+	if _, err := c.CheckSecretExists(context.Background(), "events", "kube-system", "registry-creds-ecr"); err != nil {
+		t.Fatalf("CheckSecretExists() unexpected error: %v", err)
+	}
+
+	want := []map[string]string{
+		{"addon": "registry-creds", "profile": "events", "action": "secret-created", "target": "kube-system/registry-creds-ecr", "outcome": "success", "message": ""},
+		{"addon": "registry-creds", "profile": "events", "action": "addon-enabled", "target": "registry-creds", "outcome": "failure", "message": "apply failed"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if got := configureEventData(t, e); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("event %d = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestPostConfigureEvent(t *testing.T) {
+	var received []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	defer func() { configureEventSink = nil }()
+	if err := setupConfigureEvents(server.URL); err != nil {
+		t.Fatalf("setupConfigureEvents() unexpected error: %v", err)
+	}
+	setConfigureEventScope("events", "metallb")
+	emitConfigureEvent("configured", "metallb", nil)
+
+	if contentType != "application/cloudevents+json" {
+		t.Errorf("content type = %q, want application/cloudevents+json", contentType)
+	}
+	if got := configureEventData(t, received); got["action"] != "configured" || got["outcome"] != "success" {
+		t.Errorf("webhook received %v, want a successful configured event", got)
+	}
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// configureFile is the path of the file listing the addons to configure, given by --file
+var configureFile string
+
+// addonsConfigFile lists the addons configured by --file, they are configured in the order of the file. eg.
+//
+//	addons:
+//	- name: metallb
+//	  enable: true
+//	  settings:
+//	    range: 192.168.49.100-192.168.49.120
+//	- name: registry-creds
+//	  settings:
+//	    docker-server: registry.example.com
+//	    docker-user: me
+//	    docker-password: ${REGISTRY_PASSWORD} # or @/run/secrets/registry-password
+type addonsConfigFile struct {
+	Addons []addonConfigEntry `yaml:"addons"`
+}
+
+// addonConfigEntry is the configuration of one addon in the file
+type addonConfigEntry struct {
+	Name string `yaml:"name"`
+	// Enable enables the addon once configured, an addon which is already enabled is always re-enabled
+	Enable bool `yaml:"enable"`
+	// Settings are the keys accepted by --set for the addon, or the docker registry of registry-creds
+	Settings map[string]string `yaml:"settings"`
+}
+
+// registryCredsFileKeys are the settings of registry-creds in the file, the password is expanded from the environment.
+// The user and the password may be read from a file given as @FILE.
+var registryCredsFileKeys = []string{"docker-server", "docker-user", "docker-password"}
+
+// loadAddonsConfigFile reads the file, rejecting unknown fields
+func loadAddonsConfigFile(path string) (*addonsConfigFile, error) {
+	path = expandPath(path)
+	b, err := readTextFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &addonsConfigFile{}
+	if err := yaml.UnmarshalStrict(b, f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(f.Addons) == 0 {
+		return nil, fmt.Errorf("%s does not list any addon", path)
+	}
+	return f, nil
+}
+
+// pairs returns the settings of the entry as sorted key=value pairs, as given to --set
+func (e addonConfigEntry) pairs() []string {
+	pairs := []string{}
+	for k, v := range e.Settings {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// field names the entry in the validation results, eg. "addons[0] metallb"
+func (e addonConfigEntry) field(i int) string {
+	return fmt.Sprintf("addons[%d] %s", i, e.Name)
+}
+
+// registryCredsFileConfig returns the registry-creds config of the docker registry in the settings
+func registryCredsFileConfig(settings map[string]string) (registryCredsConfig, error) {
+	c := defaultRegistryCredsConfig()
+	for k := range settings {
+		if !containsString(registryCredsFileKeys, k) {
+			return c, fmt.Errorf("unknown key %q for registry-creds, expected one of %v", k, registryCredsFileKeys)
+		}
+	}
+	server := settings["docker-server"]
+	if !isValidRegistryServer(server) {
+		return c, fmt.Errorf("invalid docker registry server %q", server)
+	}
+	user, err := resolveFileValue(settings["docker-user"])
+	if err != nil {
+		return c, fmt.Errorf("docker-user: %w", err)
+	}
+	if user == "" {
+		return c, fmt.Errorf("docker-server requires docker-user and docker-password")
+	}
+	password, err := resolveFileValue(settings["docker-password"])
+	if err == nil && password == settings["docker-password"] {
+		password, err = expandEnv(password)
+	}
+	if err != nil {
+		return c, fmt.Errorf("docker-password: %w", err)
+	}
+	if password == "" {
+		return c, fmt.Errorf("docker-server requires docker-user and docker-password")
+	}
+	c.docker = dockerRegistry{server: server, user: user, password: password}
+	c.enable("dpr")
+	return c, nil
+}
+
+// validateAddonConfigEntry checks the addon and all its settings
+func validateAddonConfigEntry(e addonConfigEntry) error {
+	if _, ok := assets.Addons[e.Name]; !ok {
+		return fmt.Errorf("%q is not a valid addon", e.Name)
+	}
+	if len(e.Settings) == 0 {
+		if !e.Enable {
+			return fmt.Errorf("neither settings nor enable are given")
+		}
+		return nil
+	}
+	if e.Name == "registry-creds" {
+		_, err := registryCredsFileConfig(e.Settings)
+		return err
+	}
+	_, err := parseAddonConfigSet(e.Name, e.pairs())
+	return err
+}
+
+// validateAddonsConfigFile checks every addon of the file, one result per addon
+func validateAddonsConfigFile(f *addonsConfigFile) []fieldValidation {
+	results := []fieldValidation{}
+	for i, e := range f.Addons {
+		results = append(results, fieldValidation{field: e.field(i), err: validateAddonConfigEntry(e)})
+	}
+	return results
+}
+
+// applyAddonConfigEntry configures the addon of a validated entry
+func applyAddonConfigEntry(profile string, e addonConfigEntry) error {
+	if e.Name == "registry-creds" && len(e.Settings) > 0 {
+		c, err := registryCredsFileConfig(e.Settings)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := clusterContext()
+		defer cancel()
+		if _, err := (&registryCredsConfigurator{config: c}).Apply(ctx, profile); err != nil {
+			return err
+		}
+		return applyAddonConfigValues(profile, e.Name, nil, e.Enable)
+	}
+
+	var values map[string]string
+	if len(e.Settings) > 0 {
+		var err error
+		if values, err = parseAddonConfigSet(e.Name, e.pairs()); err != nil {
+			return err
+		}
+	}
+	return applyAddonConfigValues(profile, e.Name, values, e.Enable)
+}
+
+// mustLoadAddonsConfigFile loads the file given with --file, exiting if it can not be read or one of its addons is invalid
+func mustLoadAddonsConfigFile(path string) *addonsConfigFile {
+	f, err := loadAddonsConfigFile(path)
+	if err != nil {
+		exit.Message(reason.Usage, "Invalid configuration file: {{.error}}", out.V{"error": err})
+	}
+	if results := validateAddonsConfigFile(f); hasInvalidFields(results) {
+		reportConfigureValidation(results)
+	}
+	return f
+}
+
+// configureFromFile validates every addon of the file, then configures them in order, reporting the outcome of each
+func configureFromFile(profile, path string) {
+	f := mustLoadAddonsConfigFile(path)
+	ensureNotPaused(profile)
+	if failed := applyAddonsConfigFile(profile, f); failed > 0 {
+		exit.Message(reason.InternalAddonConfigure, "{{.count}} of the {{.total}} addons failed to be configured", out.V{"count": failed, "total": len(f.Addons)})
+	}
+	out.SuccessT("All the addons of {{.file}} were successfully configured", out.V{"file": path})
+}
+
+// applyAddonsConfigFile configures the addons of the file on the profile in order, reporting the outcome of each,
+// and returns how many of them failed
+func applyAddonsConfigFile(profile string, f *addonsConfigFile) int {
+	failed := 0
+	for i, e := range f.Addons {
+		setConfigureEventScope(profile, e.Name)
+		warnAddonConflicts(profile, e.Name)
+		err := applyAddonConfigEntry(profile, e)
+		emitConfigureEvent("configured", e.Name, err)
+		if err != nil {
+			failed++
+			out.Styled(style.Failure, "{{.field}}: {{.error}}", out.V{"field": e.field(i), "error": err})
+			continue
+		}
+		saveUndoRecord(profile, e.Name)
+		out.Styled(style.Check, "{{.field}}: configured", out.V{"field": e.field(i)})
+		if cc, err := config.Load(profile); err == nil && !assets.Addons[e.Name].IsEnabled(cc) {
+			offerToEnableAddon(profile, e.Name, false)
+		}
+		runPostConfigHook(profile, e.Name)
+	}
+	return failed
+}
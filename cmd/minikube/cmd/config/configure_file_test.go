@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestLoadAddonsConfigFile(t *testing.T) {
+	tests := []struct {
+		description string
+		content     string
+		want        []addonConfigEntry
+		err         bool
+	}{
+		{
+			description: "scalar settings",
+			content: `addons:
+- name: dashboard
+  settings:
+    token-auth: true
+- name: auto-pause
+  enable: true
+  settings:
+    interval: 1m
+`,
+			want: []addonConfigEntry{
+				{Name: "dashboard", Settings: map[string]string{"token-auth": "true"}},
+				{Name: "auto-pause", Enable: true, Settings: map[string]string{"interval": "1m"}},
+			},
+		},
+		{
+			description: "unknown field",
+			content: `addons:
+- name: dashboard
+  enabled: true
+`,
+			err: true,
+		},
+		{
+			description: "no addons",
+			content:     "addons: []\n",
+			err:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "addons.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := loadAddonsConfigFile(path)
+			if (err != nil) != tc.err {
+				t.Fatalf("loadAddonsConfigFile() expected error %t but got %v", tc.err, err)
+			}
+			if tc.err {
+				return
+			}
+			if !reflect.DeepEqual(f.Addons, tc.want) {
+				t.Errorf("loadAddonsConfigFile() = %+v, want %+v", f.Addons, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateAddonsConfigFile(t *testing.T) {
+	t.Setenv("REGISTRY_PASSWORD", "secret")
+	f := &addonsConfigFile{Addons: []addonConfigEntry{
+		{Name: "metallb", Settings: map[string]string{"range": "10.0.0.1-10.0.0.10"}},
+		{Name: "ingress-dns", Enable: true},
+		{Name: "registry-creds", Settings: map[string]string{"docker-server": "registry.dev", "docker-user": "me", "docker-password": "${REGISTRY_PASSWORD}"}},
+		{Name: "unknown", Enable: true},
+		{Name: "dashboard"},
+		{Name: "metallb", Settings: map[string]string{"range": "10.0.0.1"}},
+		{Name: "registry-creds", Settings: map[string]string{"docker-server": "registry.dev", "docker-user": "me", "docker-password": "${UNDEFINED_PASSWORD}"}},
+		{Name: "registry-creds", Settings: map[string]string{"aws-region": "us-east-1"}},
+	}}
+
+	var invalid []string
+	for _, r := range validateAddonsConfigFile(f) {
+		if r.err != nil {
+			invalid = append(invalid, r.field)
+		}
+	}
+	want := []string{"addons[3] unknown", "addons[4] dashboard", "addons[5] metallb", "addons[6] registry-creds", "addons[7] registry-creds"}
+	if !reflect.DeepEqual(invalid, want) {
+		t.Errorf("validateAddonsConfigFile() invalid fields = %v, want %v", invalid, want)
+	}
+}
+
+func TestApplyAddonConfigEntry(t *testing.T) {
+	tests := []struct {
+		description string
+		entry       addonConfigEntry
+		calls       []string
+	}{
+		{
+			description: "settings of a disabled addon",
+			entry:       addonConfigEntry{Name: "auto-pause", Settings: map[string]string{"interval": "2m"}},
+		},
+		{
+			description: "settings of an enabled addon",
+			entry:       addonConfigEntry{Name: "metallb", Settings: map[string]string{"range": "10.0.0.1-10.0.0.10"}},
+			calls:       []string{"EnableAddon metallb"},
+		},
+		{
+			description: "enable only",
+			entry:       addonConfigEntry{Name: "ingress-dns", Enable: true},
+			calls:       []string{"EnableAddon ingress-dns"},
+		},
+		{
+			description: "registry-creds secrets",
+			entry:       addonConfigEntry{Name: "registry-creds", Enable: true, Settings: map[string]string{"docker-server": "registry.dev", "docker-user": "me", "docker-password": "secret"}},
+			calls: []string{
+				"EnsureNamespace kube-system",
+				"GetSecretData kube-system/registry-creds-ecr",
+				"GetSecretData kube-system/registry-creds-gcr",
+				"GetSecretData kube-system/registry-creds-acr",
+				"GetSecretData kube-system/registry-creds-dpr",
+				"CreateSecret kube-system/registry-creds-dpr",
+				"EnableAddon registry-creds",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			cc := &config.ClusterConfig{Name: "file", Addons: map[string]bool{"metallb": true}}
+			if err := config.SaveProfile("file", cc); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			if err := applyAddonConfigEntry("file", tc.entry); err != nil {
+				t.Fatalf("applyAddonConfigEntry() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// validateImageReference checks if s is an image reference which can be pulled, eg. registry.dev/gcp-auth-webhook:v0.1.1
+func validateImageReference(s string) error {
+	if _, err := name.ParseReference(s, name.WeakValidation); err != nil {
+		return fmt.Errorf("invalid image reference %q: %w", s, err)
+	}
+	return nil
+}
+
+// setCustomAddonImage makes the addon image named imageName use the full image reference,
+// dropping any custom registry of the image which would otherwise be prepended to it
+func setCustomAddonImage(cc *config.ClusterConfig, imageName, image string) {
+	if cc.CustomAddonImages == nil {
+		cc.CustomAddonImages = map[string]string{}
+	}
+	cc.CustomAddonImages[imageName] = image
+	delete(cc.CustomAddonRegistries, imageName)
+}
+
+// currentAddonImage returns the image currently used for the addon image named imageName
+func currentAddonImage(cc *config.ClusterConfig, addon, imageName string) string {
+	if image, ok := cc.CustomAddonImages[imageName]; ok {
+		return image
+	}
+	return assets.Addons[addon].Registries[imageName] + "/" + assets.Addons[addon].Images[imageName]
+}
+
+// askForImage asks for an optional image reference, returning an empty string to keep the current image
+func askForImage(prompt string) string {
+	for {
+		image := AskForStaticValueOptional(prompt)
+		if image == "" {
+			return ""
+		}
+		if err := validateImageReference(image); err != nil {
+			out.Err("--Invalid input, %v, please enter a value:", err)
+			continue
+		}
+		return image
+	}
+}
+
+func processGCPAuthConfig(profile string) {
+	_, cfg := mustload.Partial(profile)
+
+	webhook := askForImage("-- Enter the gcp-auth webhook image (leave empty to keep " + currentAddonImage(cfg, "gcp-auth", "GCPAuthWebhook") + "): ")
+	certgen := askForImage("-- Enter the webhook certgen image (leave empty to keep " + currentAddonImage(cfg, "gcp-auth", "KubeWebhookCertgen") + "): ")
+	if webhook == "" && certgen == "" {
+		out.Styled(style.Empty, "No changes to the gcp-auth images")
+		return
+	}
+	if webhook != "" {
+		setCustomAddonImage(cfg, "GCPAuthWebhook", webhook)
+	}
+	if certgen != "" {
+		setCustomAddonImage(cfg, "KubeWebhookCertgen", certgen)
+	}
+
+	if err := saveAndReenableAddon(profile, cfg, "gcp-auth", false); err != nil {
+		exit.Error(reason.InternalAddonConfigure, "Failed to configure gcp-auth", err)
+	}
+}
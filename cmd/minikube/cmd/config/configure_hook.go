@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// postConfigHook is the script run after an addon is successfully configured, given by --post-config-hook
+var postConfigHook string
+
+// postConfigHookTimeout bounds the run of the post-config hook, so a stuck script cannot hold configure
+const postConfigHookTimeout = 2 * time.Minute
+
+// validatePostConfigHook checks the hook is an existing executable file
+func validatePostConfigHook(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	// windows has no executable bit, the extension of the file tells whether it can be run
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
+	return nil
+}
+
+// execPostConfigHook runs the hook with the addon and the profile as arguments, and as the MINIKUBE_ADDON and
+// MINIKUBE_PROFILE environment variables. It returns an error if the hook exits with a non-zero code or times out.
+func execPostConfigHook(ctx context.Context, path, profile, addon string) error {
+	ctx, cancel := context.WithTimeout(ctx, postConfigHookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, addon, profile)
+	cmd.Env = append(os.Environ(), "MINIKUBE_ADDON="+addon, "MINIKUBE_PROFILE="+profile)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", postConfigHookTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("exited with code %d", exitErr.ExitCode())
+	}
+	return err
+}
+
+// runPostConfigHook runs the post-config hook, if any, once the addon is configured, and exits if it fails
+func runPostConfigHook(profile, addon string) {
+	if postConfigHook == "" {
+		return
+	}
+	out.Styled(style.Running, "Running the post-config hook {{.path}} ...", out.V{"path": postConfigHook})
+	err := execPostConfigHook(context.Background(), postConfigHook, profile, addon)
+	emitConfigureEvent("post-config-hook", postConfigHook, err)
+	if err != nil {
+		exit.Message(reason.InternalAddonConfigure, "The post-config hook {{.path}} failed: {{.error}}", out.V{"path": postConfigHook, "error": err})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeHook writes a shell script hook to a temporary directory and returns its path
+func writeHook(t *testing.T, script string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hook.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), perm); err != nil {
+		t.Fatalf("unable to write the hook: %v", err)
+	}
+	return path
+}
+
+func TestValidatePostConfigHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the executable bit is not checked on windows")
+	}
+	tests := []struct {
+		description string
+		path        func(t *testing.T) string
+		err         bool
+	}{
+		{description: "executable", path: func(t *testing.T) string { return writeHook(t, "exit 0\n", 0o755) }},
+		{description: "not executable", path: func(t *testing.T) string { return writeHook(t, "exit 0\n", 0o644) }, err: true},
+		{description: "missing", path: func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.sh") }, err: true},
+		{description: "directory", path: func(t *testing.T) string { return t.TempDir() }, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if err := validatePostConfigHook(tc.path(t)); (err != nil) != tc.err {
+				t.Errorf("expected error %t but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestExecPostConfigHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks of the test are shell scripts")
+	}
+	tests := []struct {
+		description string
+		script      string
+		err         string
+	}{
+		{description: "arguments", script: `[ "$1" = metallb ] && [ "$2" = p1 ]`},
+		{description: "environment", script: `[ "$MINIKUBE_ADDON" = metallb ] && [ "$MINIKUBE_PROFILE" = p1 ]`},
+		{description: "failure", script: "exit 3", err: "exited with code 3"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			path := writeHook(t, tc.script+"\n", 0o755)
+			err := execPostConfigHook(context.Background(), path, "p1", "metallb")
+			if tc.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+				t.Errorf("expected error %q but got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,474 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/util"
+)
+
+// defaultIngressController is the controller deployed by the ingress addon
+const defaultIngressController = "ingress-nginx/ingress-nginx-controller"
+
+const defaultSSLCertificateFlag = "--default-ssl-certificate="
+
+const (
+	ingressCertChoice       = "custom cert"
+	ingressSelfSignedChoice = "new self-signed cert"
+	ingressServicesChoice   = "TCP/UDP services"
+	ingressBackendChoice    = "default backend"
+)
+
+// ingressConfigChoices are what can be configured for the ingress addon
+var ingressConfigChoices = []string{ingressCertChoice, ingressSelfSignedChoice, ingressServicesChoice, ingressBackendChoice}
+
+// ingressSelfSignedCert is the secret holding the self-signed cert generated by configure, served by default by the controller
+const ingressSelfSignedCert = "kube-system/minikube-ingress-selfsigned"
+
+// the bounds of the validity period of a generated self-signed cert
+const (
+	minIngressCertValidity = time.Hour
+	maxIngressCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+// ingressReservedPorts are the ports the controller of the ingress addon already listens on
+var ingressReservedPorts = map[int]bool{80: true, 443: true, 8181: true, 8443: true, 10245: true, 10246: true, 10247: true, 10254: true}
+
+// ingressConfigurator configures one of ingressConfigChoices of the ingress addon
+type ingressConfigurator struct {
+	cfg    *config.ClusterConfig
+	choice string
+	// declined is set when the user kept the custom cert already set
+	declined bool
+	// cert is the custom cert, served by controller or by the controller of the addon if it is empty
+	cert       string
+	controller string
+	// validity is the validity period of the new self-signed cert
+	validity time.Duration
+	// tcp and udp hold the services to expose by port
+	tcp map[string]string
+	udp map[string]string
+	// backend is the new default backend, the default backend of the controller if it is empty
+	backend string
+}
+
+// Validate prompts for what to configure, then for its settings
+func (i *ingressConfigurator) Validate(profile string) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	i.cfg = cfg
+	i.choice = AskForChoice("-- What do you want to configure? ", ingressConfigChoices)
+	switch i.choice {
+	case ingressServicesChoice:
+		return i.askServices()
+	case ingressSelfSignedChoice:
+		return i.askSelfSigned()
+	case ingressBackendChoice:
+		return i.askBackend(profile)
+	default:
+		i.askCert()
+		return nil
+	}
+}
+
+// Apply configures what was chosen
+func (i *ingressConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	if i.declined {
+		return applyResult{unchanged: true}, nil
+	}
+	switch i.choice {
+	case ingressServicesChoice:
+		if err := applyIngressServices(ctx, profile, i.tcp, i.udp); err != nil {
+			return applyResult{}, fmt.Errorf("expose the services through the ingress controller: %w", err)
+		}
+		return applyResult{ingressServicesExposed: true}, nil
+	case ingressSelfSignedChoice:
+		expiry, err := regenerateIngressCert(ctx, profile, i.cfg, i.validity)
+		if err != nil {
+			return applyResult{}, fmt.Errorf("regenerate the ingress cert: %w", err)
+		}
+		return applyResult{ingressCertExpiry: expiry}, nil
+	case ingressBackendChoice:
+		if err := applyIngressBackend(profile, i.cfg, i.backend); err != nil {
+			return applyResult{}, fmt.Errorf("set the default backend of the ingress controller: %w", err)
+		}
+		return applyResult{ingressBackendChanged: true}, nil
+	default:
+		return applyResult{}, applyIngressConfig(ctx, profile, i.cfg, i.cert, i.controller)
+	}
+}
+
+// askCert prompts for the custom cert of the ingress addon, or of another ingress controller
+func (i *ingressConfigurator) askCert() {
+	i.cert = AskForStaticCheckedValue("-- Enter custom cert (format is \"namespace/secret\"): ", validateNamespacedName)
+	if i.cfg.KubernetesConfig.CustomIngressCert != "" {
+		overwrite := AskForYesNoConfirmation("A custom cert for ingress has already been set. Do you want overwrite it?", posResponses, negResponses)
+		if !overwrite {
+			i.declined = true
+			return
+		}
+	}
+
+	if !AskForYesNoConfirmation("-- Is the cert for the "+defaultIngressController+" controller of the ingress addon?", posResponses, negResponses) {
+		i.controller = AskForStaticCheckedValue("-- Enter ingress controller (format is \"namespace/deployment\"): ", validateNamespacedName)
+	}
+}
+
+// applyIngressConfig saves the custom cert of the ingress addon, or of the controller if one is given
+func applyIngressConfig(ctx context.Context, profile string, cfg *config.ClusterConfig, cert, controller string) error {
+	// the controller only serves the cert if the secret exists
+	certNamespace, certName, _ := strings.Cut(cert, "/")
+	exists, err := configureClient.CheckSecretExists(ctx, profile, certNamespace, certName)
+	if err != nil {
+		out.WarningT("Unable to check the {{.cert}} secret: {{.error}}", out.V{"cert": cert, "error": err})
+	} else if !exists {
+		out.WarningT("The {{.cert}} secret does not exist yet, the controller will use its default cert until it is created", out.V{"cert": cert})
+	}
+
+	cfg.KubernetesConfig.CustomIngressCert = cert
+	cfg.KubernetesConfig.CustomIngressController = controller
+
+	if err := saveProfileWithBackup(profile, cfg); err != nil {
+		return fmt.Errorf("save config %s: %w", profile, err)
+	}
+	if controller != "" {
+		if err := setIngressControllerCert(ctx, profile, controller, cert); err != nil {
+			return fmt.Errorf("configure ingress controller %s: %w", controller, err)
+		}
+	}
+	return nil
+}
+
+// setIngressControllerCert sets the default ssl certificate of a controller not managed by the ingress addon
+func setIngressControllerCert(ctx context.Context, profile, controller, cert string) error {
+	namespace, name, _ := strings.Cut(controller, "/")
+	client, err := kapi.Client(profile)
+	if err != nil {
+		return err
+	}
+	deployments := client.AppsV1().Deployments(namespace)
+	d, err := deployments.Get(ctx, name, meta.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get ingress controller %s: %w", controller, err)
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("ingress controller %s has no containers", controller)
+	}
+	c := &d.Spec.Template.Spec.Containers[0]
+	c.Args = withDefaultSSLCertificate(c.Args, cert)
+	if _, err := deployments.Update(ctx, d, meta.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update ingress controller %s: %w", controller, err)
+	}
+	recordApplied("set the default ssl certificate of the " + controller + " ingress controller")
+	return nil
+}
+
+// withDefaultSSLCertificate returns the controller args with the default ssl certificate set to cert
+func withDefaultSSLCertificate(args []string, cert string) []string {
+	var updated []string
+	for _, a := range args {
+		if !strings.HasPrefix(a, defaultSSLCertificateFlag) {
+			updated = append(updated, a)
+		}
+	}
+	return append(updated, defaultSSLCertificateFlag+cert)
+}
+
+// validateIngressCertValidity checks the validity period of a self-signed cert, between an hour and ten years
+func validateIngressCertValidity(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected eg. 8760h", s)
+	}
+	if d < minIngressCertValidity || d > maxIngressCertValidity {
+		return fmt.Errorf("the validity period must be between %s and %s", minIngressCertValidity, maxIngressCertValidity)
+	}
+	return nil
+}
+
+// askSelfSigned prompts for the validity period of a new self-signed cert served by the controller of the ingress addon
+func (i *ingressConfigurator) askSelfSigned() error {
+	// the controller is restarted to serve the new cert, it only runs when the addon is enabled
+	if !assets.Addons["ingress"].IsEnabled(i.cfg) {
+		return fmt.Errorf("the ingress addon must be enabled before it can be configured: minikube addons enable ingress")
+	}
+	if i.cfg.KubernetesConfig.CustomIngressCert != "" && i.cfg.KubernetesConfig.CustomIngressCert != ingressSelfSignedCert {
+		if !AskForYesNoConfirmation("A custom cert for ingress has already been set. Do you want to replace it with a self-signed cert?", posResponses, negResponses) {
+			i.declined = true
+			return nil
+		}
+	}
+	i.validity, _ = time.ParseDuration(AskForStaticCheckedValue("-- Enter the validity period of the new cert (ex. 8760h for a year): ", validateIngressCertValidity))
+	return nil
+}
+
+// regenerateIngressCert replaces the self-signed cert of the ingress addon with a new one valid for the given period,
+// then restarts the controller so it serves the new cert. It returns the expiry of the new cert.
+func regenerateIngressCert(ctx context.Context, profile string, cfg *config.ClusterConfig, validity time.Duration) (time.Time, error) {
+	certPEM, keyPEM, err := util.GenerateSelfSignedCert("minikube-ingress", validity)
+	if err != nil {
+		return time.Time{}, err
+	}
+	expiry := time.Now().Add(validity)
+	namespace, name, _ := strings.Cut(ingressSelfSignedCert, "/")
+	labels := map[string]string{"app": "ingress", "kubernetes.io/minikube-addons": "ingress"}
+	if err := configureClient.CreateSecret(ctx, profile, namespace, name, map[string]string{"tls.crt": string(certPEM), "tls.key": string(keyPEM)}, labels); err != nil {
+		return time.Time{}, classifyError(ErrSecretCreate, fmt.Errorf("create the %s secret: %w", ingressSelfSignedCert, err))
+	}
+	recordApplied("created the " + ingressSelfSignedCert + " secret")
+
+	// the controller of the addon is given the cert once, re-enabling the addon rolls it out with the new flag
+	if cfg.KubernetesConfig.CustomIngressCert != ingressSelfSignedCert || cfg.KubernetesConfig.CustomIngressController != "" {
+		cfg.KubernetesConfig.CustomIngressCert = ingressSelfSignedCert
+		cfg.KubernetesConfig.CustomIngressController = ""
+		return expiry, saveAndReenableAddon(profile, cfg, "ingress", false)
+	}
+	controllerNamespace, controller, _ := strings.Cut(defaultIngressController, "/")
+	if err := configureClient.RestartDeployment(ctx, profile, controllerNamespace, controller); err != nil {
+		return time.Time{}, fmt.Errorf("restart ingress controller %s: %w", defaultIngressController, err)
+	}
+	recordApplied("restarted the " + defaultIngressController + " ingress controller")
+	return expiry, nil
+}
+
+// parseIngressServices parses space separated "port=namespace/service:port" mappings
+// into the data of the tcp-services or udp-services ConfigMap of the ingress addon
+func parseIngressServices(s string) (map[string]string, error) {
+	services := map[string]string{}
+	for _, m := range strings.Fields(s) {
+		port, target, found := strings.Cut(m, "=")
+		if !found {
+			return nil, fmt.Errorf("expected a port=namespace/service:port mapping, got %q", m)
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return nil, fmt.Errorf("invalid port %q, must be between 1 and 65535", port)
+		}
+		if ingressReservedPorts[p] {
+			return nil, fmt.Errorf("port %d is already used by the ingress controller", p)
+		}
+		if _, ok := services[strconv.Itoa(p)]; ok {
+			return nil, fmt.Errorf("port %d is mapped more than once", p)
+		}
+		svc, svcPort, found := strings.Cut(target, ":")
+		if !found {
+			return nil, fmt.Errorf("expected a namespace/service:port target, got %q", target)
+		}
+		if err := validateNamespacedName(svc); err != nil {
+			return nil, err
+		}
+		if err := validateServicePort(svcPort); err != nil {
+			return nil, err
+		}
+		services[strconv.Itoa(p)] = target
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no port=namespace/service:port mapping")
+	}
+	return services, nil
+}
+
+// validateServicePort checks the port of a service, either its number or its name
+func validateServicePort(port string) error {
+	if p, err := strconv.Atoi(port); err == nil {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("invalid service port %d, must be between 1 and 65535", p)
+		}
+		return nil
+	}
+	if errs := validation.IsValidPortName(port); len(errs) > 0 {
+		return fmt.Errorf("invalid service port %q: %s", port, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// askForIngressServices asks for the services to expose for a protocol, returning nil if the prompt is skipped
+func askForIngressServices(protocol core.Protocol) map[string]string {
+	for {
+		s := AskForStaticValueOptional("-- (Optional) Enter the " + string(protocol) + " services to expose separated by space (format is \"port=namespace/service:port\"): ")
+		if s == "" {
+			return nil
+		}
+		services, err := parseIngressServices(s)
+		if err == nil {
+			return services
+		}
+		out.Err("--Invalid input, %v, please enter a value:", err)
+	}
+}
+
+// askServices prompts for the TCP and UDP services to expose through the controller of the ingress addon
+func (i *ingressConfigurator) askServices() error {
+	// the tcp-services and udp-services ConfigMaps are only created when the addon is enabled
+	if !assets.Addons["ingress"].IsEnabled(i.cfg) {
+		return fmt.Errorf("the ingress addon must be enabled before it can be configured: minikube addons enable ingress")
+	}
+
+	i.tcp = askForIngressServices(core.ProtocolTCP)
+	i.udp = askForIngressServices(core.ProtocolUDP)
+	if len(i.tcp) == 0 && len(i.udp) == 0 {
+		return fmt.Errorf("no TCP or UDP service to expose was entered")
+	}
+	return nil
+}
+
+// applyIngressServices adds the services to the tcp-services and udp-services ConfigMaps
+// and exposes their ports on the controller of the ingress addon
+func applyIngressServices(ctx context.Context, profile string, tcp, udp map[string]string) error {
+	namespace, controller, _ := strings.Cut(defaultIngressController, "/")
+	var ports []core.ContainerPort
+	for _, s := range []struct {
+		configMap string
+		protocol  core.Protocol
+		services  map[string]string
+	}{
+		{"tcp-services", core.ProtocolTCP, tcp},
+		{"udp-services", core.ProtocolUDP, udp},
+	} {
+		if len(s.services) == 0 {
+			continue
+		}
+		if err := configureClient.PatchConfigMap(ctx, profile, namespace, s.configMap, s.services); err != nil {
+			return fmt.Errorf("update the %s ConfigMap: %w", s.configMap, err)
+		}
+		recordApplied("updated the " + namespace + "/" + s.configMap + " ConfigMap")
+		ports = append(ports, ingressServicePorts(s.services, s.protocol)...)
+	}
+
+	if err := configureClient.ExposeContainerPorts(ctx, profile, namespace, controller, ports); err != nil {
+		return fmt.Errorf("expose the ports of ingress controller %s: %w", defaultIngressController, err)
+	}
+	recordApplied("exposed the ports of the services on the " + defaultIngressController + " ingress controller")
+	return nil
+}
+
+// ingressServicePorts returns the container ports, bound to the same host ports, exposing the services on the controller
+func ingressServicePorts(services map[string]string, protocol core.Protocol) []core.ContainerPort {
+	var ports []core.ContainerPort
+	for port := range services {
+		p, _ := strconv.Atoi(port)
+		ports = append(ports, core.ContainerPort{
+			// port names are limited to 15 characters, "udp-65535" fits
+			Name:          strings.ToLower(string(protocol)) + "-" + port,
+			ContainerPort: int32(p),
+			HostPort:      int32(p),
+			Protocol:      protocol,
+		})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].ContainerPort < ports[j].ContainerPort })
+	return ports
+}
+
+// validateIngressBackend checks a default backend of the ingress controller, as "namespace/service:port"
+func validateIngressBackend(s string) error {
+	svc, port, found := strings.Cut(s, ":")
+	if !found {
+		return fmt.Errorf("expected a namespace/service:port default backend, got %q", s)
+	}
+	if err := validateNamespacedName(svc); err != nil {
+		return err
+	}
+	return validateServicePort(port)
+}
+
+// validateIngressBackendPorts checks that the service of the default backend exists, given its ports,
+// and that the port is its first one: the controller only sends the requests to the first port of the service
+func validateIngressBackendPorts(backend string, ports []core.ServicePort) error {
+	svc, port, _ := strings.Cut(backend, ":")
+	if len(ports) == 0 {
+		return fmt.Errorf("the %s service does not exist or exposes no port", svc)
+	}
+	var names []string
+	for i, p := range ports {
+		if port != p.Name && port != strconv.Itoa(int(p.Port)) {
+			names = append(names, strconv.Itoa(int(p.Port)))
+			continue
+		}
+		if i > 0 {
+			return fmt.Errorf("port %s is not the first port of the %s service, the controller only serves its first port %d", port, svc, ports[0].Port)
+		}
+		return nil
+	}
+	return fmt.Errorf("the %s service has no port %s, its ports are %s", svc, port, strings.Join(names, ", "))
+}
+
+// askBackend prompts for the default backend of the ingress addon, serving the requests matching no ingress rule,
+// checking that its service exposes the port
+func (i *ingressConfigurator) askBackend(profile string) error {
+	ctx, cancel := clusterContext()
+	defer cancel()
+
+	var backend string
+	for {
+		s := AskForStaticCheckedValueOptional("-- Enter the default backend, or leave empty for the default backend of the controller (format is \"namespace/service:port\"): ", validateIngressBackend)
+		if s == "" {
+			break
+		}
+		svc, _, _ := strings.Cut(s, ":")
+		namespace, name, _ := strings.Cut(svc, "/")
+		ports, err := configureClient.GetServicePorts(ctx, profile, namespace, name)
+		if err != nil {
+			out.WarningT("Unable to check the {{.service}} service: {{.error}}", out.V{"service": svc, "error": err})
+			backend = s
+			break
+		}
+		err = validateIngressBackendPorts(s, ports)
+		if err == nil {
+			backend = s
+			break
+		}
+		if configureQuiet {
+			return fmt.Errorf("invalid default backend: %w", err)
+		}
+		out.Err("--Invalid default backend, %v, please enter it again:", err)
+	}
+	if backend == "" && i.cfg.KubernetesConfig.CustomIngressBackend == "" {
+		return fmt.Errorf("no default backend was entered")
+	}
+	i.backend = backend
+	return nil
+}
+
+// applyIngressBackend saves the default backend of the ingress addon, or removes it if backend is empty,
+// then re-enables the addon if it is enabled so its controller is given the new backend
+func applyIngressBackend(profile string, cfg *config.ClusterConfig, backend string) error {
+	cfg.KubernetesConfig.CustomIngressBackend = backend
+	if err := saveAndReenableAddon(profile, cfg, "ingress", false); err != nil {
+		return err
+	}
+	if backend == "" {
+		recordApplied("removed the default backend of the ingress controller")
+	} else {
+		recordApplied("set the default backend of the ingress controller to " + backend)
+	}
+	return nil
+}
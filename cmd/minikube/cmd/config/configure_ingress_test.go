@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"reflect"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestWithDefaultSSLCertificate(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		want        []string
+	}{
+		{
+			description: "no certificate set",
+			args:        []string{"/nginx-ingress-controller", "--election-id=leader"},
+			want:        []string{"/nginx-ingress-controller", "--election-id=leader", "--default-ssl-certificate=ns/cert"},
+		},
+		{
+			description: "replace certificate",
+			args:        []string{"/nginx-ingress-controller", "--default-ssl-certificate=old/cert", "--election-id=leader"},
+			want:        []string{"/nginx-ingress-controller", "--election-id=leader", "--default-ssl-certificate=ns/cert"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := withDefaultSSLCertificate(tc.args, "ns/cert"); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("withDefaultSSLCertificate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseIngressServices(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{input: "6379=default/redis:6379", want: map[string]string{"6379": "default/redis:6379"}},
+		{input: " 5432=db/postgres:5432  53=kube-system/kube-dns:dns ", want: map[string]string{"5432": "db/postgres:5432", "53": "kube-system/kube-dns:dns"}},
+		{input: "", wantErr: true},
+		{input: "6379", wantErr: true},
+		{input: "0=default/redis:6379", wantErr: true},
+		{input: "70000=default/redis:6379", wantErr: true},
+		{input: "443=default/redis:6379", wantErr: true},
+		{input: "6379=redis:6379", wantErr: true},
+		{input: "6379=default/redis", wantErr: true},
+		{input: "6379=default/redis:0", wantErr: true},
+		{input: "6379=default/redis:not_a_port", wantErr: true},
+		{input: "6379=default/redis:6379 6379=default/other:6379", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parseIngressServices(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseIngressServices(%q) error = %v, wantErr %t", tc.input, err, tc.wantErr)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseIngressServices(%q) = %v, want %v", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateIngressCertValidity(t *testing.T) {
+	tests := []struct {
+		validity string
+		valid    bool
+	}{
+		{validity: "8760h", valid: true},
+		{validity: "1h", valid: true},
+		{validity: "30m", valid: false},
+		{validity: "876001h", valid: false},
+		{validity: "1y", valid: false},
+		{validity: "", valid: false},
+	}
+	for _, tc := range tests {
+		if err := validateIngressCertValidity(tc.validity); (err == nil) != tc.valid {
+			t.Errorf("validateIngressCertValidity(%q) = %v, want valid %t", tc.validity, err, tc.valid)
+		}
+	}
+}
+
+func TestRegenerateIngressCert(t *testing.T) {
+	tests := []struct {
+		description string
+		cert        string
+		calls       []string
+	}{
+		{
+			description: "cert of the addon set",
+			calls:       []string{"CreateSecret kube-system/minikube-ingress-selfsigned", "EnableAddon ingress"},
+		},
+		{
+			description: "cert of the addon rotated",
+			cert:        ingressSelfSignedCert,
+			calls:       []string{"CreateSecret kube-system/minikube-ingress-selfsigned", "RestartDeployment ingress-nginx/ingress-nginx-controller"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			cc := &config.ClusterConfig{Name: "ingress", Addons: map[string]bool{"ingress": true}}
+			cc.KubernetesConfig.CustomIngressCert = tc.cert
+			if err := config.SaveProfile("ingress", cc); err != nil {
+				t.Fatalf("unable to save profile: %v", err)
+			}
+
+			expiry, err := regenerateIngressCert(context.Background(), "ingress", cc, 24*time.Hour)
+			if err != nil {
+				t.Fatalf("regenerateIngressCert() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+			data := f.secrets["kube-system/minikube-ingress-selfsigned"]
+			if _, err := tls.X509KeyPair([]byte(data["tls.crt"]), []byte(data["tls.key"])); err != nil {
+				t.Errorf("the secret does not hold a valid cert and key: %v", err)
+			}
+			if got := loadTestProfile(t, "ingress").KubernetesConfig.CustomIngressCert; got != ingressSelfSignedCert {
+				t.Errorf("saved ingress cert = %q, want %q", got, ingressSelfSignedCert)
+			}
+			if expiry.IsZero() {
+				t.Errorf("expected the expiry of the new cert to be returned")
+			}
+		})
+	}
+}
+
+func TestValidateIngressBackend(t *testing.T) {
+	tests := []struct {
+		backend string
+		valid   bool
+	}{
+		{backend: "default/backend:8080", valid: true},
+		{backend: "default/backend:http", valid: true},
+		{backend: "default/backend", valid: false},
+		{backend: "backend:8080", valid: false},
+		{backend: "default/backend:0", valid: false},
+		{backend: "default/backend:not_a_port", valid: false},
+		{backend: "", valid: false},
+	}
+	for _, tc := range tests {
+		if err := validateIngressBackend(tc.backend); (err == nil) != tc.valid {
+			t.Errorf("validateIngressBackend(%q) = %v, want valid %t", tc.backend, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateIngressBackendPorts(t *testing.T) {
+	ports := []core.ServicePort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9090}}
+	tests := []struct {
+		description string
+		backend     string
+		ports       []core.ServicePort
+		valid       bool
+	}{
+		{description: "first port number", backend: "default/backend:8080", ports: ports, valid: true},
+		{description: "first port name", backend: "default/backend:http", ports: ports, valid: true},
+		{description: "second port", backend: "default/backend:9090", ports: ports, valid: false},
+		{description: "unknown port", backend: "default/backend:80", ports: ports, valid: false},
+		{description: "missing service", backend: "default/backend:8080", valid: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if err := validateIngressBackendPorts(tc.backend, tc.ports); (err == nil) != tc.valid {
+				t.Errorf("validateIngressBackendPorts(%q) = %v, want valid %t", tc.backend, err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestApplyIngressBackend(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		f := useFakeClusterClient(t)
+		cc := &config.ClusterConfig{Name: "ingress", Addons: map[string]bool{"ingress": enabled}}
+		if err := config.SaveProfile("ingress", cc); err != nil {
+			t.Fatalf("unable to save profile: %v", err)
+		}
+
+		if err := applyIngressBackend("ingress", cc, "default/backend:8080"); err != nil {
+			t.Fatalf("applyIngressBackend() unexpected error: %v", err)
+		}
+		var want []string
+		if enabled {
+			want = []string{"EnableAddon ingress"}
+		}
+		if !reflect.DeepEqual(f.calls, want) {
+			t.Errorf("enabled %t: unexpected cluster operations %v, want %v", enabled, f.calls, want)
+		}
+		if got := loadTestProfile(t, "ingress").KubernetesConfig.CustomIngressBackend; got != "default/backend:8080" {
+			t.Errorf("enabled %t: saved default backend = %q, want %q", enabled, got, "default/backend:8080")
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// configureCtx is canceled when configure is interrupted, the cluster operations derive their context from it
+var configureCtx = context.Background()
+
+// configureProgress records the changes applied by configure, so they can be reported if it is interrupted
+var configureProgress struct {
+	sync.Mutex
+	applied []string
+}
+
+// recordApplied records a change applied to the profile or the cluster
+func recordApplied(change string) {
+	configureProgress.Lock()
+	defer configureProgress.Unlock()
+	configureProgress.applied = append(configureProgress.applied, change)
+}
+
+// appliedChanges returns the changes recorded so far
+func appliedChanges() []string {
+	configureProgress.Lock()
+	defer configureProgress.Unlock()
+	return append([]string{}, configureProgress.applied...)
+}
+
+// handleConfigureInterrupt cancels the cluster operations on SIGINT or SIGTERM, even while prompting,
+// and reports the changes applied so far before exiting
+func handleConfigureInterrupt() {
+	ctx, cancel := context.WithCancel(context.Background())
+	configureCtx = ctx
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-c
+		cancel()
+		out.Ln("")
+		applied := appliedChanges()
+		if len(applied) == 0 {
+			out.Styled(style.Empty, "No changes were applied")
+		} else {
+			out.Styled(style.Warning, "These changes were applied before the interruption, the others were not:")
+			for _, a := range applied {
+				out.Styled(style.Option, a)
+			}
+		}
+		exit.Message(reason.Interrupted, "Received {{.name}} signal", out.V{"name": sig})
+	}()
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+)
+
+var (
+	// configureList is set by --list to list the configurable addons and their settings instead of configuring one
+	configureList bool
+	// configureListOutput is the format of --list, list or json
+	configureListOutput string
+)
+
+// configurableAddonInfo describes an addon accepted by configure, as listed by --list
+type configurableAddonInfo struct {
+	Name string `json:"name"`
+	// Addon is false for the targets which are not addons, eg. namespace-defaults
+	Addon bool `json:"addon"`
+	// ChangesCluster is true if configuring the addon needs a running cluster, false if it only changes the profile
+	ChangesCluster bool `json:"changesCluster"`
+	// Settings are the keys accepted by --set, --edit and --file
+	Settings []configurableSettingInfo `json:"settings"`
+}
+
+// configurableSettingInfo describes a key accepted by --set for an addon
+type configurableSettingInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// configurableAddonsInfo returns the description of the configurable addons, sorted by name,
+// or only that of the given addon if it is not empty
+func configurableAddonsInfo(only string) ([]configurableAddonInfo, error) {
+	names := configurableAddonNames()
+	if only != "" {
+		if !containsString(names, only) {
+			return nil, fmt.Errorf("%q is not a configurable addon, expected one of %s", only, strings.Join(names, ", "))
+		}
+		names = []string{only}
+	}
+	infos := []configurableAddonInfo{}
+	for _, name := range names {
+		_, isAddon := assets.Addons[name]
+		info := configurableAddonInfo{
+			Name:           name,
+			Addon:          isAddon,
+			ChangesCluster: !profileOnlyAddons[name],
+			Settings:       []configurableSettingInfo{},
+		}
+		for _, k := range addonConfigKeyNames(name) {
+			key := addonConfigKeys[name][k]
+			info.Settings = append(info.Settings, configurableSettingInfo{Name: k, Type: key.kind, Description: key.description})
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// printConfigurableAddons prints the configurable addons and their settings, as a table or as JSON
+func printConfigurableAddons(only, output string) {
+	infos, err := configurableAddonsInfo(only)
+	if err != nil {
+		exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+	}
+	switch strings.ToLower(output) {
+	case "list":
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoFormatHeaders(true)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Addon", "Changes Cluster", "Setting", "Type", "Description"})
+		for _, info := range infos {
+			changesCluster := fmt.Sprintf("%t", info.ChangesCluster)
+			if len(info.Settings) == 0 {
+				table.Append([]string{info.Name, changesCluster, "", "", ""})
+				continue
+			}
+			for _, s := range info.Settings {
+				table.Append([]string{info.Name, changesCluster, s.Name, s.Type, s.Description})
+			}
+		}
+		table.Render()
+	case "json":
+		b, err := json.Marshal(infos)
+		if err != nil {
+			exit.Error(reason.InternalJSONMarshal, "Failed to marshal the configurable addons", err)
+		}
+		out.Ln("%s", string(b))
+	default:
+		exit.Message(reason.Usage, "invalid output format: {{.output}}. Valid values: 'list', 'json'", out.V{"output": output})
+	}
+}
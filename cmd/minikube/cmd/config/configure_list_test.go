@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+)
+
+func TestConfigurableAddonsInfo(t *testing.T) {
+	infos, err := configurableAddonsInfo("")
+	if err != nil {
+		t.Fatalf("configurableAddonsInfo returned error: %v", err)
+	}
+	if len(infos) != len(configurableAddonNames()) {
+		t.Errorf("expected %d addons, got %d", len(configurableAddonNames()), len(infos))
+	}
+	byName := map[string]configurableAddonInfo{}
+	for _, info := range infos {
+		byName[info.Name] = info
+	}
+	tests := []struct {
+		name           string
+		addon          bool
+		changesCluster bool
+		setting        string
+		kind           string
+	}{
+		{name: "metallb", addon: true, changesCluster: false, setting: "range", kind: "ip-range"},
+		{name: "metrics-server", addon: true, changesCluster: false, setting: "cpu-limit", kind: "quantity"},
+		{name: "registry-creds", addon: true, changesCluster: true, setting: "refresh-interval", kind: "duration"},
+		{name: namespaceDefaultsTarget, addon: false, changesCluster: true},
+	}
+	for _, tc := range tests {
+		info, ok := byName[tc.name]
+		if !ok {
+			t.Errorf("expected %s to be listed", tc.name)
+			continue
+		}
+		if info.Addon != tc.addon || info.ChangesCluster != tc.changesCluster {
+			t.Errorf("%s: got addon=%t changesCluster=%t, want addon=%t changesCluster=%t", tc.name, info.Addon, info.ChangesCluster, tc.addon, tc.changesCluster)
+		}
+		if tc.setting == "" {
+			if len(info.Settings) != 0 {
+				t.Errorf("%s: expected no settings, got %v", tc.name, info.Settings)
+			}
+			continue
+		}
+		found := false
+		for _, s := range info.Settings {
+			if s.Name == tc.setting {
+				found = true
+				if s.Type != tc.kind {
+					t.Errorf("%s: %s has type %q, want %q", tc.name, s.Name, s.Type, tc.kind)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected setting %s, got %v", tc.name, tc.setting, info.Settings)
+		}
+	}
+}
+
+func TestConfigurableAddonsInfoOnly(t *testing.T) {
+	infos, err := configurableAddonsInfo("metallb")
+	if err != nil {
+		t.Fatalf("configurableAddonsInfo returned error: %v", err)
+	}
+	if len(infos) != 1 || infos[0].Name != "metallb" {
+		t.Errorf("expected only metallb, got %v", infos)
+	}
+	if _, err := configurableAddonsInfo("registry"); err == nil {
+		t.Errorf("expected an error for an addon which is not configurable")
+	}
+}
+
+// every --set key must be described, --list is what tools build their forms from
+func TestAddonConfigKeysDescribed(t *testing.T) {
+	names := configurableAddonNames()
+	for addon, keys := range addonConfigKeys {
+		if !containsString(names, addon) {
+			t.Errorf("%s has --set keys but is not configurable", addon)
+		}
+		for k, key := range keys {
+			if key.kind == "" || key.description == "" {
+				t.Errorf("%s: %s has no type or description", addon, k)
+			}
+		}
+	}
+}
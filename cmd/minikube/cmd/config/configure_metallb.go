@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/network"
+)
+
+// nodeSubnet returns the subnet of the cluster nodes: the --subnet of the profile if set,
+// otherwise the /24 of the primary node, which is the size of the networks created by minikube.
+// It returns nil if the node IP is not known yet.
+func nodeSubnet(cc *config.ClusterConfig) *net.IPNet {
+	if cc.Subnet != "" {
+		ip, subnet, err := network.ParseAddr(cc.Subnet)
+		if err != nil {
+			return nil
+		}
+		if subnet == nil {
+			subnet = &net.IPNet{IP: ip.Mask(ip.DefaultMask()), Mask: ip.DefaultMask()}
+		}
+		return subnet
+	}
+	if len(cc.Nodes) == 0 {
+		return nil
+	}
+	ip := net.ParseIP(cc.Nodes[0].IP).To4()
+	if ip == nil {
+		return nil
+	}
+	mask := net.CIDRMask(24, 32)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// checkLoadBalancerRange checks the load balancer range against the network of the cluster.
+// It returns an error if the range includes a node IP or the gateway of the node subnet, as MetalLB
+// would answer ARP requests for them, and the node subnet if the range overlaps it.
+func checkLoadBalancerRange(startIP, endIP string, cc *config.ClusterConfig) (*net.IPNet, error) {
+	start, end := net.ParseIP(startIP).To16(), net.ParseIP(endIP).To16()
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("invalid range %s-%s", startIP, endIP)
+	}
+	if bytes.Compare(start, end) > 0 {
+		return nil, fmt.Errorf("start IP %s is after end IP %s", startIP, endIP)
+	}
+	inRange := func(ip net.IP) bool {
+		ip = ip.To16()
+		return ip != nil && bytes.Compare(ip, start) >= 0 && bytes.Compare(ip, end) <= 0
+	}
+
+	for _, n := range cc.Nodes {
+		if inRange(net.ParseIP(n.IP)) {
+			return nil, fmt.Errorf("range %s-%s includes the IP %s of node %q", startIP, endIP, n.IP, config.MachineName(*cc, n))
+		}
+	}
+
+	subnet := nodeSubnet(cc)
+	if subnet == nil {
+		return nil, nil
+	}
+	gateway := make(net.IP, len(subnet.IP))
+	copy(gateway, subnet.IP)
+	gateway[len(gateway)-1]++
+	if inRange(gateway) {
+		return nil, fmt.Errorf("range %s-%s includes the gateway %s of the node subnet %s", startIP, endIP, gateway, subnet)
+	}
+	if subnet.Contains(start) || subnet.Contains(end) || inRange(subnet.IP) {
+		return subnet, nil
+	}
+	return nil, nil
+}
+
+// metallbConfigurator sets the load balancer range of metallb, how its addresses are advertised and the resources of its pods
+type metallbConfigurator struct {
+	cfg     *config.ClusterConfig
+	metallb config.MetalLBConfig
+}
+
+// Validate prompts for the range, the mode and the resources, checking the range against the network of the cluster
+func (m *metallbConfigurator) Validate(profile string) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	m.cfg = cfg
+
+	validator := func(s string) bool {
+		return net.ParseIP(s) != nil
+	}
+	startIP := AskForStaticValidatedValue("-- Enter Load Balancer Start IP: ", validator)
+	endIP := AskForStaticValidatedValue("-- Enter Load Balancer End IP: ", validator)
+
+	subnet, err := checkLoadBalancerRange(startIP, endIP, cfg)
+	if err != nil {
+		if !addons.Force {
+			return fmt.Errorf("invalid load balancer range: %w, use --force to configure it anyway", err)
+		}
+		out.WarningT("Invalid load balancer range: {{.error}}", out.V{"error": err})
+	}
+	if subnet != nil {
+		out.WarningT("The load balancer range overlaps the {{.subnet}} node subnet, make sure no other host on that network uses these addresses", out.V{"subnet": subnet})
+	}
+
+	m.metallb = config.DefaultMetalLBConfig(startIP, endIP)
+	// layer2 is kept as the empty mode, so profiles configured before BGP support are unchanged
+	if AskForChoice("-- How should MetalLB advertise the addresses? ", metallbModes) == config.MetalLBModeBGP {
+		m.metallb.Mode = config.MetalLBModeBGP
+		m.metallb.Peers = askForMetalLBPeers()
+	}
+	// the resources are kept unless they are set again
+	m.metallb.Controller, m.metallb.Speaker = cfg.MetalLB.Controller, cfg.MetalLB.Speaker
+	if AskForYesNoConfirmation("\nDo you want to set the CPU and memory of the MetalLB controller and speaker?", posResponses, negResponses) {
+		m.metallb.Controller = askForMetalLBResources("controller")
+		m.metallb.Speaker = askForMetalLBResources("speaker")
+	}
+	return nil
+}
+
+// Apply saves the metallb config and re-enables the addon with it
+func (m *metallbConfigurator) Apply(_ context.Context, profile string) (applyResult, error) {
+	changed, err := applyMetalLBConfig(profile, m.cfg, m.metallb)
+	return applyResult{unchanged: !changed}, err
+}
+
+// applyMetalLBConfig saves the metallb config and re-enables the addon to generate its manifests, returning whether anything changed.
+// Re-enabling restarts the metallb pods, so nothing is done when the enabled addon already has this config.
+func applyMetalLBConfig(profile string, cfg *config.ClusterConfig, metallb config.MetalLBConfig) (bool, error) {
+	if assets.Addons["metallb"].IsEnabled(cfg) && reflect.DeepEqual(metallb, cfg.MetalLB) {
+		out.Styled(style.Check, "No changes to the metallb configuration of {{.profile}}", out.V{"profile": profile})
+		return false, nil
+	}
+
+	cfg.MetalLB = metallb
+
+	// Re-enable metallb addon in order to generate template manifest files with Load Balancer Start/End IP
+	if err := saveAndReenableAddon(profile, cfg, "metallb", true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// metallbModes are the ways metallb can advertise the addresses of the pools
+var metallbModes = []string{config.MetalLBModeL2, config.MetalLBModeBGP}
+
+// parseASN parses a BGP autonomous system number
+func parseASN(s string) (uint32, error) {
+	asn, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || asn == 0 {
+		return 0, fmt.Errorf("invalid ASN %q, must be between 1 and 4294967295", s)
+	}
+	return uint32(asn), nil
+}
+
+func validateASN(s string) error {
+	_, err := parseASN(s)
+	return err
+}
+
+func validatePeerAddress(s string) error {
+	if net.ParseIP(s) == nil {
+		return fmt.Errorf("invalid IP address %q", s)
+	}
+	return nil
+}
+
+// askForMetalLBPeers asks for the BGP routers metallb advertises the addresses to, at least one
+func askForMetalLBPeers() []config.MetalLBPeer {
+	var peers []config.MetalLBPeer
+	for {
+		address := AskForStaticCheckedValue("-- Enter the IP address of the BGP peer: ", validatePeerAddress)
+		peerASN, _ := parseASN(AskForStaticCheckedValue("-- Enter the ASN of the BGP peer: ", validateASN))
+		myASN, _ := parseASN(AskForStaticCheckedValue("-- Enter the ASN MetalLB uses to peer with it: ", validateASN))
+		peers = append(peers, config.MetalLBPeer{Address: address, ASN: peerASN, MyASN: myASN})
+		if !AskForYesNoConfirmation("-- Do you want to add another BGP peer?", posResponses, negResponses) {
+			return peers
+		}
+	}
+}
+
+// askForMetalLBResources asks for the CPU and memory of a metallb container, each limit being at least its request
+func askForMetalLBResources(component string) config.MetalLBResources {
+	quantity := func(s string) bool {
+		return IsValidQuantity("", s) == nil
+	}
+	var r config.MetalLBResources
+	r.CPURequest = AskForStaticValidatedValue("-- Enter the CPU request of the "+component+" (ex. 50m): ", quantity)
+	r.MemoryRequest = AskForStaticValidatedValue("-- Enter the memory request of the "+component+" (ex. 50Mi): ", quantity)
+	r.CPULimit = AskForStaticValidatedValue("-- Enter the CPU limit of the "+component+" (at least the CPU request): ", isQuantityAtLeast(r.CPURequest))
+	r.MemoryLimit = AskForStaticValidatedValue("-- Enter the memory limit of the "+component+" (at least the memory request): ", isQuantityAtLeast(r.MemoryRequest))
+	return r
+}
+
+// metallbResourceKey is the --set key of a resource of a metallb container, field returns the setting on the profile
+func metallbResourceKey(description string, field func(cc *config.ClusterConfig) *string) addonConfigKey {
+	return addonConfigKey{
+		kind:        "quantity",
+		description: description,
+		validate:    func(v string) error { return IsValidQuantity("", v) },
+		set:         func(cc *config.ClusterConfig, v string) { *field(cc) = v },
+		get:         func(cc *config.ClusterConfig) string { return *field(cc) },
+	}
+}
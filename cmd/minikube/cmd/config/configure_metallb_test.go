@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestCheckLoadBalancerRange(t *testing.T) {
+	cc := &config.ClusterConfig{
+		Name:  "minikube",
+		Nodes: []config.Node{{Name: "", IP: "192.168.49.2", ControlPlane: true}, {Name: "m02", IP: "192.168.49.3"}},
+	}
+	tests := []struct {
+		start, end string
+		subnet     string
+		err        bool
+	}{
+		{start: "192.168.49.100", end: "192.168.49.120", subnet: "192.168.49.0/24"},
+		{start: "10.0.0.10", end: "10.0.0.20"},
+		{start: "192.168.49.3", end: "192.168.49.10", err: true},
+		{start: "192.168.49.1", end: "192.168.49.1", err: true},
+		{start: "192.168.48.200", end: "192.168.50.10", err: true},
+		{start: "192.168.49.120", end: "192.168.49.100", err: true},
+	}
+	for _, tc := range tests {
+		subnet, err := checkLoadBalancerRange(tc.start, tc.end, cc)
+		if (err != nil) != tc.err {
+			t.Errorf("checkLoadBalancerRange(%s, %s) error = %v, want error %t", tc.start, tc.end, err, tc.err)
+			continue
+		}
+		got := ""
+		if subnet != nil {
+			got = subnet.String()
+		}
+		if got != tc.subnet {
+			t.Errorf("checkLoadBalancerRange(%s, %s) subnet = %q, want %q", tc.start, tc.end, got, tc.subnet)
+		}
+	}
+
+	cc.Subnet = "10.0.0.0/16"
+	if subnet, err := checkLoadBalancerRange("10.0.5.1", "10.0.5.9", cc); err != nil || subnet == nil || subnet.String() != "10.0.0.0/16" {
+		t.Errorf("expected the range to overlap the 10.0.0.0/16 subnet, got %v and error %v", subnet, err)
+	}
+}
+
+func TestParseASN(t *testing.T) {
+	tests := []struct {
+		input string
+		want  uint32
+		err   bool
+	}{
+		{input: "64512", want: 64512},
+		{input: "4294967295", want: 4294967295},
+		{input: "0", err: true},
+		{input: "4294967296", err: true},
+		{input: "-1", err: true},
+		{input: "AS64512", err: true},
+	}
+	for _, tc := range tests {
+		got, err := parseASN(tc.input)
+		if (err != nil) != tc.err || got != tc.want {
+			t.Errorf("parseASN(%q) = %d, %v, want %d and error %t", tc.input, got, err, tc.want, tc.err)
+		}
+	}
+}
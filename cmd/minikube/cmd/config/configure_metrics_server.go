@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// metricsServerConfigurator sets the resources of metrics-server and how often it scrapes the metrics
+type metricsServerConfigurator struct {
+	cfg           *config.ClusterConfig
+	metricsServer config.MetricsServerConfig
+}
+
+// Validate prompts for the requests, the optional limits and the scrape interval
+func (m *metricsServerConfigurator) Validate(profile string) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	m.cfg = cfg
+
+	requestValidator := func(s string) bool {
+		return IsValidQuantity("", s) == nil
+	}
+	// metrics-server refuses to start with a metric resolution below 10s
+	resolutionValidator := func(s string) bool {
+		d, err := time.ParseDuration(s)
+		return err == nil && d >= 10*time.Second
+	}
+
+	ms := config.MetricsServerConfig{}
+	ms.CPURequest = AskForStaticValidatedValue("-- Enter CPU request (ex. 100m): ", requestValidator)
+	ms.MemoryRequest = AskForStaticValidatedValue("-- Enter memory request (ex. 200Mi): ", requestValidator)
+	if AskForYesNoConfirmation("\nDo you want to set CPU and memory limits?", posResponses, negResponses) {
+		ms.CPULimit = AskForStaticValidatedValue("-- Enter CPU limit (at least the CPU request): ", isQuantityAtLeast(ms.CPURequest))
+		ms.MemoryLimit = AskForStaticValidatedValue("-- Enter memory limit (at least the memory request): ", isQuantityAtLeast(ms.MemoryRequest))
+	}
+	resolution := AskForStaticValidatedValue("-- Enter scrape interval of metrics-server (at least 10s, ex. 60s): ", resolutionValidator)
+	ms.MetricResolution, _ = time.ParseDuration(resolution)
+	m.metricsServer = ms
+	return nil
+}
+
+// Apply saves the settings and re-enables metrics-server to generate its manifests with them
+func (m *metricsServerConfigurator) Apply(_ context.Context, profile string) (applyResult, error) {
+	m.cfg.MetricsServer = m.metricsServer
+	if err := saveAndReenableAddon(profile, m.cfg, "metrics-server", false); err != nil {
+		return applyResult{}, err
+	}
+	return applyResult{}, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// namespaceDefaultsTarget is given to addons configure instead of an addon name to set the default resources
+// of the containers of a namespace, eg. for lab clusters. It is not an addon, nothing is saved in the profile.
+const namespaceDefaultsTarget = "namespace-defaults"
+
+// the names of the objects created in the namespace by the namespace-defaults flow
+const (
+	namespaceDefaultsLimitRange    = "minikube-defaults"
+	namespaceDefaultsResourceQuota = "minikube-quota"
+)
+
+// namespaceDefaults holds the default resources of the containers of a namespace, and its optional quota
+type namespaceDefaults struct {
+	namespace     string
+	cpuRequest    string
+	memoryRequest string
+	cpuLimit      string
+	memoryLimit   string
+	// quotaCPU and quotaMemory are the total limits of the namespace, no quota is created if they are empty
+	quotaCPU    string
+	quotaMemory string
+}
+
+// isQuantityAtLeast returns a validator of the quantities which are not lower than minimum, eg. a limit and its request
+func isQuantityAtLeast(minimum string) func(s string) bool {
+	return func(s string) bool {
+		q, err := resource.ParseQuantity(s)
+		return err == nil && q.Cmp(resource.MustParse(minimum)) >= 0
+	}
+}
+
+// validateNamespaceName checks if s is a valid namespace name
+func validateNamespaceName(s string) error {
+	if errs := validation.IsDNS1123Label(s); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", s, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validate checks the quantities: each limit must not be lower than its request, nor the quota than the limits
+func (d namespaceDefaults) validate() error {
+	if err := validateNamespaceName(d.namespace); err != nil {
+		return err
+	}
+	// each quantity is checked against its minimum, eg. a limit against its request
+	type quantity struct {
+		name  string
+		value string
+		min   string
+	}
+	quantities := []quantity{
+		{"CPU request", d.cpuRequest, "0"},
+		{"memory request", d.memoryRequest, "0"},
+		{"CPU limit", d.cpuLimit, d.cpuRequest},
+		{"memory limit", d.memoryLimit, d.memoryRequest},
+	}
+	if d.quotaCPU != "" || d.quotaMemory != "" {
+		quantities = append(quantities, quantity{"quota CPU", d.quotaCPU, d.cpuLimit}, quantity{"quota memory", d.quotaMemory, d.memoryLimit})
+	}
+	for _, q := range quantities {
+		if IsValidQuantity("", q.value) != nil {
+			return fmt.Errorf("invalid %s %q", q.name, q.value)
+		}
+		if !isQuantityAtLeast(q.min)(q.value) {
+			return fmt.Errorf("the %s %s is lower than %s", q.name, q.value, q.min)
+		}
+	}
+	return nil
+}
+
+// limitRange returns the spec of the LimitRange setting the default requests and limits of the containers
+func (d namespaceDefaults) limitRange() core.LimitRangeSpec {
+	return core.LimitRangeSpec{Limits: []core.LimitRangeItem{{
+		Type: core.LimitTypeContainer,
+		DefaultRequest: core.ResourceList{
+			core.ResourceCPU:    resource.MustParse(d.cpuRequest),
+			core.ResourceMemory: resource.MustParse(d.memoryRequest),
+		},
+		Default: core.ResourceList{
+			core.ResourceCPU:    resource.MustParse(d.cpuLimit),
+			core.ResourceMemory: resource.MustParse(d.memoryLimit),
+		},
+	}}}
+}
+
+// resourceQuota returns the spec of the ResourceQuota bounding the total limits of the containers of the namespace
+func (d namespaceDefaults) resourceQuota() core.ResourceQuotaSpec {
+	return core.ResourceQuotaSpec{Hard: core.ResourceList{
+		core.ResourceLimitsCPU:    resource.MustParse(d.quotaCPU),
+		core.ResourceLimitsMemory: resource.MustParse(d.quotaMemory),
+	}}
+}
+
+// namespaceDefaultsConfigurator creates the LimitRange and the ResourceQuota of a namespace once all the quantities are valid
+type namespaceDefaultsConfigurator struct {
+	defaults namespaceDefaults
+}
+
+// Validate prompts for the namespace and its default resources
+func (n *namespaceDefaultsConfigurator) Validate(_ string) error {
+	isQuantity := func(s string) bool {
+		return IsValidQuantity("", s) == nil
+	}
+	d := namespaceDefaults{}
+	d.namespace = AskForStaticCheckedValue("-- Enter the namespace to set the default resources of (ex. lab): ", validateNamespaceName)
+	d.cpuRequest = AskForStaticValidatedValue("-- Enter the default CPU request of the containers (ex. 100m): ", isQuantity)
+	d.memoryRequest = AskForStaticValidatedValue("-- Enter the default memory request of the containers (ex. 128Mi): ", isQuantity)
+	d.cpuLimit = AskForStaticValidatedValue("-- Enter the default CPU limit of the containers (at least the CPU request): ", isQuantityAtLeast(d.cpuRequest))
+	d.memoryLimit = AskForStaticValidatedValue("-- Enter the default memory limit of the containers (at least the memory request): ", isQuantityAtLeast(d.memoryRequest))
+	if AskForYesNoConfirmation("\nDo you want to limit the total CPU and memory of the namespace with a ResourceQuota?", posResponses, negResponses) {
+		d.quotaCPU = AskForStaticValidatedValue("-- Enter the total CPU limit of the namespace (at least the CPU limit): ", isQuantityAtLeast(d.cpuLimit))
+		d.quotaMemory = AskForStaticValidatedValue("-- Enter the total memory limit of the namespace (at least the memory limit): ", isQuantityAtLeast(d.memoryLimit))
+	}
+	n.defaults = d
+	return d.validate()
+}
+
+// Apply creates the namespace if needed, then creates or updates its LimitRange and ResourceQuota
+func (n *namespaceDefaultsConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	d := n.defaults
+	if err := configureClient.EnsureNamespace(ctx, profile, d.namespace); err != nil {
+		return applyResult{}, fmt.Errorf("create namespace %s: %w", d.namespace, err)
+	}
+	if err := configureClient.ApplyLimitRange(ctx, profile, d.namespace, namespaceDefaultsLimitRange, d.limitRange()); err != nil {
+		return applyResult{}, fmt.Errorf("apply the %s LimitRange: %w", namespaceDefaultsLimitRange, err)
+	}
+	recordApplied("set the default resources of the " + d.namespace + " namespace in the " + namespaceDefaultsLimitRange + " LimitRange")
+	if d.quotaCPU == "" {
+		return applyResult{}, nil
+	}
+	if err := configureClient.ApplyResourceQuota(ctx, profile, d.namespace, namespaceDefaultsResourceQuota, d.resourceQuota()); err != nil {
+		return applyResult{}, fmt.Errorf("apply the %s ResourceQuota: %w", namespaceDefaultsResourceQuota, err)
+	}
+	recordApplied("limited the total resources of the " + d.namespace + " namespace in the " + namespaceDefaultsResourceQuota + " ResourceQuota")
+	return applyResult{}, nil
+}
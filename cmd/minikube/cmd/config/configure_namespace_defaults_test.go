@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+)
+
+func TestNamespaceDefaultsValidate(t *testing.T) {
+	valid := namespaceDefaults{namespace: "lab", cpuRequest: "100m", memoryRequest: "128Mi", cpuLimit: "500m", memoryLimit: "512Mi"}
+	tests := []struct {
+		description string
+		change      func(d *namespaceDefaults)
+		wantErr     bool
+	}{
+		{description: "valid", change: func(_ *namespaceDefaults) {}},
+		{description: "valid with quota", change: func(d *namespaceDefaults) { d.quotaCPU, d.quotaMemory = "2", "2Gi" }},
+		{description: "invalid namespace", change: func(d *namespaceDefaults) { d.namespace = "Lab_1" }, wantErr: true},
+		{description: "invalid request", change: func(d *namespaceDefaults) { d.cpuRequest = "lots" }, wantErr: true},
+		{description: "negative request", change: func(d *namespaceDefaults) { d.memoryRequest = "-1Mi" }, wantErr: true},
+		{description: "limit lower than request", change: func(d *namespaceDefaults) { d.cpuLimit = "50m" }, wantErr: true},
+		{description: "quota lower than limit", change: func(d *namespaceDefaults) { d.quotaCPU, d.quotaMemory = "2", "256Mi" }, wantErr: true},
+		{description: "quota missing memory", change: func(d *namespaceDefaults) { d.quotaCPU = "2" }, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			d := valid
+			tc.change(&d)
+			if err := d.validate(); (err != nil) != tc.wantErr {
+				t.Errorf("validate() expected error %t but got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	spec := valid.limitRange()
+	if q := spec.Limits[0].Default[core.ResourceMemory]; q.String() != "512Mi" {
+		t.Errorf("default memory limit = %s, want 512Mi", q.String())
+	}
+}
+
+func TestNamespaceDefaultsApply(t *testing.T) {
+	tests := []struct {
+		description string
+		quota       bool
+		err         error
+		calls       []string
+		wantErr     bool
+	}{
+		{
+			description: "limit range only",
+			calls:       []string{"EnsureNamespace lab", "ApplyLimitRange lab/minikube-defaults"},
+		},
+		{
+			description: "limit range and quota",
+			quota:       true,
+			calls:       []string{"EnsureNamespace lab", "ApplyLimitRange lab/minikube-defaults", "ApplyResourceQuota lab/minikube-quota"},
+		},
+		{
+			description: "namespace not created",
+			err:         fmt.Errorf("unreachable"),
+			calls:       []string{"EnsureNamespace lab"},
+			wantErr:     true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			f := useFakeClusterClient(t)
+			f.err = tc.err
+
+			d := namespaceDefaults{namespace: "lab", cpuRequest: "100m", memoryRequest: "128Mi", cpuLimit: "500m", memoryLimit: "512Mi"}
+			if tc.quota {
+				d.quotaCPU, d.quotaMemory = "2", "2Gi"
+			}
+			n := &namespaceDefaultsConfigurator{defaults: d}
+			if _, err := n.Apply(context.Background(), "minikube"); (err != nil) != tc.wantErr {
+				t.Errorf("Apply() expected error %t but got %v", tc.wantErr, err)
+			}
+			if !reflect.DeepEqual(f.calls, tc.calls) {
+				t.Errorf("unexpected cluster operations %v, want %v", f.calls, tc.calls)
+			}
+		})
+	}
+}
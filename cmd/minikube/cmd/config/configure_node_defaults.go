@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+// nodeDefaultsTarget is given to addons configure instead of an addon name to set labels and taints on the nodes,
+// eg. to simulate the node pools of a production cluster. It is not an addon, nothing is saved in the profile.
+const nodeDefaultsTarget = "node-defaults"
+
+// taintEffects are the effects a node taint can have
+var taintEffects = []core.TaintEffect{core.TaintEffectNoSchedule, core.TaintEffectPreferNoSchedule, core.TaintEffectNoExecute}
+
+// nodeDefaults holds the nodes to change, all of them if empty, and their labels and taints
+type nodeDefaults struct {
+	nodes   []string
+	changes service.NodeChanges
+}
+
+// validateLabelKey checks the key of a label or a taint, eg. "pool" or "example.com/pool"
+func validateLabelKey(key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid key %q: %s", key, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// validateLabelValue checks the value of a label or a taint, which may be empty
+func validateLabelValue(value string) error {
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid value %q: %s", value, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// parseNodeLabel parses a label as key=value, or key- to remove it, like kubectl label
+func parseNodeLabel(s string) (key, value string, remove bool, err error) {
+	s = strings.TrimSpace(s)
+	key, value, found := strings.Cut(s, "=")
+	if !found {
+		if !strings.HasSuffix(s, "-") {
+			return "", "", false, fmt.Errorf("expected key=value, or key- to remove the label, got %q", s)
+		}
+		key, remove = strings.TrimSuffix(s, "-"), true
+	}
+	if err := validateLabelKey(key); err != nil {
+		return "", "", false, err
+	}
+	if err := validateLabelValue(value); err != nil {
+		return "", "", false, err
+	}
+	return key, value, remove, nil
+}
+
+// parseNodeTaint parses a taint as key=value:Effect or key:Effect, or key:Effect- and key- to remove it, like kubectl taint
+func parseNodeTaint(s string) (taint core.Taint, remove bool, err error) {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, "-") {
+		s, remove = strings.TrimSuffix(s, "-"), true
+	}
+	keyValue, effect, found := strings.Cut(s, ":")
+	if !found && !remove {
+		return taint, false, fmt.Errorf("expected key=value:Effect or key:Effect, got %q", s)
+	}
+	key, value, _ := strings.Cut(keyValue, "=")
+	if err := validateLabelKey(key); err != nil {
+		return taint, false, err
+	}
+	if err := validateLabelValue(value); err != nil {
+		return taint, false, err
+	}
+	taint = core.Taint{Key: key, Value: value, Effect: core.TaintEffect(effect)}
+	if effect == "" && remove {
+		return taint, true, nil
+	}
+	for _, e := range taintEffects {
+		if taint.Effect == e {
+			return taint, remove, nil
+		}
+	}
+	return taint, false, fmt.Errorf("invalid taint effect %q, expected one of NoSchedule, PreferNoSchedule, NoExecute", effect)
+}
+
+// validate checks the node names, and that a label or a taint is not both set and removed
+func (d nodeDefaults) validate() error {
+	for _, name := range d.nodes {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("invalid node name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+	c := d.changes
+	if len(c.Labels) == 0 && len(c.RemoveLabels) == 0 && len(c.Taints) == 0 && len(c.RemoveTaints) == 0 {
+		return fmt.Errorf("no labels nor taints given")
+	}
+	for _, key := range c.RemoveLabels {
+		if _, ok := c.Labels[key]; ok {
+			return fmt.Errorf("label %q is both set and removed", key)
+		}
+	}
+	for _, r := range c.RemoveTaints {
+		for _, t := range c.Taints {
+			if r.Key == t.Key && (r.Effect == "" || r.Effect == t.Effect) {
+				return fmt.Errorf("taint %q is both set and removed", t.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// nodeDefaultsConfigurator sets the labels and taints of the nodes once all of them are valid
+type nodeDefaultsConfigurator struct {
+	defaults nodeDefaults
+}
+
+// Validate prompts for the nodes, and the labels and taints to set or remove
+func (n *nodeDefaultsConfigurator) Validate(_ string) error {
+	d := nodeDefaults{changes: service.NodeChanges{Labels: map[string]string{}}}
+	d.nodes = strings.Fields(AskForStaticValueOptional("-- Enter the nodes to change separated by spaces, or leave empty for all the nodes: "))
+	prompt := "\nDo you want to set or remove a node label?"
+	for AskForYesNoConfirmation(prompt, posResponses, negResponses) {
+		label := AskForStaticCheckedValue("-- Enter the label as key=value, or key- to remove it (ex. pool=gpu): ", func(s string) error {
+			_, _, _, err := parseNodeLabel(s)
+			return err
+		})
+		key, value, remove, _ := parseNodeLabel(label)
+		if remove {
+			d.changes.RemoveLabels = append(d.changes.RemoveLabels, key)
+		} else {
+			d.changes.Labels[key] = value
+		}
+		prompt = "-- Do you want to set or remove another label?"
+	}
+	prompt = "\nDo you want to set or remove a node taint?"
+	for AskForYesNoConfirmation(prompt, posResponses, negResponses) {
+		taint := AskForStaticCheckedValue("-- Enter the taint as key=value:Effect, or key:Effect- to remove it (ex. gpu=true:NoSchedule): ", func(s string) error {
+			_, _, err := parseNodeTaint(s)
+			return err
+		})
+		t, remove, _ := parseNodeTaint(taint)
+		if remove {
+			d.changes.RemoveTaints = append(d.changes.RemoveTaints, t)
+		} else {
+			d.changes.Taints = append(d.changes.Taints, t)
+		}
+		prompt = "-- Do you want to set or remove another taint?"
+	}
+	n.defaults = d
+	return d.validate()
+}
+
+// Apply updates the labels and taints of the nodes
+func (n *nodeDefaultsConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	updated, err := configureClient.UpdateNodes(ctx, profile, n.defaults.nodes, n.defaults.changes)
+	for _, name := range updated {
+		recordApplied("updated the labels and taints of the " + name + " node")
+	}
+	if err != nil {
+		return applyResult{}, fmt.Errorf("update the nodes: %w", err)
+	}
+	return applyResult{}, nil
+}
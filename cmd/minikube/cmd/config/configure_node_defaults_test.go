@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/minikube/pkg/minikube/service"
+)
+
+func TestParseNodeLabel(t *testing.T) {
+	tests := []struct {
+		label  string
+		key    string
+		value  string
+		remove bool
+		err    bool
+	}{
+		{label: "pool=gpu", key: "pool", value: "gpu"},
+		{label: "example.com/pool=", key: "example.com/pool"},
+		{label: "pool-", key: "pool", remove: true},
+		{label: "pool", err: true},
+		{label: "pool=gpu nodes", err: true},
+		{label: "-pool=gpu", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.label, func(t *testing.T) {
+			key, value, remove, err := parseNodeLabel(tc.label)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if !tc.err && (key != tc.key || value != tc.value || remove != tc.remove) {
+				t.Errorf("parseNodeLabel(%q) = %q, %q, %t, want %q, %q, %t", tc.label, key, value, remove, tc.key, tc.value, tc.remove)
+			}
+		})
+	}
+}
+
+func TestParseNodeTaint(t *testing.T) {
+	tests := []struct {
+		taint  string
+		want   core.Taint
+		remove bool
+		err    bool
+	}{
+		{taint: "gpu=true:NoSchedule", want: core.Taint{Key: "gpu", Value: "true", Effect: core.TaintEffectNoSchedule}},
+		{taint: "spot:NoExecute", want: core.Taint{Key: "spot", Effect: core.TaintEffectNoExecute}},
+		{taint: "spot:NoExecute-", want: core.Taint{Key: "spot", Effect: core.TaintEffectNoExecute}, remove: true},
+		{taint: "spot-", want: core.Taint{Key: "spot"}, remove: true},
+		{taint: "gpu=true", err: true},
+		{taint: "gpu=true:NoRun", err: true},
+		{taint: "gpu=true:", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.taint, func(t *testing.T) {
+			got, remove, err := parseNodeTaint(tc.taint)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if !tc.err && (!reflect.DeepEqual(got, tc.want) || remove != tc.remove) {
+				t.Errorf("parseNodeTaint(%q) = %v, %t, want %v, %t", tc.taint, got, remove, tc.want, tc.remove)
+			}
+		})
+	}
+}
+
+func TestNodeDefaultsValidate(t *testing.T) {
+	gpu := core.Taint{Key: "gpu", Value: "true", Effect: core.TaintEffectNoSchedule}
+	tests := []struct {
+		description string
+		defaults    nodeDefaults
+		err         bool
+	}{
+		{description: "labels and taints", defaults: nodeDefaults{nodes: []string{"minikube-m02"}, changes: service.NodeChanges{Labels: map[string]string{"pool": "gpu"}, Taints: []core.Taint{gpu}}}},
+		{description: "no changes", defaults: nodeDefaults{}, err: true},
+		{description: "invalid node", defaults: nodeDefaults{nodes: []string{"Node_1"}, changes: service.NodeChanges{RemoveLabels: []string{"pool"}}}, err: true},
+		{description: "label set and removed", defaults: nodeDefaults{changes: service.NodeChanges{Labels: map[string]string{"pool": "gpu"}, RemoveLabels: []string{"pool"}}}, err: true},
+		{description: "taint set and removed", defaults: nodeDefaults{changes: service.NodeChanges{Taints: []core.Taint{gpu}, RemoveTaints: []core.Taint{{Key: "gpu"}}}}, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if err := tc.defaults.validate(); (err != nil) != tc.err {
+				t.Errorf("expected error %t but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestNodeDefaultsApply(t *testing.T) {
+	f := useFakeClusterClient(t)
+	c := &nodeDefaultsConfigurator{defaults: nodeDefaults{
+		nodes:   []string{"minikube", "minikube-m02"},
+		changes: service.NodeChanges{Labels: map[string]string{"pool": "gpu"}},
+	}}
+	if _, err := c.Apply(context.Background(), "node-defaults"); err != nil {
+		t.Fatalf("Apply() returned unexpected error: %v", err)
+	}
+	if want := []string{"UpdateNodes minikube,minikube-m02"}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("unexpected cluster operations %v, want %v", f.calls, want)
+	}
+	want := []string{"updated the labels and taints of the minikube node", "updated the labels and taints of the minikube-m02 node"}
+	if !reflect.DeepEqual(configureProgress.applied, want) {
+		t.Errorf("applied changes %v, want %v", configureProgress.applied, want)
+	}
+}
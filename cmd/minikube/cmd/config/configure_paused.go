@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// ensureNotPaused unpauses the cluster, once confirmed, or exits when its control plane is paused,
+// as the cluster operations of configure would otherwise wait for the apiserver until they time out
+func ensureNotPaused(profile string) {
+	paused, err := addons.IsPaused(profile)
+	if err != nil {
+		klog.Warningf("unable to check whether the cluster is paused: %v", err)
+		return
+	}
+	if !paused {
+		return
+	}
+	out.Styled(style.Shrug, `The control plane for "{{.name}}" is paused!`, out.V{"name": profile})
+	if !AskForYesNoConfirmation("Do you want to unpause the cluster to configure the addon?", posResponses, negResponses) {
+		exit.Message(reason.InternalAddonConfigure, "Unpause the cluster before configuring the addon: minikube -p {{.profile}} unpause", out.V{"profile": profile})
+	}
+	unpauseCluster(profile)
+}
+
+// unpauseCluster unpauses all the namespaces of every node of the cluster, like `minikube unpause -A`
+func unpauseCluster(profile string) {
+	co := mustload.Running(profile)
+	for _, n := range co.Config.Nodes {
+		host, err := machine.LoadHost(co.API, config.MachineName(*co.Config, n))
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(host)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: co.Config.KubernetesConfig.ContainerRuntime, Runner: r})
+		if err != nil {
+			exit.Error(reason.InternalNewRuntime, "Failed runtime", err)
+		}
+		if _, err := cluster.Unpause(cr, r, nil); err != nil {
+			exit.Error(reason.GuestUnpause, "Failed to unpause the cluster", err)
+		}
+	}
+	recordApplied("unpaused " + profile)
+	out.Styled(style.Unpause, "Unpaused {{.profile}}", out.V{"profile": profile})
+}
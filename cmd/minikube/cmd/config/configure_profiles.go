@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	// configureProfiles is set by --profiles to apply the same --set or --file configuration to each of the profiles
+	configureProfiles []string
+	// configureAllProfiles is set by --all-profiles to apply it to every valid profile
+	configureAllProfiles bool
+)
+
+// profilePaused returns whether the cluster of the profile is paused, it is replaced by the tests
+var profilePaused = addons.IsPaused
+
+// profileResult is the outcome of the configuration of one of the profiles of --profiles or --all-profiles
+type profileResult struct {
+	Profile string   `json:"profile"`
+	Changes []string `json:"changes"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// profileResults are the outcomes of the profiles configured so far, printed with the result of --quiet
+var profileResults []profileResult
+
+// multiProfile returns whether the configuration is applied to several profiles, with --profiles or --all-profiles
+func multiProfile() bool {
+	return len(configureProfiles) > 0 || configureAllProfiles
+}
+
+// validateMultiProfileFlags checks the flags used with --profiles or --all-profiles: the same configuration is
+// applied to each profile without prompting, so it must be given with --set or --file
+func validateMultiProfileFlags() error {
+	if len(configureProfiles) > 0 && configureAllProfiles {
+		return fmt.Errorf("--profiles and --all-profiles can not be used together")
+	}
+	if viper.IsSet(config.ProfileName) {
+		return fmt.Errorf("--profiles and --all-profiles can not be used with --profile")
+	}
+	if configureEdit || undoLast || listBackups || restoreBackup != "" {
+		return fmt.Errorf("--profiles and --all-profiles can not be used with --edit, --undo, --list-backups or --restore-backup")
+	}
+	if configureFile == "" && len(configureSet) == 0 {
+		return fmt.Errorf("--profiles and --all-profiles need the configuration given with --set or --file")
+	}
+	return nil
+}
+
+// targetProfiles returns the profiles of --profiles, or every valid profile with --all-profiles.
+// It fails if one of them does not exist or can not be loaded, before any of them is configured.
+func targetProfiles() ([]string, error) {
+	var names []string
+	if configureAllProfiles {
+		profiles, err := config.ListValidProfiles()
+		if err != nil {
+			return nil, fmt.Errorf("list the profiles: %w", err)
+		}
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("there is no profile to configure")
+		}
+		return names, nil
+	}
+
+	var problems []string
+	for _, p := range configureProfiles {
+		p = strings.TrimSpace(p)
+		if p == "" || containsString(names, p) {
+			continue
+		}
+		if !config.ProfileExists(p) {
+			problems = append(problems, fmt.Sprintf("profile %q does not exist", p))
+			continue
+		}
+		if _, err := config.Load(p); err != nil {
+			problems = append(problems, fmt.Sprintf("profile %q can not be loaded: %v", p, err))
+			continue
+		}
+		names = append(names, p)
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, ", "))
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no profile given")
+	}
+	return names, nil
+}
+
+// checkProfileNotPaused fails if the cluster of the profile is paused, as configuring several profiles
+// does not prompt to unpause them
+func checkProfileNotPaused(profile string) error {
+	paused, err := profilePaused(profile)
+	if err != nil {
+		klog.Warningf("unable to check whether the cluster of %s is paused: %v", profile, err)
+		return nil
+	}
+	if paused {
+		return fmt.Errorf("the cluster is paused, unpause it first: minikube -p %s unpause", profile)
+	}
+	return nil
+}
+
+// configureEachProfile configures each profile with apply, going on with the next profile when one fails,
+// and reports the outcome of each of them. It returns how many of them failed.
+func configureEachProfile(profiles []string, apply func(profile string) error) int {
+	failed := 0
+	for _, p := range profiles {
+		out.Styled(style.Running, "Configuring the {{.profile}} profile ...", out.V{"profile": p})
+		before := len(appliedChanges())
+		err := checkProfileNotPaused(p)
+		if err == nil {
+			err = apply(p)
+		}
+		r := profileResult{Profile: p, Changes: appliedChanges()[before:]}
+		if err != nil {
+			failed++
+			r.Error = err.Error()
+			out.Styled(style.Failure, "{{.profile}}: {{.error}}", out.V{"profile": p, "error": err})
+		} else {
+			out.Styled(style.Check, "{{.profile}}: configured", out.V{"profile": p})
+		}
+		profileResults = append(profileResults, r)
+	}
+	return failed
+}
+
+// configureMultipleProfiles applies the configuration of --set to the addon, or that of --file, to each profile
+func configureMultipleProfiles(profiles []string, args []string) {
+	var addon string
+	var apply func(profile string) error
+	if configureFile != "" {
+		f := mustLoadAddonsConfigFile(configureFile)
+		apply = func(profile string) error {
+			if failed := applyAddonsConfigFile(profile, f); failed > 0 {
+				return fmt.Errorf("%d of the %d addons failed to be configured", failed, len(f.Addons))
+			}
+			return nil
+		}
+	} else {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "usage: minikube addons configure ADDON_NAME --set key=value --profiles p1,p2")
+		}
+		addon = args[0]
+		values, err := parseAddonConfigSet(addon, configureSet)
+		if err != nil {
+			exitConfigure("invalid configuration", classifyError(ErrInvalidInput, err))
+		}
+		apply = func(profile string) error {
+			setConfigureEventScope(profile, addon)
+			checkClusterRunning(profile, addon)
+			warnAddonConflicts(profile, addon)
+			err := applyAddonConfigValues(profile, addon, values, false)
+			emitConfigureEvent("configured", addon, err)
+			if err != nil {
+				return err
+			}
+			saveUndoRecord(profile, addon)
+			runPostConfigHook(profile, addon)
+			return nil
+		}
+	}
+
+	failed := configureEachProfile(profiles, apply)
+	setConfigureEventScope("", addon)
+	if configureQuiet {
+		printConfigureResult(addon)
+	}
+	if failed > 0 {
+		exit.Message(reason.InternalAddonConfigure, "{{.count}} of the {{.total}} profiles failed to be configured", out.V{"count": failed, "total": len(profiles)})
+	}
+	out.SuccessT("The {{.count}} profiles were successfully configured", out.V{"count": len(profiles)})
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// useProfiles sets --profiles and --set for the duration of the test
+func useProfiles(t *testing.T, profiles, set []string) {
+	oldProfiles, oldAll, oldSet := configureProfiles, configureAllProfiles, configureSet
+	configureProfiles, configureAllProfiles, configureSet = profiles, false, set
+	t.Cleanup(func() {
+		configureProfiles, configureAllProfiles, configureSet = oldProfiles, oldAll, oldSet
+		profileResults = nil
+	})
+}
+
+func TestValidateMultiProfileFlags(t *testing.T) {
+	useProfiles(t, []string{"p1", "p2"}, nil)
+	if err := validateMultiProfileFlags(); err == nil {
+		t.Errorf("expected an error without --set or --file")
+	}
+	configureSet = []string{"interval=1m"}
+	if err := validateMultiProfileFlags(); err != nil {
+		t.Errorf("unexpected error with --set: %v", err)
+	}
+	configureAllProfiles = true
+	if err := validateMultiProfileFlags(); err == nil {
+		t.Errorf("expected an error with both --profiles and --all-profiles")
+	}
+}
+
+func TestTargetProfiles(t *testing.T) {
+	useFakeClusterClient(t)
+	for _, name := range []string{"p1", "p2"} {
+		if err := config.SaveProfile(name, &config.ClusterConfig{Name: name}); err != nil {
+			t.Fatalf("unable to save profile: %v", err)
+		}
+	}
+	tests := []struct {
+		profiles []string
+		want     []string
+		wantErr  bool
+	}{
+		{profiles: []string{"p1", "p2"}, want: []string{"p1", "p2"}},
+		{profiles: []string{" p2", "p1", "p2"}, want: []string{"p2", "p1"}},
+		{profiles: []string{"p1", "missing"}, wantErr: true},
+		{profiles: []string{""}, wantErr: true},
+	}
+	for _, tc := range tests {
+		useProfiles(t, tc.profiles, nil)
+		got, err := targetProfiles()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("targetProfiles() with %v error = %v, wantErr %t", tc.profiles, err, tc.wantErr)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("targetProfiles() with %v = %v, want %v", tc.profiles, got, tc.want)
+		}
+	}
+}
+
+func TestConfigureEachProfile(t *testing.T) {
+	useFakeClusterClient(t)
+	useProfiles(t, nil, nil)
+	orig := profilePaused
+	profilePaused = func(profile string) (bool, error) { return profile == "paused", nil }
+	t.Cleanup(func() { profilePaused = orig })
+
+	var applied []string
+	failed := configureEachProfile([]string{"p1", "broken", "paused", "p2"}, func(profile string) error {
+		applied = append(applied, profile)
+		if profile == "broken" {
+			return fmt.Errorf("boom")
+		}
+		recordApplied("configured " + profile)
+		return nil
+	})
+	if failed != 2 {
+		t.Errorf("expected 2 failed profiles, got %d", failed)
+	}
+	if want := []string{"p1", "broken", "p2"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied the configuration to %v, want %v", applied, want)
+	}
+	want := []profileResult{
+		{Profile: "p1", Changes: []string{"configured p1"}},
+		{Profile: "broken", Changes: []string{}, Error: "boom"},
+		{Profile: "paused", Changes: []string{}, Error: "the cluster is paused, unpause it first: minikube -p paused unpause"},
+		{Profile: "p2", Changes: []string{"configured p2"}},
+	}
+	if !reflect.DeepEqual(profileResults, want) {
+		t.Errorf("profile results = %+v, want %+v", profileResults, want)
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// configureQuiet is set by --quiet: only the errors and the result are printed, and nothing is prompted for
+var configureQuiet bool
+
+// quietPrompt is the prompt last answered with --quiet, and how many answers it had accepted then
+var quietPrompt struct {
+	key      string
+	accepted int
+}
+
+// quietAnswer returns the answer of the answer profile for the prompt, as --quiet does not prompt.
+// It fails if a required prompt has no answer, or if the answer was rejected: asking again would return it again.
+func quietAnswer(s string, required bool) (string, error) {
+	k := answerKey(s)
+	accepted := len(answers.given[k])
+	if quietPrompt.key == k && quietPrompt.accepted == accepted {
+		return "", classifyError(ErrInvalidInput, fmt.Errorf("the answer to %q is invalid", k))
+	}
+	quietPrompt.key, quietPrompt.accepted = k, accepted
+	def := promptDefault(s)
+	if def == "" && required {
+		return "", classifyError(ErrInvalidInput, fmt.Errorf("%q needs an answer, which is not prompted for with --quiet: give it with --answers, or use --set or --file", k))
+	}
+	return def, nil
+}
+
+// quietResponse returns the answer of the prompt with --quiet, exiting if it has none
+func quietResponse(s string, required bool) string {
+	v, err := quietAnswer(s, required)
+	if err != nil {
+		exitConfigure("missing answer", err)
+	}
+	return v
+}
+
+// configureResult is the result printed once configure succeeds with --quiet
+type configureResult struct {
+	Profile string   `json:"profile,omitempty"`
+	Addon   string   `json:"addon,omitempty"`
+	Changes []string `json:"changes"`
+	// Profiles are the outcomes of each profile with --profiles or --all-profiles
+	Profiles []profileResult `json:"profiles,omitempty"`
+}
+
+// printConfigureResult prints the changes applied by configure to the addon, or to the addons of --file if it is empty,
+// as a single JSON line, even though the output is silenced
+func printConfigureResult(addon string) {
+	r := configureResult{Profile: configureEventScope.profile, Addon: addon, Changes: appliedChanges(), Profiles: profileResults}
+	b, err := json.Marshal(r)
+	if err != nil {
+		klog.Warningf("unable to marshal the configure result: %v", err)
+		return
+	}
+	out.Output(os.Stdout, "%s\n", b)
+}
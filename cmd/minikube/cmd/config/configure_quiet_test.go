@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+// useQuiet sets --quiet until the test ends
+func useQuiet(t *testing.T) {
+	t.Helper()
+	resetAnswers(t)
+	configureQuiet = true
+	t.Cleanup(func() {
+		configureQuiet = false
+		quietPrompt.key, quietPrompt.accepted = "", 0
+	})
+}
+
+func TestQuietAnswer(t *testing.T) {
+	useQuiet(t)
+	answers.defaults = promptAnswers{"-- Enter the host:": {"headlamp.test"}}
+
+	host := "-- Enter the host: "
+	got, err := quietAnswer(host, true)
+	if err != nil || got != "headlamp.test" {
+		t.Fatalf("quietAnswer(%q) = %q, %v, want headlamp.test", host, got, err)
+	}
+	// asking again without accepting the answer means it was rejected
+	if _, err := quietAnswer(host, true); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected a rejected answer to fail with ErrInvalidInput, got %v", err)
+	}
+
+	class := "-- Enter the ingress class: "
+	if got, err := quietAnswer(class, false); err != nil || got != "" {
+		t.Errorf("quietAnswer(%q) = %q, %v, want an empty optional answer", class, got, err)
+	}
+	acceptAnswer(class, "")
+	if _, err := quietAnswer("-- Enter the port: ", true); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("expected a required prompt without answer to fail with ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestQuietPrompts(t *testing.T) {
+	useQuiet(t)
+	answers.defaults = promptAnswers{
+		"-- Enter the host:":               {"headlamp.test", "yakd.test"},
+		"Do you want to add another host?": {"y", "n"},
+	}
+
+	// the prompts take their answers without reading stdin
+	var hosts []string
+	for {
+		hosts = append(hosts, AskForStaticCheckedValue("-- Enter the host: ", validateIngressHost))
+		if !AskForYesNoConfirmation("Do you want to add another host?", posResponses, negResponses) {
+			break
+		}
+	}
+	if len(hosts) != 2 || hosts[0] != "headlamp.test" || hosts[1] != "yakd.test" {
+		t.Errorf("expected the hosts of the answers, got %v", hosts)
+	}
+}
@@ -0,0 +1,1021 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// awsRegions are the regions accepted for ECR, including the GovCloud and China partitions
+var awsRegions = []string{
+	"af-south-1",
+	"ap-east-1",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-northeast-3",
+	"ap-south-1",
+	"ap-south-2",
+	"ap-southeast-1",
+	"ap-southeast-2",
+	"ap-southeast-3",
+	"ap-southeast-4",
+	"ca-central-1",
+	"ca-west-1",
+	"cn-north-1",
+	"cn-northwest-1",
+	"eu-central-1",
+	"eu-central-2",
+	"eu-north-1",
+	"eu-south-1",
+	"eu-south-2",
+	"eu-west-1",
+	"eu-west-2",
+	"eu-west-3",
+	"il-central-1",
+	"me-central-1",
+	"me-south-1",
+	"sa-east-1",
+	"us-east-1",
+	"us-east-2",
+	"us-gov-east-1",
+	"us-gov-west-1",
+	"us-west-1",
+	"us-west-2",
+}
+
+// isValidAWSRegion checks if the region is one of the known AWS regions
+func isValidAWSRegion(region string) bool {
+	return containsString(awsRegions, region)
+}
+
+// awsRoleARN matches the ARN of an IAM role in any partition, eg. arn:aws:iam::123456789012:role/ecr-pull,
+// the name of the role may be preceded by a path
+var awsRoleARN = regexp.MustCompile(`^arn:aws(-[a-z]+)*:iam::[0-9]{12}:role/[A-Za-z0-9+=,.@_/-]+$`)
+
+// validateAWSRoleARN checks the ARN of the role to assume
+func validateAWSRoleARN(arn string) error {
+	if !awsRoleARN.MatchString(arn) || strings.HasSuffix(arn, "/") {
+		return fmt.Errorf("%q is not the ARN of a role, expected arn:aws:iam::<12 digit account>:role/<name>", arn)
+	}
+	return nil
+}
+
+// awsAccountID matches a 12 digit AWS account ID
+var awsAccountID = regexp.MustCompile(`^[0-9]{12}$`)
+
+// parseAWSAccounts parses the comma separated list of AWS account IDs the registries are in
+func parseAWSAccounts(list string) ([]string, error) {
+	var accounts []string
+	for _, a := range strings.Split(list, ",") {
+		a = strings.TrimSpace(a)
+		if !awsAccountID.MatchString(a) {
+			return nil, fmt.Errorf("%q is not a 12 digit AWS account ID", a)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, nil
+}
+
+// validateECR checks the AWS credentials, account IDs and role together: each one may be valid on its own
+// while the controller fails with their combination
+func (c registryCredsConfig) validateECR() error {
+	accounts, err := parseAWSAccounts(c.awsAccount)
+	if err != nil {
+		return err
+	}
+	// long-term access keys start with AKIA and have no session token, the temporary ones start with ASIA and need one
+	switch {
+	case strings.HasPrefix(c.awsAccessID, "AKIA") && c.awsSessionToken != "":
+		return fmt.Errorf("a session token is only used with temporary credentials, access key %s is a long-term one", c.awsAccessID)
+	case strings.HasPrefix(c.awsAccessID, "ASIA") && c.awsSessionToken == "":
+		return fmt.Errorf("access key %s is a temporary one, its session token is required", c.awsAccessID)
+	}
+	if c.awsRole == "" {
+		return nil
+	}
+	if err := validateAWSRoleARN(c.awsRole); err != nil {
+		return err
+	}
+	if c.awsSessionToken != "" {
+		return fmt.Errorf("the role %s is assumed with long-term credentials, not with a session token", c.awsRole)
+	}
+	// arn:aws:iam::<account>:role/<name>
+	roleAccount := strings.Split(c.awsRole, ":")[4]
+	for _, a := range accounts {
+		if a == roleAccount {
+			return nil
+		}
+	}
+	return fmt.Errorf("the role %s is in account %s, which is not one of the AWS account IDs %s", c.awsRole, roleAccount, strings.Join(accounts, ","))
+}
+
+// isValidRegistryServer checks if the docker registry server is a URL or host with an optional port
+func isValidRegistryServer(server string) bool {
+	if server == "" || strings.ContainsAny(server, " \t") {
+		return false
+	}
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Hostname() != ""
+}
+
+// registryCredsSecret is one of the secrets consumed by the registry-creds controller
+type registryCredsSecret struct {
+	name    string
+	cloud   string
+	data    map[string]string
+	enabled bool
+}
+
+// configured returns whether the secret should be created: its registry is enabled
+// and it holds actual values, a secret left with only placeholders would make the controller log errors
+func (s registryCredsSecret) configured() bool {
+	if !s.enabled {
+		return false
+	}
+	for _, v := range s.data {
+		if v != "" && v != registryCredsPlaceholder && v != defaultRegistryCredsGCRURL {
+			return true
+		}
+	}
+	return false
+}
+
+// registryCredsConfigurator creates the registry-creds secrets once all of them are known to be valid
+type registryCredsConfigurator struct {
+	config registryCredsConfig
+	// refresh is the new refresh interval of the controller, it is left unchanged if 0
+	refresh time.Duration
+	// runtimeRegistries is the registry config of the container runtime of the nodes, written with --runtime-registry-config
+	runtimeRegistries []runtimeRegistry
+}
+
+// registryCredsMaxRefresh is the longest refresh interval of the registry-creds controller, the lifetime of the ECR tokens
+const registryCredsMaxRefresh = 12 * time.Hour
+
+// validateRegistryCredsRefresh checks the refresh interval of the controller, which refreshes every N minutes
+func validateRegistryCredsRefresh(d time.Duration) error {
+	if d < time.Minute || d > registryCredsMaxRefresh {
+		return fmt.Errorf("refresh interval %s must be between 1m and %s", d, registryCredsMaxRefresh)
+	}
+	if d%time.Minute != 0 {
+		return fmt.Errorf("refresh interval %s must be a whole number of minutes", d)
+	}
+	return nil
+}
+
+// readRegistryCredsRefresh prompts for the refresh interval of the controller, it returns 0 to leave it unchanged
+func readRegistryCredsRefresh() time.Duration {
+	if !AskForYesNoConfirmation("\nDo you want to change how often the controller refreshes the credentials (Default 60m)?", posResponses, negResponses) {
+		return 0
+	}
+	s := AskForStaticCheckedValue("-- Enter the refresh interval (ex. 30m, at most 12h as the ECR tokens expire after 12h): ", func(s string) error {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		return validateRegistryCredsRefresh(d)
+	})
+	d, _ := time.ParseDuration(s)
+	return d
+}
+
+// Validate gathers the registry credentials from the flags or the prompts and checks them
+func (r *registryCredsConfigurator) Validate(profile string) error {
+	var err error
+	if registryCredsRuntimeConfig {
+		cfg, err := config.Load(profile)
+		if err != nil {
+			return fmt.Errorf("load config %s: %w", profile, err)
+		}
+		if err := checkRuntimeRegistrySupported(cfg.KubernetesConfig.ContainerRuntime); err != nil {
+			return err
+		}
+	}
+	if registryCredsImportDockerConfig != "" {
+		r.config, err = dockerConfigCredsConfig(registryCredsImportDockerConfig)
+	} else if registryCredsDockerServerFlag != "" {
+		r.config, err = dockerRegistryCredsConfig(registryCredsDockerServerFlag, registryCredsDockerUserFlag, registryCredsPasswordStdin, os.Stdin)
+		if err == nil {
+			r.config.docker.insecure = registryCredsDockerInsecure
+		}
+		if err == nil && registryCredsDockerCACert != "" {
+			r.config.docker.caCert, err = readCACertFile(expandPath(registryCredsDockerCACert))
+		}
+		if err == nil && registryCredsVerify {
+			ctx, cancel := context.WithTimeout(configureCtx, registryCredsVerifyTimeout)
+			defer cancel()
+			err = verifyDockerRegistry(ctx, r.config.docker)
+		}
+	} else {
+		r.config, err = readRegistryCredsConfig()
+		if err == nil && registryCredsRefreshInterval == 0 {
+			r.refresh = readRegistryCredsRefresh()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if registryCredsRefreshInterval != 0 {
+		r.refresh = registryCredsRefreshInterval
+	}
+	if r.refresh != 0 {
+		if err := validateRegistryCredsRefresh(r.refresh); err != nil {
+			return err
+		}
+	}
+	if err := r.config.validate(); err != nil {
+		return err
+	}
+	if registryCredsRuntimeConfig {
+		prompt := registryCredsImportDockerConfig == "" && registryCredsDockerServerFlag == ""
+		r.runtimeRegistries, err = readRuntimeRegistries(r.config.docker, prompt)
+	} else if r.config.enabled["dpr"] {
+		// the runtime pulls from the registries itself, so it must trust them too
+		r.runtimeRegistries, err = tlsRuntimeRegistries(r.config.docker)
+	}
+	if err != nil || len(r.runtimeRegistries) == 0 {
+		return err
+	}
+	return checkRuntimeRegistryFiles(profile, r.runtimeRegistries)
+}
+
+// Apply creates the registry-creds secrets
+func (r *registryCredsConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	if err := configureClient.EnsureNamespace(ctx, profile, registryCredsNamespace); err != nil {
+		return applyResult{}, fmt.Errorf("create namespace %s: %w", registryCredsNamespace, err)
+	}
+	if _, err := applyRegistryCredsConfig(ctx, profile, registryCredsNamespace, r.config.secrets()); err != nil {
+		return applyResult{}, fmt.Errorf("failed to update the registry-creds secrets: %w", err)
+	}
+	if r.refresh != 0 {
+		if err := applyRegistryCredsRefresh(profile, r.refresh); err != nil {
+			return applyResult{}, err
+		}
+	}
+	if len(r.runtimeRegistries) > 0 {
+		if err := applyRuntimeRegistryConfig(profile, r.runtimeRegistries); err != nil {
+			return applyResult{}, err
+		}
+	}
+	if registryCredsHealthCheckTimeout > 0 {
+		checkRegistryCredsHealth(ctx, profile, registryCredsHealthCheckTimeout)
+	}
+	return applyResult{}, nil
+}
+
+// applyRegistryCredsRefresh saves the refresh interval of the controller in the profile
+func applyRegistryCredsRefresh(profile string, refresh time.Duration) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	if cfg.RegistryCredsRefresh == refresh {
+		return nil
+	}
+	cfg.RegistryCredsRefresh = refresh
+	// Re-enable registry-creds addon in order to pass the new interval to the controller
+	if err := saveAndReenableAddon(profile, cfg, "registry-creds", false); err != nil {
+		return err
+	}
+	recordApplied("set the refresh interval of registry-creds to " + refresh.String())
+	return nil
+}
+
+// registryCredsLogTail is the number of controller log lines scanned for credential errors
+const registryCredsLogTail = 50
+
+// checkRegistryCredsHealth waits for the registry-creds controller and reports whether it loaded the credentials
+func checkRegistryCredsHealth(ctx context.Context, profile string, timeout time.Duration) {
+	out.Styled(style.HealthCheck, "Verifying the registry-creds controller ...")
+	client, err := kapi.Client(profile)
+	if err != nil {
+		out.WarningT("Unable to verify the registry-creds controller: {{.error}}", out.V{"error": err})
+		return
+	}
+	if err := kapi.WaitForPods(client, "kube-system", "name=registry-creds", timeout); err != nil {
+		out.WarningT("The registry-creds controller is not running, is the addon enabled? {{.error}}", out.V{"error": err})
+		return
+	}
+
+	logs, err := configureClient.GetPodLogs(ctx, profile, "kube-system", "name=registry-creds", registryCredsLogTail)
+	if err != nil {
+		out.WarningT("Unable to read the registry-creds controller logs: {{.error}}", out.V{"error": err})
+		return
+	}
+	errs := credentialErrors(logs)
+	if len(errs) == 0 {
+		out.Styled(style.Check, "The registry-creds controller is running and loaded the credentials")
+		return
+	}
+	out.WarningT("The registry-creds controller reported errors while loading the credentials:")
+	for _, l := range errs {
+		out.Styled(style.LogEntry, l)
+	}
+}
+
+// credentialErrors returns the log lines reporting an error
+func credentialErrors(logs string) []string {
+	var errs []string
+	for _, l := range strings.Split(logs, "\n") {
+		lower := strings.ToLower(l)
+		if strings.Contains(lower, "error") || strings.Contains(lower, "failed") {
+			errs = append(errs, strings.TrimSpace(l))
+		}
+	}
+	return errs
+}
+
+// registryCredsConfig holds the values stored in the registry-creds secrets
+type registryCredsConfig struct {
+	awsAccessID     string
+	awsAccessKey    string
+	awsSessionToken string
+	awsRegion       string
+	awsAccount      string
+	awsRole         string
+	gcr             gcrRegistry
+	docker          dockerRegistry
+	acrURL          string
+	acrClientID     string
+	acrPassword     string
+	// enabled holds the registries enabled by the user, keyed by cloud, only their secrets are created
+	enabled map[string]bool
+}
+
+// enable marks the registry of the cloud ("ecr", "gcr", "acr" or "dpr") as enabled
+func (c *registryCredsConfig) enable(cloud string) {
+	if c.enabled == nil {
+		c.enabled = map[string]bool{}
+	}
+	c.enabled[cloud] = true
+}
+
+// gcrRegistry holds the credentials of a single Google registry, eg. the GCR or Artifact Registry of a project
+type gcrRegistry struct {
+	credentials string
+	url         string
+}
+
+// dockerRegistry holds the credentials of a single docker private registry
+type dockerRegistry struct {
+	server   string
+	user     string
+	password string
+	// caCert is the PEM CA cert the registry cert is signed with, eg. a self-signed cert
+	caCert string
+	// insecure skips the verification of the registry cert
+	insecure bool
+}
+
+// registryCredsPlaceholder is the value of the credentials of the registries that are not enabled
+const registryCredsPlaceholder = "changeme"
+
+// defaultRegistryCredsGCRURL is the GCR URL used unless the user changes it
+const defaultRegistryCredsGCRURL = "https://gcr.io"
+
+// defaultRegistryCredsConfig returns the placeholder values used for the registries that are not enabled
+func defaultRegistryCredsConfig() registryCredsConfig {
+	return registryCredsConfig{
+		awsAccessID:     registryCredsPlaceholder,
+		awsAccessKey:    registryCredsPlaceholder,
+		awsSessionToken: "",
+		awsRegion:       registryCredsPlaceholder,
+		awsAccount:      registryCredsPlaceholder,
+		awsRole:         registryCredsPlaceholder,
+		gcr:             gcrRegistry{credentials: registryCredsPlaceholder, url: defaultRegistryCredsGCRURL},
+		docker:          dockerRegistry{server: registryCredsPlaceholder, user: registryCredsPlaceholder, password: registryCredsPlaceholder},
+		acrURL:          registryCredsPlaceholder,
+		acrClientID:     registryCredsPlaceholder,
+		acrPassword:     registryCredsPlaceholder,
+	}
+}
+
+// readRegistryCredsConfig gathers the registry-creds configuration on the client.
+// Any file-based input (eg. the GCR credentials) is read locally here, so only the resulting data
+// is ever sent to the cluster, which keeps the flow working against remote profiles.
+func readRegistryCredsConfig() (registryCredsConfig, error) {
+	c := defaultRegistryCredsConfig()
+
+	// the credentials saved by a previous run, for any profile, are offered before prompting for them
+	savedPath := savedRegistryCredsPath()
+	saved, err := loadSavedRegistryCreds(savedPath)
+	canSave := err == nil
+	if err != nil {
+		out.WarningT("Ignoring the saved registry credentials: {{.error}}", out.V{"error": err})
+	}
+	save := false
+
+	enableAWSECR := AskForYesNoConfirmation("\nDo you want to enable AWS Elastic Container Registry?", posResponses, negResponses)
+	if enableAWSECR {
+		c.enable("ecr")
+		if saved.AWS != nil && AskForYesNoConfirmation("-- Do you want to use the saved AWS credentials?", posResponses, negResponses) {
+			saved.AWS.apply(&c)
+		} else {
+			for {
+				c.awsAccessID = AskForStaticValue("-- Enter AWS Access Key ID: ")
+				c.awsAccessKey = AskForPasswordValue("-- Enter AWS Secret Access Key: ")
+				c.awsSessionToken = AskForPasswordValueOptional("-- (Optional) Enter AWS Session Token: ")
+				c.awsRegion = AskForStaticValidatedValue("-- Enter AWS Region (e.g. us-east-1): ", isValidAWSRegion)
+				c.awsAccount = AskForStaticCheckedValue("-- Enter 12 digit AWS Account ID (Comma separated list): ", func(s string) error {
+					_, err := parseAWSAccounts(s)
+					return err
+				})
+				c.awsRole = AskForStaticCheckedValueOptional("-- (Optional) Enter ARN of AWS role to assume: ", validateAWSRoleARN)
+				if err := c.validateECR(); err != nil {
+					out.WarningT("Invalid AWS credentials: {{.error}}, please enter them again", out.V{"error": err})
+					continue
+				}
+				if !reenterAfterFailedCheck("AWS", func(ctx context.Context) error { return verifyECRCredentials(ctx, c) }) {
+					break
+				}
+			}
+			if canSave && AskForYesNoConfirmation("-- Do you want to save the AWS credentials for the other profiles?", posResponses, negResponses) {
+				saved.AWS = c.savedAWS()
+				save = true
+			}
+		}
+	}
+
+	enableGCR := AskForYesNoConfirmation("\nDo you want to enable Google Container Registry?", posResponses, negResponses)
+	if enableGCR {
+		c.enable("gcr")
+		c.gcr, err = readGCRRegistry()
+		if err != nil {
+			return c, err
+		}
+	}
+
+	enableDR := AskForYesNoConfirmation("\nDo you want to enable Docker Registry?", posResponses, negResponses)
+	if enableDR {
+		c.enable("dpr")
+		if saved.Docker != nil && AskForYesNoConfirmation("-- Do you want to use the saved docker registry credentials?", posResponses, negResponses) {
+			saved.Docker.apply(&c)
+		} else {
+			var imported bool
+			if c.docker, imported = askDockerConfigImport(); !imported {
+				c.docker = readDockerRegistry()
+			}
+			if canSave && AskForYesNoConfirmation("-- Do you want to save the docker registry credentials for the other profiles?", posResponses, negResponses) {
+				saved.Docker = c.savedDocker()
+				save = true
+			}
+		}
+	}
+
+	enableACR := AskForYesNoConfirmation("\nDo you want to enable Azure Container Registry?", posResponses, negResponses)
+	if enableACR {
+		c.enable("acr")
+		if saved.ACR != nil && AskForYesNoConfirmation("-- Do you want to use the saved Azure Container Registry credentials?", posResponses, negResponses) {
+			saved.ACR.apply(&c)
+		} else {
+			for {
+				if err := readACRCredentials(&c); err != nil {
+					return c, err
+				}
+				if !reenterAfterFailedCheck("Azure Container Registry", func(ctx context.Context) error {
+					return verifyDockerRegistryLogin(ctx, http.DefaultTransport, c.acrURL, c.acrClientID, c.acrPassword)
+				}) {
+					break
+				}
+			}
+			if canSave && AskForYesNoConfirmation("-- Do you want to save the Azure Container Registry credentials for the other profiles?", posResponses, negResponses) {
+				saved.ACR = c.savedACR()
+				save = true
+			}
+		}
+	}
+
+	if save {
+		if err := writeSavedRegistryCreds(savedPath, saved); err != nil {
+			out.WarningT("Unable to save the registry credentials: {{.error}}", out.V{"error": err})
+		} else {
+			out.Styled(style.Check, "Saved the registry credentials to {{.path}}", out.V{"path": savedPath})
+		}
+	}
+
+	return c, nil
+}
+
+// readDockerRegistry prompts for the credentials of the docker registry, the controller reads a single one
+func readDockerRegistry() dockerRegistry {
+	for {
+		r := dockerRegistry{
+			server:   AskForStaticValidatedValue("-- Enter docker registry server url: ", isValidRegistryServer),
+			user:     AskForStaticValue("-- Enter docker registry username: "),
+			password: AskForPasswordValue("-- Enter docker registry password: "),
+		}
+		r.caCert, r.insecure = readRegistryTLS(r.server)
+		if reenterAfterFailedCheck(r.server, func(ctx context.Context) error { return verifyDockerRegistry(ctx, r) }) {
+			continue
+		}
+		return r
+	}
+}
+
+// registryTLSCACert and registryTLSInsecure are the ways to trust a registry whose cert is not signed by a public CA
+const (
+	registryTLSCACert   = "CA cert file"
+	registryTLSInsecure = "skip the verification"
+)
+
+// readRegistryTLS prompts for how to trust a registry with a self-signed cert or a cert signed by a private CA,
+// it returns the PEM CA cert of the registry or whether to skip the verification of its cert
+func readRegistryTLS(server string) (string, bool) {
+	if !AskForYesNoConfirmation("-- Does "+server+" use a self-signed cert or a cert signed by a private CA?", posResponses, negResponses) {
+		return "", false
+	}
+	if AskForChoice("-- How should the cert of "+server+" be trusted?", []string{registryTLSCACert, registryTLSInsecure}) == registryTLSInsecure {
+		out.WarningT("The cert of {{.server}} will not be verified, anyone between the nodes and the registry can read the pulled images and credentials", out.V{"server": server})
+		return "", true
+	}
+	for {
+		path := AskForFilePath("-- Enter path to the PEM CA cert (e.g. ~/certs/ca.crt): ")
+		ca, err := readCACertFile(path)
+		if err != nil {
+			out.FailureT("{{.path}} is not a valid CA cert: {{.error}}", out.V{"path": path, "error": err})
+			continue
+		}
+		return ca, false
+	}
+}
+
+// readGCRRegistry prompts for the credentials file of a Google registry, until it holds valid credentials, then for its URL
+func readGCRRegistry() (gcrRegistry, error) {
+	r := gcrRegistry{url: defaultRegistryCredsGCRURL}
+	for {
+		gcrPath := AskForFilePath("-- Enter path to credentials (e.g. ~/.config/gcloud/application_default_credentials.json):")
+		// Read file from the local disk, not from the cluster node
+		dat, err := readTextFile(gcrPath)
+		if errors.Is(err, ErrFileTooLarge) || errors.Is(err, ErrFileNotText) {
+			out.FailureT("{{.path}} is not a valid JSON credentials file: {{.error}}", out.V{"path": gcrPath, "error": err})
+			continue
+		}
+		if err != nil {
+			return r, fmt.Errorf("reading %s: %w", gcrPath, err)
+		}
+		if !json.Valid(dat) {
+			out.FailureT("{{.path}} is not a valid JSON credentials file", out.V{"path": gcrPath})
+			continue
+		}
+		if reenterAfterFailedCheck("Google Container Registry", func(ctx context.Context) error { return verifyGCRCredentials(ctx, dat) }) {
+			continue
+		}
+		r.credentials = string(dat)
+		if !isUserCredentials(dat) {
+			break
+		}
+		out.WarningT("{{.path}} holds user credentials, whose tokens expire, a service account key is recommended for the registry-creds addon", out.V{"path": gcrPath})
+		if AskForYesNoConfirmation("-- Do you want to use these user credentials anyway?", posResponses, negResponses) {
+			break
+		}
+	}
+
+	if AskForYesNoConfirmation("-- Do you want to change the GCR URL (Default https://gcr.io)?", posResponses, negResponses) {
+		r.url = AskForStaticValidatedValue("-- Enter GCR or Artifact Registry URL (e.g. https://asia.gcr.io, https://europe-docker.pkg.dev): ", isValidRegistryServer)
+	}
+	return r, nil
+}
+
+// isUserCredentials returns whether the Google credentials are the application default credentials of a user,
+// as written by `gcloud auth application-default login`, rather than a service account key
+func isUserCredentials(data []byte) bool {
+	var creds struct {
+		Type         string `json:"type"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return false
+	}
+	return creds.Type == "authorized_user" || (creds.Type != "service_account" && creds.RefreshToken != "")
+}
+
+// azureServicePrincipal holds the fields of an Azure service principal credentials file,
+// as written by `az ad sp create-for-rbac --sdk-auth`
+type azureServicePrincipal struct {
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	TenantID     string `json:"tenantId"`
+}
+
+// parseAzureServicePrincipal parses an Azure service principal credentials file.
+// The tenant is not used by the registry-creds controller, but a file without it is not a valid service principal.
+func parseAzureServicePrincipal(data []byte) (azureServicePrincipal, error) {
+	var sp azureServicePrincipal
+	if err := json.Unmarshal(data, &sp); err != nil {
+		return sp, fmt.Errorf("parsing service principal credentials: %w", err)
+	}
+	var missing []string
+	if sp.ClientID == "" {
+		missing = append(missing, "clientId")
+	}
+	if sp.ClientSecret == "" {
+		missing = append(missing, "clientSecret")
+	}
+	if sp.TenantID == "" {
+		missing = append(missing, "tenantId")
+	}
+	if len(missing) > 0 {
+		return sp, fmt.Errorf("service principal credentials are missing %s", strings.Join(missing, ", "))
+	}
+	return sp, nil
+}
+
+// acrAuthMethods are the ways to log in to ACR. The registry-creds controller logs in with a username and
+// a password whatever the method, so they all fill the client ID and password of the registry-creds-acr secret.
+var acrAuthMethods = []string{"service principal", "admin user", "token"}
+
+var (
+	// acrURLPattern matches the login server of a registry in any Azure cloud, eg. myregistry.azurecr.io
+	acrURLPattern = regexp.MustCompile(`^(https://)?[a-zA-Z0-9]{5,50}\.azurecr\.(io|cn|us|de)/?$`)
+	// acrClientIDPattern matches the application ID of a service principal, a GUID
+	acrClientIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// acrAdminUserPattern matches the admin user of a registry, which is the name of the registry
+	acrAdminUserPattern = regexp.MustCompile(`^[a-zA-Z0-9]{5,50}$`)
+	// acrTokenNamePattern matches the name of a repository scoped token of a registry
+	acrTokenNamePattern = regexp.MustCompile(`^[a-zA-Z0-9-]{3,50}$`)
+)
+
+func isValidACRURL(s string) bool {
+	return acrURLPattern.MatchString(s)
+}
+
+func isValidACRClientID(s string) bool {
+	return acrClientIDPattern.MatchString(s)
+}
+
+func isValidACRAdminUser(s string) bool {
+	return acrAdminUserPattern.MatchString(s)
+}
+
+func isValidACRTokenName(s string) bool {
+	return acrTokenNamePattern.MatchString(s)
+}
+
+// readACRCredentials prompts for the ACR URL and the credentials of the chosen auth method
+func readACRCredentials(c *registryCredsConfig) error {
+	c.acrURL = AskForStaticValidatedValue("-- Enter Azure Container Registry (ACR) URL (e.g. myregistry.azurecr.io): ", isValidACRURL)
+
+	switch AskForChoice("-- How do you want to log in to Azure Container Registry? ", acrAuthMethods) {
+	case "admin user":
+		c.acrClientID = AskForStaticValidatedValue("-- Enter the admin user of the registry (the registry name): ", isValidACRAdminUser)
+		c.acrPassword = AskForPasswordValue("-- Enter the admin password of the registry: ")
+	case "token":
+		c.acrClientID = AskForStaticValidatedValue("-- Enter the name of the registry token: ", isValidACRTokenName)
+		c.acrPassword = AskForPasswordValue("-- Enter a password of the registry token: ")
+	default:
+		if AskForYesNoConfirmation("-- Do you want to read the service principal from a credentials file?", posResponses, negResponses) {
+			spPath := AskForFilePath("-- Enter path to service principal credentials (e.g. ~/.azure/sp.json): ")
+			// Read file from the local disk, not from the cluster node
+			dat, err := readTextFile(spPath)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", spPath, err)
+			}
+			sp, err := parseAzureServicePrincipal(dat)
+			if err != nil {
+				return fmt.Errorf("%s: %w", spPath, err)
+			}
+			c.acrClientID = sp.ClientID
+			c.acrPassword = sp.ClientSecret
+		} else {
+			c.acrClientID = AskForStaticValidatedValue("-- Enter client ID (service principal ID) to access ACR: ", isValidACRClientID)
+			c.acrPassword = AskForPasswordValue("-- Enter service principal password to access Azure Container Registry: ")
+		}
+	}
+	return nil
+}
+
+// validate checks the values of the enabled registries, the placeholders of the others are left as they are
+func (c registryCredsConfig) validate() error {
+	d := defaultRegistryCredsConfig()
+	if c.awsRegion != d.awsRegion && !isValidAWSRegion(c.awsRegion) {
+		return fmt.Errorf("invalid AWS region %q", c.awsRegion)
+	}
+	if c.awsRole != d.awsRole && c.awsRole != "" {
+		if err := validateAWSRoleARN(c.awsRole); err != nil {
+			return err
+		}
+	}
+	if c.awsAccount != d.awsAccount {
+		if err := c.validateECR(); err != nil {
+			return err
+		}
+	}
+	if c.gcr.credentials != registryCredsPlaceholder && !json.Valid([]byte(c.gcr.credentials)) {
+		return fmt.Errorf("GCR credentials of %s are not valid JSON", c.gcr.url)
+	}
+	if c.gcr.url != defaultRegistryCredsGCRURL && !isValidRegistryServer(c.gcr.url) {
+		return fmt.Errorf("invalid GCR URL %q", c.gcr.url)
+	}
+	r := c.docker
+	if r.server != registryCredsPlaceholder && !isValidRegistryServer(r.server) {
+		return fmt.Errorf("invalid docker registry server %q", r.server)
+	}
+	if r.caCert != "" && r.insecure {
+		return fmt.Errorf("docker registry %s can not have a CA cert and skip the verification of its cert", r.server)
+	}
+	if r.caCert != "" {
+		if err := parseCACert([]byte(r.caCert)); err != nil {
+			return fmt.Errorf("CA cert of docker registry %s: %w", r.server, err)
+		}
+	}
+	return nil
+}
+
+// dockerRegistryCredsConfig returns the config of a single docker registry given with flags, reading the password from stdin
+func dockerRegistryCredsConfig(server, user string, passwordStdin bool, stdin io.Reader) (registryCredsConfig, error) {
+	c := defaultRegistryCredsConfig()
+	if !isValidRegistryServer(server) {
+		return c, fmt.Errorf("invalid docker registry server %q", server)
+	}
+	if user == "" || !passwordStdin {
+		return c, fmt.Errorf("--docker-server requires --docker-user and --docker-password-stdin")
+	}
+	password, err := readPasswordStdin(stdin)
+	if err != nil {
+		return c, err
+	}
+	c.docker = dockerRegistry{server: server, user: user, password: password}
+	c.enable("dpr")
+	return c, nil
+}
+
+// readPasswordStdin reads a password piped to stdin, ignoring the trailing newline
+func readPasswordStdin(stdin io.Reader) (string, error) {
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading password from stdin: %w", err)
+	}
+	password := strings.TrimRight(string(b), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password read from stdin is empty")
+	}
+	return password, nil
+}
+
+// secrets returns the secrets consumed by the registry-creds controller for the config
+func (c registryCredsConfig) secrets() []registryCredsSecret {
+	secrets := []registryCredsSecret{
+		{
+			name:    "registry-creds-ecr",
+			cloud:   "ecr",
+			enabled: c.enabled["ecr"],
+			data: map[string]string{
+				"AWS_ACCESS_KEY_ID":     c.awsAccessID,
+				"AWS_SECRET_ACCESS_KEY": c.awsAccessKey,
+				"AWS_SESSION_TOKEN":     c.awsSessionToken,
+				"aws-account":           c.awsAccount,
+				"aws-region":            c.awsRegion,
+				"aws-assume-role":       c.awsRole,
+			},
+		},
+	}
+	secrets = append(secrets, registryCredsSecret{
+		name:    "registry-creds-gcr",
+		cloud:   "gcr",
+		enabled: c.enabled["gcr"],
+		data: map[string]string{
+			"application_default_credentials.json": c.gcr.credentials,
+			"gcrurl":                               c.gcr.url,
+		},
+	})
+	secrets = append(secrets, registryCredsSecret{
+		name:    "registry-creds-acr",
+		cloud:   "acr",
+		enabled: c.enabled["acr"],
+		data: map[string]string{
+			"ACR_URL":       c.acrURL,
+			"ACR_CLIENT_ID": c.acrClientID,
+			"ACR_PASSWORD":  c.acrPassword,
+		},
+	})
+	return append(secrets, c.dockerRegistrySecret())
+}
+
+// dockerRegistrySecret returns the registry-creds-dpr secret of the docker private registry
+func (c registryCredsConfig) dockerRegistrySecret() registryCredsSecret {
+	// the CA cert is not stored: the controller does not read it, it is only written on the nodes for the container runtime
+	return registryCredsSecret{
+		name:    "registry-creds-dpr",
+		cloud:   "dpr",
+		enabled: c.enabled["dpr"],
+		data: map[string]string{
+			"DOCKER_PRIVATE_REGISTRY_SERVER":   c.docker.server,
+			"DOCKER_PRIVATE_REGISTRY_USER":     c.docker.user,
+			"DOCKER_PRIVATE_REGISTRY_PASSWORD": c.docker.password,
+		},
+	}
+}
+
+// registryCredsRollbackTimeout bounds the rollback of the registry-creds secrets, which runs even if the cluster
+// operations were canceled or timed out
+const registryCredsRollbackTimeout = 30 * time.Second
+
+// registryCredsChange is a change of applyRegistryCredsConfig to a secret, with the data the secret held before,
+// so that the change can be rolled back
+type registryCredsChange struct {
+	secret registryCredsSecret
+	// delete is set if the secret is deleted, rather than created or replaced
+	delete bool
+	// previous is the data of the secret before the change, nil if the secret did not exist
+	previous map[string]string
+}
+
+// planRegistryCredsChanges gathers the changes making the cluster match the secrets, without making any of them:
+// the secrets of the enabled registries are created or replaced, those of the other registries are deleted
+func planRegistryCredsChanges(ctx context.Context, profile, namespace string, secrets []registryCredsSecret) ([]registryCredsChange, error) {
+	var changes []registryCredsChange
+	for _, s := range secrets {
+		previous, err := configureClient.GetSecretData(ctx, profile, namespace, s.name)
+		if err != nil {
+			return nil, fmt.Errorf("get %s secret: %w", s.name, err)
+		}
+		if s.configured() {
+			changes = append(changes, registryCredsChange{secret: s, previous: previous})
+		} else if previous != nil {
+			changes = append(changes, registryCredsChange{secret: s, delete: true, previous: previous})
+		}
+	}
+	return changes, nil
+}
+
+// applyRegistryCredsConfig makes the registry-creds secrets in the cluster match the config, all or nothing:
+// the changes are planned before any is made, and those already made are rolled back if one fails.
+// It returns the changes made, so the caller can roll them back if a later step fails.
+func applyRegistryCredsConfig(ctx context.Context, profile, namespace string, secrets []registryCredsSecret) ([]registryCredsChange, error) {
+	changes, err := planRegistryCredsChanges(ctx, profile, namespace, secrets)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range changes {
+		if err := applyRegistryCredsChange(ctx, profile, namespace, c); err != nil {
+			out.FailureT("ERROR updating `{{.name}}` secret: {{.error}}", out.V{"name": c.secret.name, "error": err})
+			rollbackRegistryCredsChanges(ctx, profile, namespace, changes[:i])
+			return nil, fmt.Errorf("%s secret: %w, the changes made before were rolled back", c.secret.name, err)
+		}
+	}
+	return changes, nil
+}
+
+// applyRegistryCredsChange creates, replaces or deletes a single registry-creds secret
+func applyRegistryCredsChange(ctx context.Context, profile, namespace string, c registryCredsChange) error {
+	if !c.delete {
+		return createRegistryCredsSecret(ctx, profile, namespace, c.secret)
+	}
+	if err := configureClient.DeleteSecret(ctx, profile, namespace, c.secret.name); err != nil {
+		return err
+	}
+	recordApplied("deleted the " + namespace + "/" + c.secret.name + " secret")
+	return nil
+}
+
+// rollbackRegistryCredsChanges undoes the changes in reverse order: the secrets which did not exist are deleted,
+// the others are restored with their previous data. It goes on past failures, reporting the secrets it could not restore.
+func rollbackRegistryCredsChanges(ctx context.Context, profile, namespace string, changes []registryCredsChange) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), registryCredsRollbackTimeout)
+	defer cancel()
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		var err error
+		if c.previous == nil {
+			err = configureClient.DeleteSecret(ctx, profile, namespace, c.secret.name)
+		} else {
+			previous := registryCredsSecret{name: c.secret.name, cloud: c.secret.cloud, data: c.previous}
+			err = configureClient.CreateSecret(ctx, profile, namespace, previous.name, previous.data, registryCredsLabels(previous))
+		}
+		if err != nil {
+			out.FailureT("Failed to roll back the `{{.name}}` secret: {{.error}}", out.V{"name": c.secret.name, "error": err})
+			continue
+		}
+		recordApplied("rolled back the " + namespace + "/" + c.secret.name + " secret")
+	}
+}
+
+// createRegistryCredsSecret creates or replaces a single registry-creds secret
+func createRegistryCredsSecret(ctx context.Context, profile, namespace string, s registryCredsSecret) error {
+	err := configureClient.CreateSecret(ctx, profile, namespace, s.name, s.data, registryCredsLabels(s))
+	if err != nil {
+		return classifyError(ErrSecretCreate, err)
+	}
+	recordApplied("created the " + namespace + "/" + s.name + " secret")
+	return nil
+}
+
+// registryCredsLabels returns the labels of a registry-creds secret
+func registryCredsLabels(s registryCredsSecret) map[string]string {
+	return map[string]string{
+		"app":                           "registry-creds",
+		"cloud":                         s.cloud,
+		"kubernetes.io/minikube-addons": "registry-creds",
+	}
+}
+
+// registryCredsPublicKeys lists the non-sensitive keys of the registry-creds secrets of each cloud,
+// the values of the other keys are redacted by --show
+var registryCredsPublicKeys = map[string][]string{
+	"ecr": {"aws-account", "aws-region", "aws-assume-role"},
+	"gcr": {"gcrurl"},
+	"acr": {"ACR_URL", "ACR_CLIENT_ID"},
+	"dpr": {"DOCKER_PRIVATE_REGISTRY_SERVER", "DOCKER_PRIVATE_REGISTRY_USER"},
+}
+
+// showRegistryCredsConfig prints the registries configured in the registry-creds secrets, redacting the credentials
+func showRegistryCredsConfig(profile, namespace string) {
+	ctx, cancel := clusterContext()
+	defer cancel()
+
+	for _, cloud := range []string{"ecr", "gcr", "acr", "dpr"} {
+		if err := showRegistryCredsSecret(ctx, profile, namespace, cloud); err != nil {
+			exitConfigure("failed to read the registry-creds secrets", err)
+		}
+	}
+}
+
+// showRegistryCredsSecret prints the registry-creds secret of the cloud
+func showRegistryCredsSecret(ctx context.Context, profile, namespace, cloud string) error {
+	name := "registry-creds-" + cloud
+	data, err := configureClient.GetSecretData(ctx, profile, namespace, name)
+	if err != nil {
+		return err
+	}
+	if !isConfiguredRegistryCredsSecret(data) {
+		out.Styled(style.Empty, "{{.name}}: not configured", out.V{"name": name})
+		return nil
+	}
+	out.Styled(style.Check, "{{.name}}:", out.V{"name": name})
+	for _, field := range redactedRegistryCredsData(data, registryCredsPublicKeys[cloud]) {
+		out.Styled(style.Option, field)
+	}
+	return nil
+}
+
+// isConfiguredRegistryCredsSecret returns whether the secret data holds actual credentials,
+// the registries that were not enabled only hold placeholder values
+func isConfiguredRegistryCredsSecret(data map[string]string) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, v := range data {
+		if v == registryCredsPlaceholder {
+			return false
+		}
+	}
+	return true
+}
+
+// redactedRegistryCredsData returns the "key: value" fields of the secret data sorted by key,
+// with the values of the keys that are not public redacted. Empty values are skipped.
+func redactedRegistryCredsData(data map[string]string, public []string) []string {
+	var keys []string
+	for k, v := range data {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var fields []string
+	for _, k := range keys {
+		v := "<redacted>"
+		if containsString(public, k) {
+			v = data[k]
+		}
+		fields = append(fields, k+": "+v)
+	}
+	return fields
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// savedRegistryCreds are the registry-creds credentials saved for reuse by the other profiles.
+// They are stored in the minikube home rather than in a profile, in a file only readable by the user.
+type savedRegistryCreds struct {
+	AWS    *savedAWSCreds    `json:"aws,omitempty"`
+	Docker *savedDockerCreds `json:"docker,omitempty"`
+	ACR    *savedACRCreds    `json:"acr,omitempty"`
+}
+
+// savedAWSCreds are the saved credentials of AWS Elastic Container Registry
+type savedAWSCreds struct {
+	AccessID     string `json:"accessID"`
+	AccessKey    string `json:"accessKey"`
+	SessionToken string `json:"sessionToken,omitempty"`
+	Region       string `json:"region"`
+	Account      string `json:"account"`
+	Role         string `json:"role,omitempty"`
+}
+
+// savedDockerCreds are the saved credentials of the docker private registry
+type savedDockerCreds struct {
+	Server   string `json:"server"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	CACert   string `json:"caCert,omitempty"`
+	Insecure bool   `json:"insecure,omitempty"`
+}
+
+// savedACRCreds are the saved credentials of Azure Container Registry
+type savedACRCreds struct {
+	URL      string `json:"url"`
+	ClientID string `json:"clientID"`
+	Password string `json:"password"`
+}
+
+// savedRegistryCredsPath returns the path of the saved registry-creds credentials
+func savedRegistryCredsPath() string {
+	return localpath.MakeMiniPath("config", "registry-creds.json")
+}
+
+// loadSavedRegistryCreds reads the saved credentials, none are saved if the file does not exist.
+// A file readable by other users is refused, as it may have been exposed.
+func loadSavedRegistryCreds(path string) (savedRegistryCreds, error) {
+	var saved savedRegistryCreds
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return saved, nil
+	}
+	if err != nil {
+		return saved, err
+	}
+	// Windows does not support unix permissions
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0077 != 0 {
+		return saved, fmt.Errorf("%s must only be accessible by its owner, run: chmod 600 %s", path, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return saved, err
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return saved, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return saved, nil
+}
+
+// writeSavedRegistryCreds writes the saved credentials, only readable by the user
+func writeSavedRegistryCreds(path string, saved savedRegistryCreds) error {
+	data, err := json.MarshalIndent(saved, "", "	")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the permissions of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// apply sets the saved AWS credentials on the config
+func (a *savedAWSCreds) apply(c *registryCredsConfig) {
+	c.awsAccessID = a.AccessID
+	c.awsAccessKey = a.AccessKey
+	c.awsSessionToken = a.SessionToken
+	c.awsRegion = a.Region
+	c.awsAccount = a.Account
+	c.awsRole = a.Role
+}
+
+// apply sets the saved docker registry credentials on the config
+func (d *savedDockerCreds) apply(c *registryCredsConfig) {
+	c.docker = dockerRegistry{server: d.Server, user: d.User, password: d.Password, caCert: d.CACert, insecure: d.Insecure}
+}
+
+// apply sets the saved ACR credentials on the config
+func (a *savedACRCreds) apply(c *registryCredsConfig) {
+	c.acrURL = a.URL
+	c.acrClientID = a.ClientID
+	c.acrPassword = a.Password
+}
+
+// savedAWS returns the AWS credentials of the config to save
+func (c registryCredsConfig) savedAWS() *savedAWSCreds {
+	return &savedAWSCreds{
+		AccessID:     c.awsAccessID,
+		AccessKey:    c.awsAccessKey,
+		SessionToken: c.awsSessionToken,
+		Region:       c.awsRegion,
+		Account:      c.awsAccount,
+		Role:         c.awsRole,
+	}
+}
+
+// savedDocker returns the docker registry credentials of the config to save
+func (c registryCredsConfig) savedDocker() *savedDockerCreds {
+	r := c.docker
+	return &savedDockerCreds{Server: r.server, User: r.user, Password: r.password, CACert: r.caCert, Insecure: r.insecure}
+}
+
+// savedACR returns the ACR credentials of the config to save
+func (c registryCredsConfig) savedACR() *savedACRCreds {
+	return &savedACRCreds{URL: c.acrURL, ClientID: c.acrClientID, Password: c.acrPassword}
+}
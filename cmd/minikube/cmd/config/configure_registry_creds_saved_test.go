@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSavedRegistryCreds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "registry-creds.json")
+
+	saved, err := loadSavedRegistryCreds(path)
+	if err != nil || saved.AWS != nil || saved.Docker != nil || saved.ACR != nil {
+		t.Fatalf("expected no saved credentials, got %+v and error %v", saved, err)
+	}
+
+	c := defaultRegistryCredsConfig()
+	c.awsAccessID = "id"
+	c.awsAccessKey = "key"
+	c.awsRegion = "us-east-1"
+	c.awsAccount = "123456789012"
+	c.docker = dockerRegistry{server: "registry.dev", user: "user", password: "password"}
+	if err := writeSavedRegistryCreds(path, savedRegistryCreds{AWS: c.savedAWS(), Docker: c.savedDocker()}); err != nil {
+		t.Fatalf("error saving credentials: %v", err)
+	}
+
+	saved, err = loadSavedRegistryCreds(path)
+	if err != nil {
+		t.Fatalf("error loading credentials: %v", err)
+	}
+	if saved.ACR != nil {
+		t.Errorf("expected no saved ACR credentials, got %+v", saved.ACR)
+	}
+	loaded := defaultRegistryCredsConfig()
+	saved.AWS.apply(&loaded)
+	saved.Docker.apply(&loaded)
+	if loaded.awsAccessKey != "key" || loaded.awsRegion != "us-east-1" || loaded.awsAccount != "123456789012" {
+		t.Errorf("unexpected AWS credentials %+v", saved.AWS)
+	}
+	if loaded.docker != c.docker {
+		t.Errorf("unexpected docker registry %+v", loaded.docker)
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("error changing permissions: %v", err)
+	}
+	if _, err := loadSavedRegistryCreds(path); err == nil {
+		t.Errorf("expected an error loading credentials readable by other users")
+	}
+}
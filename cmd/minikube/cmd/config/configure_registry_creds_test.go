@@ -0,0 +1,381 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsValidAWSRegion(t *testing.T) {
+	tests := []struct {
+		region string
+		valid  bool
+	}{
+		{"us-east-1", true},
+		{"eu-west-3", true},
+		{"us-gov-west-1", true},
+		{"cn-northwest-1", true},
+		{"us-east1", false},
+		{"US-EAST-1", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		if got := isValidAWSRegion(tc.region); got != tc.valid {
+			t.Errorf("isValidAWSRegion(%q) = %v, want %v", tc.region, got, tc.valid)
+		}
+	}
+}
+
+func TestCredentialErrors(t *testing.T) {
+	logs := `I0101 00:00:00 main.go:10] Starting registry-creds
+E0101 00:00:01 main.go:20] Failed to get ECR token: invalid credentials
+I0101 00:00:02 main.go:30] Refreshed gcr credentials
+E0101 00:00:03 main.go:40] error updating secret awsecr-cred
+`
+	got := credentialErrors(logs)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 error lines but got %d: %v", len(got), got)
+	}
+	if !strings.Contains(got[0], "ECR token") || !strings.Contains(got[1], "awsecr-cred") {
+		t.Errorf("unexpected error lines: %v", got)
+	}
+	if got := credentialErrors("I0101 00:00:00 main.go:10] Starting registry-creds\n"); len(got) != 0 {
+		t.Errorf("expected no error lines but got %v", got)
+	}
+}
+
+func TestIsValidRegistryServer(t *testing.T) {
+	tests := []struct {
+		server string
+		valid  bool
+	}{
+		{"https://registry.example.com", true},
+		{"http://localhost:5000", true},
+		{"registry.example.com:5000", true},
+		{"quay.io", true},
+		{"", false},
+		{"ftp://registry.example.com", false},
+		{"registry example.com", false},
+		{"https://", false},
+	}
+	for _, tc := range tests {
+		if got := isValidRegistryServer(tc.server); got != tc.valid {
+			t.Errorf("isValidRegistryServer(%q) = %t, want %t", tc.server, got, tc.valid)
+		}
+	}
+}
+
+func TestDockerRegistryCredsConfig(t *testing.T) {
+	tests := []struct {
+		description   string
+		server, user  string
+		passwordStdin bool
+		stdin         string
+		password      string
+		err           bool
+	}{
+		{description: "ok", server: "https://registry.example.com", user: "me", passwordStdin: true, stdin: "s3cret\n", password: "s3cret"},
+		{description: "crlf", server: "registry.example.com", user: "me", passwordStdin: true, stdin: "s3cret\r\n", password: "s3cret"},
+		{description: "invalid server", server: "not a server", user: "me", passwordStdin: true, stdin: "s3cret", err: true},
+		{description: "missing user", server: "registry.example.com", passwordStdin: true, stdin: "s3cret", err: true},
+		{description: "missing stdin flag", server: "registry.example.com", user: "me", stdin: "s3cret", err: true},
+		{description: "empty password", server: "registry.example.com", user: "me", passwordStdin: true, stdin: "\n", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			c, err := dockerRegistryCredsConfig(tc.server, tc.user, tc.passwordStdin, strings.NewReader(tc.stdin))
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if err == nil && c.docker.password != tc.password {
+				t.Errorf("expected password %q but got %q", tc.password, c.docker.password)
+			}
+		})
+	}
+}
+
+func TestValidateAWSRoleARN(t *testing.T) {
+	tests := []struct {
+		arn   string
+		valid bool
+	}{
+		{"arn:aws:iam::123456789012:role/ecr-pull", true},
+		{"arn:aws:iam::123456789012:role/team/ecr-pull", true},
+		{"arn:aws-cn:iam::123456789012:role/ecr-pull", true},
+		{"arn:aws-us-gov:iam::123456789012:role/ecr_pull@example.com", true},
+		{"arn:aws:iam::123456789012:user/ecr-pull", false},
+		{"arn:aws:iam::12345678901:role/ecr-pull", false},
+		{"arn:aws:iam:us-east-1:123456789012:role/ecr-pull", false},
+		{"arn:aws:iam::123456789012:role/", false},
+		{"arn:aws:iam::123456789012:role/ecr pull", false},
+		{"123456789012:role/ecr-pull", false},
+	}
+	for _, tc := range tests {
+		if err := validateAWSRoleARN(tc.arn); (err == nil) != tc.valid {
+			t.Errorf("validateAWSRoleARN(%q) = %v, want valid %t", tc.arn, err, tc.valid)
+		}
+	}
+}
+
+func TestParseAWSAccounts(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{list: "123456789012", want: []string{"123456789012"}},
+		{list: "123456789012, 210987654321", want: []string{"123456789012", "210987654321"}},
+		{list: "12345678901"},
+		{list: "123456789012,"},
+		{list: "changeme"},
+	}
+	for _, tc := range tests {
+		got, err := parseAWSAccounts(tc.list)
+		if (err == nil) != (tc.want != nil) {
+			t.Errorf("parseAWSAccounts(%q) returned error %v, want valid %t", tc.list, err, tc.want != nil)
+			continue
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("parseAWSAccounts(%q) = %v, want %v", tc.list, got, tc.want)
+		}
+	}
+}
+
+func TestValidateECR(t *testing.T) {
+	tests := []struct {
+		description string
+		accessID    string
+		token       string
+		account     string
+		role        string
+		err         string
+	}{
+		{description: "long-term credentials", accessID: "AKIAEXAMPLE", account: "123456789012"},
+		{description: "temporary credentials", accessID: "ASIAEXAMPLE", token: "token", account: "123456789012"},
+		{description: "role in the accounts", accessID: "AKIAEXAMPLE", account: "210987654321,123456789012", role: "arn:aws:iam::123456789012:role/ecr-pull"},
+		{description: "invalid account", accessID: "AKIAEXAMPLE", account: "1234", err: "12 digit"},
+		{description: "long-term credentials with a token", accessID: "AKIAEXAMPLE", token: "token", account: "123456789012", err: "long-term"},
+		{description: "temporary credentials without a token", accessID: "ASIAEXAMPLE", account: "123456789012", err: "session token is required"},
+		{description: "role with a token", accessID: "ASIAEXAMPLE", token: "token", account: "123456789012", role: "arn:aws:iam::123456789012:role/ecr-pull", err: "not with a session token"},
+		{description: "role in another account", accessID: "AKIAEXAMPLE", account: "210987654321", role: "arn:aws:iam::123456789012:role/ecr-pull", err: "not one of the AWS account IDs"},
+		{description: "invalid role", accessID: "AKIAEXAMPLE", account: "123456789012", role: "changeme", err: "not the ARN of a role"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			c := defaultRegistryCredsConfig()
+			c.awsAccessID, c.awsSessionToken, c.awsAccount, c.awsRole = tc.accessID, tc.token, tc.account, tc.role
+			err := c.validateECR()
+			if tc.err == "" {
+				if err != nil {
+					t.Errorf("validateECR() returned unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("validateECR() = %v, want an error containing %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryCredsRefresh(t *testing.T) {
+	tests := []struct {
+		refresh time.Duration
+		valid   bool
+	}{
+		{time.Minute, true},
+		{30 * time.Minute, true},
+		{12 * time.Hour, true},
+		{30 * time.Second, false},
+		{90 * time.Second, false},
+		{13 * time.Hour, false},
+	}
+	for _, tc := range tests {
+		if err := validateRegistryCredsRefresh(tc.refresh); (err == nil) != tc.valid {
+			t.Errorf("validateRegistryCredsRefresh(%s) = %v, want valid %t", tc.refresh, err, tc.valid)
+		}
+	}
+}
+
+func TestRegistryCredsConfigValidate(t *testing.T) {
+	valid := defaultRegistryCredsConfig()
+	valid.awsRegion = "us-east-1"
+	valid.gcr = gcrRegistry{credentials: `{"type": "authorized_user"}`, url: "https://europe-docker.pkg.dev"}
+	valid.docker = dockerRegistry{server: "https://registry.example.com", user: "me", password: "s3cret"}
+
+	tests := []struct {
+		description string
+		update      func(c *registryCredsConfig)
+		err         bool
+	}{
+		{description: "placeholders", update: func(c *registryCredsConfig) { *c = defaultRegistryCredsConfig() }},
+		{description: "valid", update: func(_ *registryCredsConfig) {}},
+		{description: "invalid region", update: func(c *registryCredsConfig) { c.awsRegion = "moon-1" }, err: true},
+		{description: "no role", update: func(c *registryCredsConfig) { c.awsRole = "" }},
+		{description: "invalid role", update: func(c *registryCredsConfig) { c.awsRole = "ecr-pull" }, err: true},
+		{description: "role outside the accounts", update: func(c *registryCredsConfig) {
+			c.awsAccount, c.awsRole = "210987654321", "arn:aws:iam::123456789012:role/ecr-pull"
+		}, err: true},
+		{description: "invalid gcr credentials", update: func(c *registryCredsConfig) { c.gcr.credentials = "not json" }, err: true},
+		{description: "invalid gcr url", update: func(c *registryCredsConfig) { c.gcr.url = "not a url" }, err: true},
+		{description: "invalid docker server", update: func(c *registryCredsConfig) { c.docker.server = "not a server" }, err: true},
+		{description: "insecure docker registry", update: func(c *registryCredsConfig) { c.docker.insecure = true }},
+		{description: "invalid docker CA cert", update: func(c *registryCredsConfig) { c.docker.caCert = "not a cert" }, err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			c := valid
+			tc.update(&c)
+			if err := c.validate(); (err != nil) != tc.err {
+				t.Errorf("expected error %t but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestRedactedRegistryCredsData(t *testing.T) {
+	data := map[string]string{
+		"DOCKER_PRIVATE_REGISTRY_SERVER":   "https://registry.example.com",
+		"DOCKER_PRIVATE_REGISTRY_USER":     "admin",
+		"DOCKER_PRIVATE_REGISTRY_PASSWORD": "secret",
+	}
+	got := redactedRegistryCredsData(data, registryCredsPublicKeys["dpr"])
+	want := []string{
+		"DOCKER_PRIVATE_REGISTRY_PASSWORD: <redacted>",
+		"DOCKER_PRIVATE_REGISTRY_SERVER: https://registry.example.com",
+		"DOCKER_PRIVATE_REGISTRY_USER: admin",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactedRegistryCredsData() = %v, want %v", got, want)
+	}
+}
+
+func TestIsConfiguredRegistryCredsSecret(t *testing.T) {
+	if isConfiguredRegistryCredsSecret(nil) {
+		t.Errorf("a missing secret must not be configured")
+	}
+	for _, s := range defaultRegistryCredsConfig().secrets() {
+		if isConfiguredRegistryCredsSecret(s.data) {
+			t.Errorf("the placeholder %s secret must not be configured", s.name)
+		}
+	}
+	if !isConfiguredRegistryCredsSecret(map[string]string{"gcrurl": "https://gcr.io", "application_default_credentials.json": "{}"}) {
+		t.Errorf("expected the gcr secret to be configured")
+	}
+}
+
+func TestRegistryCredsSecretConfigured(t *testing.T) {
+	c := defaultRegistryCredsConfig()
+	for _, cloud := range []string{"ecr", "gcr", "acr", "dpr"} {
+		c.enable(cloud)
+	}
+	// enabled registries whose values were left as placeholders are not created either
+	for _, s := range c.secrets() {
+		if s.configured() {
+			t.Errorf("the placeholder %s secret must not be created", s.name)
+		}
+	}
+
+	c.gcr.credentials = "{}"
+	c.acrURL = "https://example.azurecr.io"
+	want := map[string]bool{"registry-creds-ecr": false, "registry-creds-gcr": true, "registry-creds-acr": true, "registry-creds-dpr": false}
+	for _, s := range c.secrets() {
+		if got := s.configured(); got != want[s.name] {
+			t.Errorf("%s configured() = %t, want %t", s.name, got, want[s.name])
+		}
+	}
+
+	delete(c.enabled, "gcr")
+	for _, s := range c.secrets() {
+		if s.name == "registry-creds-gcr" && s.configured() {
+			t.Errorf("the secret of a registry that is not enabled must not be created")
+		}
+	}
+}
+
+func TestParseAzureServicePrincipal(t *testing.T) {
+	tests := []struct {
+		data    string
+		missing string
+		err     bool
+	}{
+		{data: `{"clientId": "id", "clientSecret": "secret", "tenantId": "tenant", "subscriptionId": "sub"}`},
+		{data: `{"clientId": "id", "tenantId": "tenant"}`, missing: "clientSecret", err: true},
+		{data: `{}`, missing: "clientId, clientSecret, tenantId", err: true},
+		{data: `not json`, err: true},
+	}
+	for _, tc := range tests {
+		sp, err := parseAzureServicePrincipal([]byte(tc.data))
+		if (err != nil) != tc.err {
+			t.Errorf("parseAzureServicePrincipal(%s) error = %v, want error %t", tc.data, err, tc.err)
+			continue
+		}
+		if err != nil && tc.missing != "" && !strings.Contains(err.Error(), tc.missing) {
+			t.Errorf("parseAzureServicePrincipal(%s) error = %v, want missing %s", tc.data, err, tc.missing)
+		}
+		if err == nil && (sp.ClientID != "id" || sp.ClientSecret != "secret") {
+			t.Errorf("parseAzureServicePrincipal(%s) = %+v", tc.data, sp)
+		}
+	}
+}
+
+func TestIsUserCredentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		creds string
+		user  bool
+	}{
+		{"user ADC", `{"type": "authorized_user", "client_id": "id", "refresh_token": "token"}`, true},
+		{"refresh token without type", `{"client_id": "id", "refresh_token": "token"}`, true},
+		{"service account", `{"type": "service_account", "private_key": "key"}`, false},
+		{"invalid JSON", `not json`, false},
+	}
+	for _, tc := range tests {
+		if got := isUserCredentials([]byte(tc.creds)); got != tc.user {
+			t.Errorf("%s: isUserCredentials() = %t, want %t", tc.name, got, tc.user)
+		}
+	}
+}
+
+func TestACRValidators(t *testing.T) {
+	tests := []struct {
+		validator func(string) bool
+		name      string
+		input     string
+		valid     bool
+	}{
+		{isValidACRURL, "url", "myregistry.azurecr.io", true},
+		{isValidACRURL, "url", "https://myregistry.azurecr.cn/", true},
+		{isValidACRURL, "url", "my-registry.azurecr.io", false},
+		{isValidACRURL, "url", "myregistry.docker.io", false},
+		{isValidACRClientID, "client ID", "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d", true},
+		{isValidACRClientID, "client ID", "myregistry", false},
+		{isValidACRAdminUser, "admin user", "myregistry", true},
+		{isValidACRAdminUser, "admin user", "my-registry", false},
+		{isValidACRAdminUser, "admin user", "reg", false},
+		{isValidACRTokenName, "token name", "ci-pull", true},
+		{isValidACRTokenName, "token name", "ci pull", false},
+	}
+	for _, tc := range tests {
+		if got := tc.validator(tc.input); got != tc.valid {
+			t.Errorf("ACR %s %q valid = %t, want %t", tc.name, tc.input, got, tc.valid)
+		}
+	}
+}
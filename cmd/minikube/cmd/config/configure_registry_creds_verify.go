@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"golang.org/x/oauth2/google"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// registryCredsVerify makes registry-creds log in to the registries with the entered credentials before creating the secrets
+var registryCredsVerify bool
+
+// registryCredsVerifyTimeout bounds each login, so an unreachable registry fails the check instead of hanging
+const registryCredsVerifyTimeout = 30 * time.Second
+
+// gcrScope is the OAuth scope the registry-creds controller requests for GCR
+const gcrScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// registryTransport returns the transport used to log in to a registry, trusting its PEM CA cert if any,
+// or skipping the verification of its cert if insecure is set
+func registryTransport(caCert string, insecure bool) (http.RoundTripper, error) {
+	if caCert == "" && !insecure {
+		return http.DefaultTransport, nil
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure} //nolint:gosec // the user asked not to verify the cert of the registry, as the container runtime will not
+	if caCert != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("no PEM certificate found in the CA cert")
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// verifyDockerRegistryLogin logs in to a docker registry through the transport, going through the token exchange
+// when the registry asks for one
+func verifyDockerRegistryLogin(ctx context.Context, tr http.RoundTripper, server, user, password string) error {
+	host := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://"), "/")
+	reg, err := name.NewRegistry(host)
+	if err != nil {
+		return fmt.Errorf("invalid registry %q: %w", server, err)
+	}
+	auth := &authn.Basic{Username: user, Password: password}
+	rt, err := transport.NewWithContext(ctx, reg, auth, tr, []string{reg.Scope(transport.PullScope)})
+	if err != nil {
+		return fmt.Errorf("login to %s: %w", host, err)
+	}
+	// a registry using basic auth only checks the credentials on the first request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reg.Scheme()+"://"+reg.RegistryStr()+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return fmt.Errorf("login to %s: %w", host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("login to %s: the username or password is wrong (%s)", host, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login to %s: unexpected response %s", host, resp.Status)
+	}
+	return nil
+}
+
+// verifyGCRCredentials exchanges the credentials for an access token, as the registry-creds controller does
+func verifyGCRCredentials(ctx context.Context, data []byte) error {
+	creds, err := google.CredentialsFromJSON(ctx, data, gcrScope)
+	if err != nil {
+		return err
+	}
+	if _, err := creds.TokenSource.Token(); err != nil {
+		return fmt.Errorf("getting an access token: %w", err)
+	}
+	return nil
+}
+
+// verifyECRCredentials asks ECR for an authorization token, assuming the role of the config if there is one
+func verifyECRCredentials(ctx context.Context, c registryCredsConfig) error {
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(c.awsRegion),
+		Credentials: credentials.NewStaticCredentials(c.awsAccessID, c.awsAccessKey, c.awsSessionToken),
+	})
+	if err != nil {
+		return err
+	}
+	var cfgs []*aws.Config
+	if c.awsRole != "" {
+		cfgs = append(cfgs, &aws.Config{Credentials: stscreds.NewCredentials(sess, c.awsRole)})
+	}
+	if _, err := ecr.New(sess, cfgs...).GetAuthorizationTokenWithContext(ctx, &ecr.GetAuthorizationTokenInput{}); err != nil {
+		return fmt.Errorf("getting an ECR authorization token: %w", err)
+	}
+	return nil
+}
+
+// reenterAfterFailedCheck checks the credentials of the registry when --verify-credentials is set,
+// and returns true if they were rejected and the user wants to enter them again
+func reenterAfterFailedCheck(registry string, check func(ctx context.Context) error) bool {
+	if !registryCredsVerify {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(configureCtx, registryCredsVerifyTimeout)
+	defer cancel()
+	if err := check(ctx); err != nil {
+		out.WarningT("The {{.registry}} credentials could not be verified: {{.error}}", out.V{"registry": registry, "error": err})
+		return AskForYesNoConfirmation("-- Do you want to enter them again?", posResponses, negResponses)
+	}
+	out.Styled(style.Check, "The {{.registry}} credentials are valid", out.V{"registry": registry})
+	return false
+}
+
+// verifyDockerRegistry logs in to a docker registry with its credentials, trusting its CA cert
+func verifyDockerRegistry(ctx context.Context, r dockerRegistry) error {
+	tr, err := registryTransport(r.caCert, r.insecure)
+	if err != nil {
+		return err
+	}
+	return verifyDockerRegistryLogin(ctx, tr, r.server, r.user, r.password)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestRegistry returns a registry accepting the user and password, either directly with basic auth
+// or by exchanging them for a bearer token
+func newTestRegistry(t *testing.T, bearer bool) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"token": "registry-token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if bearer {
+			if r.Header.Get("Authorization") == "Bearer registry-token" {
+				return
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if u, p, ok := r.BasicAuth(); ok && u == "user" && p == "password" {
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifyDockerRegistryLogin(t *testing.T) {
+	tests := []struct {
+		description string
+		bearer      bool
+		password    string
+		valid       bool
+	}{
+		{description: "basic auth", password: "password", valid: true},
+		{description: "basic auth with a wrong password", password: "wrong"},
+		{description: "token exchange", bearer: true, password: "password", valid: true},
+		{description: "token exchange with a wrong password", bearer: true, password: "wrong"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			server := newTestRegistry(t, tc.bearer)
+			host := strings.TrimPrefix(server.URL, "http://")
+
+			err := verifyDockerRegistryLogin(context.Background(), http.DefaultTransport, host, "user", tc.password)
+			if (err == nil) != tc.valid {
+				t.Errorf("verifyDockerRegistryLogin() = %v, want valid %t", err, tc.valid)
+			}
+		})
+	}
+}
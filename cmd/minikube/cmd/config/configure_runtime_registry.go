@@ -0,0 +1,286 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+var (
+	// registryCredsRuntimeConfig is set by --runtime-registry-config to also write the registry config of the container runtime of the nodes
+	registryCredsRuntimeConfig bool
+	// registryCredsDockerCACert is the path of the PEM CA cert of the registry given with --docker-server
+	registryCredsDockerCACert string
+	// registryCredsDockerInsecure skips the verification of the cert of the registry given with --docker-server
+	registryCredsDockerInsecure bool
+)
+
+const (
+	// runtimeRegistryMarker starts the registry config files written by configure
+	runtimeRegistryMarker = "# written by minikube addons configure registry-creds"
+	// containerdCertsDir holds the hosts.toml of each registry, read by containerd on each pull
+	containerdCertsDir = "/etc/containerd/certs.d"
+	// crioRegistriesFile holds the registries of CRI-O written by configure, it is replaced on each run
+	crioRegistriesFile = "/etc/containers/registries.conf.d/99-minikube-registry-creds.conf"
+	// crioCertsDir holds the CA cert of each registry, read by CRI-O on each pull
+	crioCertsDir = "/etc/containers/certs.d"
+	// dockerCertsDir holds the CA cert of each registry, read by dockerd on each pull
+	dockerCertsDir = "/etc/docker/certs.d"
+)
+
+// runtimeRegistry is the config of a docker registry in the container runtime of the nodes
+type runtimeRegistry struct {
+	host string
+	// skipVerify skips the verification of the TLS cert of the registry, eg. a self-signed cert
+	skipVerify bool
+	// ca is the PEM CA cert the registry cert is signed with
+	ca string
+	// mirrors are the hosts pulled from before the registry
+	mirrors []string
+}
+
+// registryHost returns the host, with its port, of a registry server given as a host or a URL, eg. "https://registry.dev:5000/v2/"
+func registryHost(server string) (string, error) {
+	s := server
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid registry %q", server)
+	}
+	host := strings.ToLower(u.Host)
+	// the runtimes name Docker Hub docker.io, whatever host the credentials were given for
+	if host == "index.docker.io" || host == "registry-1.docker.io" {
+		return "docker.io", nil
+	}
+	return host, nil
+}
+
+// parseRegistryMirrors parses the mirrors of a registry separated by spaces, given as hosts or URLs
+func parseRegistryMirrors(s string) ([]string, error) {
+	var mirrors []string
+	for _, m := range strings.Fields(s) {
+		host, err := registryHost(m)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, host)
+	}
+	return mirrors, nil
+}
+
+// parseCACert checks the PEM data holds at least one CA cert, and nothing but certs
+func parseCACert(data []byte) error {
+	n := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block %q, only CERTIFICATE blocks are allowed", block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return fmt.Errorf("invalid certificate: %w", err)
+		}
+		n++
+	}
+	if n == 0 {
+		return fmt.Errorf("no PEM certificate found")
+	}
+	if strings.TrimSpace(string(data)) != "" {
+		return fmt.Errorf("unexpected data after the PEM certificates")
+	}
+	return nil
+}
+
+// readCACertFile reads the PEM CA cert of a registry from a local file
+func readCACertFile(path string) (string, error) {
+	data, err := readTextFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := parseCACert(data); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// checkRuntimeRegistrySupported checks the container runtime of the profile has a registry config configure can write
+func checkRuntimeRegistrySupported(runtime string) error {
+	if runtime != constants.Containerd && runtime != constants.CRIO {
+		return fmt.Errorf("--runtime-registry-config supports the containerd and cri-o container runtimes, not %s", runtime)
+	}
+	return nil
+}
+
+// readRuntimeRegistries returns the runtime config of the docker registry, prompting for its mirrors if prompt is set
+func readRuntimeRegistries(d dockerRegistry, prompt bool) ([]runtimeRegistry, error) {
+	if d.server == "" || d.server == registryCredsPlaceholder {
+		return nil, fmt.Errorf("--runtime-registry-config requires a docker registry")
+	}
+	r, err := newRuntimeRegistry(d)
+	if err != nil {
+		return nil, err
+	}
+	if prompt {
+		mirrors := AskForStaticCheckedValueOptional("-- Enter the mirrors of "+r.host+" separated by spaces, or leave empty: ", func(s string) error {
+			_, err := parseRegistryMirrors(s)
+			return err
+		})
+		r.mirrors, _ = parseRegistryMirrors(mirrors)
+	}
+	return []runtimeRegistry{r}, nil
+}
+
+// tlsRuntimeRegistries returns the runtime config of the docker registry if it has a CA cert or skips the verification
+// of its cert, the runtime must trust it to pull its images
+func tlsRuntimeRegistries(d dockerRegistry) ([]runtimeRegistry, error) {
+	if d.caCert == "" && !d.insecure {
+		return nil, nil
+	}
+	r, err := newRuntimeRegistry(d)
+	if err != nil {
+		return nil, err
+	}
+	return []runtimeRegistry{r}, nil
+}
+
+// newRuntimeRegistry returns the runtime config of a docker registry, without mirrors
+func newRuntimeRegistry(d dockerRegistry) (runtimeRegistry, error) {
+	host, err := registryHost(d.server)
+	if err != nil {
+		return runtimeRegistry{}, err
+	}
+	return runtimeRegistry{host: host, skipVerify: d.insecure, ca: d.caCert}, nil
+}
+
+// containerdServer returns the URL containerd pulls the images of the host from
+func containerdServer(host string) string {
+	if host == "docker.io" {
+		return "https://registry-1.docker.io"
+	}
+	return "https://" + host
+}
+
+// containerdHostsToml returns the hosts.toml of the registry, see https://github.com/containerd/containerd/blob/main/docs/hosts.md
+func containerdHostsToml(r runtimeRegistry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nserver = %q\n", runtimeRegistryMarker, containerdServer(r.host))
+	for _, m := range r.mirrors {
+		fmt.Fprintf(&b, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", containerdServer(m))
+	}
+	if r.skipVerify || r.ca != "" {
+		fmt.Fprintf(&b, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\", \"push\"]\n", containerdServer(r.host))
+	}
+	if r.skipVerify {
+		b.WriteString("  skip_verify = true\n")
+	}
+	if r.ca != "" {
+		fmt.Fprintf(&b, "  ca = %q\n", path.Join(containerdCertsDir, r.host, "ca.crt"))
+	}
+	return b.String()
+}
+
+// crioRegistriesConf returns the registries.conf drop-in of the registries, see containers-registries.conf(5)
+func crioRegistriesConf(rs []runtimeRegistry) string {
+	var b strings.Builder
+	b.WriteString(runtimeRegistryMarker + "\n")
+	for _, r := range rs {
+		fmt.Fprintf(&b, "\n[[registry]]\nprefix = %q\nlocation = %q\ninsecure = %t\n", r.host, r.host, r.skipVerify)
+		for _, m := range r.mirrors {
+			fmt.Fprintf(&b, "\n[[registry.mirror]]\nlocation = %q\n", m)
+		}
+	}
+	return b.String()
+}
+
+// runtimeRegistryFiles returns the registry config files of the runtime by path, and the service to restart to read them.
+// dockerd only reads the CA certs of the registries, on each pull, so it is not restarted.
+func runtimeRegistryFiles(runtime string, rs []runtimeRegistry) (map[string]string, string, error) {
+	files := map[string]string{}
+	switch runtime {
+	case constants.Docker:
+		for _, r := range rs {
+			if r.skipVerify || len(r.mirrors) > 0 {
+				return nil, "", fmt.Errorf("the docker container runtime only supports the CA cert of a registry, use containerd or cri-o to skip the verification of %s or use its mirrors", r.host)
+			}
+			files[path.Join(dockerCertsDir, r.host, "ca.crt")] = r.ca
+		}
+		return files, "", nil
+	case constants.CRIO:
+		files[crioRegistriesFile] = crioRegistriesConf(rs)
+		for _, r := range rs {
+			if r.ca != "" {
+				files[path.Join(crioCertsDir, r.host, "ca.crt")] = r.ca
+			}
+		}
+		return files, "crio", nil
+	case constants.Containerd:
+		for _, r := range rs {
+			files[path.Join(containerdCertsDir, r.host, "hosts.toml")] = containerdHostsToml(r)
+			if r.ca != "" {
+				files[path.Join(containerdCertsDir, r.host, "ca.crt")] = r.ca
+			}
+		}
+		return files, "containerd", nil
+	}
+	return nil, "", fmt.Errorf("the registry config of the %s container runtime can not be written", runtime)
+}
+
+// checkRuntimeRegistryFiles checks the registry config of the container runtime of the profile can be written
+func checkRuntimeRegistryFiles(profile string, rs []runtimeRegistry) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	_, _, err = runtimeRegistryFiles(cfg.KubernetesConfig.ContainerRuntime, rs)
+	return err
+}
+
+// applyRuntimeRegistryConfig writes the registry config of the container runtime on the nodes, then restarts the runtime if it
+// has to read them
+func applyRuntimeRegistryConfig(profile string, rs []runtimeRegistry) error {
+	cfg, err := config.Load(profile)
+	if err != nil {
+		return fmt.Errorf("load config %s: %w", profile, err)
+	}
+	runtime := cfg.KubernetesConfig.ContainerRuntime
+	files, service, err := runtimeRegistryFiles(runtime, rs)
+	if err != nil {
+		return err
+	}
+	if err := configureClient.WriteNodeFiles(cfg, files, service); err != nil {
+		return fmt.Errorf("write the registry config of %s: %w", runtime, err)
+	}
+	if service == "" {
+		recordApplied("wrote the registry config of " + runtime + " on the nodes")
+		return nil
+	}
+	recordApplied("wrote the registry config of " + runtime + " on the nodes and restarted it")
+	return nil
+}
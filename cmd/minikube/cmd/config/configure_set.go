@@ -0,0 +1,338 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/mustload"
+)
+
+// addonConfigKey is a profile setting of an addon which can be set with --set key=value
+type addonConfigKey struct {
+	// kind is the type of the value, eg. quantity or duration, listed by --list
+	kind string
+	// description tells what the key sets and which values are valid, listed by --list
+	description string
+	// validate checks the value before any setting of the addon is changed
+	validate func(value string) error
+	// set stores the validated value on the profile
+	set func(cc *config.ClusterConfig, value string)
+	// get returns the current value on the profile, as it would be given to set
+	get func(cc *config.ClusterConfig) string
+}
+
+// addonConfigKeys lists the keys accepted by --set for each addon
+var addonConfigKeys = map[string]map[string]addonConfigKey{
+	"metallb": {
+		"range": {
+			kind:        "ip-range",
+			description: "Load balancer IP range, formatted as START-END",
+			validate: func(v string) error {
+				_, _, err := parseLoadBalancerRange(v)
+				return err
+			},
+			set: func(cc *config.ClusterConfig, v string) {
+				startIP, endIP, _ := parseLoadBalancerRange(v)
+				// only the range changes, the BGP peers are kept
+				cc.MetalLB.Pools = config.DefaultMetalLBConfig(startIP, endIP).Pools
+			},
+			get: func(cc *config.ClusterConfig) string {
+				if len(cc.MetalLB.Pools) == 0 || len(cc.MetalLB.Pools[0].Ranges) == 0 {
+					return ""
+				}
+				return cc.MetalLB.Pools[0].Ranges[0]
+			},
+		},
+		"controller-cpu-request":    metallbResourceKey("CPU request of the metallb controller", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Controller.CPURequest }),
+		"controller-memory-request": metallbResourceKey("Memory request of the metallb controller", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Controller.MemoryRequest }),
+		"controller-cpu-limit":      metallbResourceKey("CPU limit of the metallb controller, at least its request", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Controller.CPULimit }),
+		"controller-memory-limit":   metallbResourceKey("Memory limit of the metallb controller, at least its request", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Controller.MemoryLimit }),
+		"speaker-cpu-request":       metallbResourceKey("CPU request of the metallb speaker", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Speaker.CPURequest }),
+		"speaker-memory-request":    metallbResourceKey("Memory request of the metallb speaker", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Speaker.MemoryRequest }),
+		"speaker-cpu-limit":         metallbResourceKey("CPU limit of the metallb speaker, at least its request", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Speaker.CPULimit }),
+		"speaker-memory-limit":      metallbResourceKey("Memory limit of the metallb speaker, at least its request", func(cc *config.ClusterConfig) *string { return &cc.MetalLB.Speaker.MemoryLimit }),
+	},
+	"ingress": {
+		"cert": {
+			kind:        "namespaced-name",
+			description: "Default TLS certificate of the ingress controller, as the NAMESPACE/NAME of a TLS secret",
+			validate:    validateNamespacedName,
+			set: func(cc *config.ClusterConfig, v string) {
+				cc.KubernetesConfig.CustomIngressCert = v
+				cc.KubernetesConfig.CustomIngressController = ""
+			},
+			get: func(cc *config.ClusterConfig) string { return cc.KubernetesConfig.CustomIngressCert },
+		},
+		"default-backend": {
+			kind:        "service",
+			description: "Service serving the requests matching no ingress rule, as NAMESPACE/NAME:PORT where PORT is its first port",
+			validate:    validateIngressBackend,
+			set:         func(cc *config.ClusterConfig, v string) { cc.KubernetesConfig.CustomIngressBackend = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.KubernetesConfig.CustomIngressBackend },
+		},
+	},
+	"registry-aliases": {
+		"aliases": {
+			kind:        "list",
+			description: "Comma separated aliases of the registry addon, each a valid host name",
+			validate:    validateRegistryAliases,
+			set: func(cc *config.ClusterConfig, v string) {
+				cc.KubernetesConfig.RegistryAliases, _ = registryAliasHosts(v)
+			},
+			get: func(cc *config.ClusterConfig) string { return cc.KubernetesConfig.RegistryAliases },
+		},
+	},
+	"efk": {
+		"endpoint": {
+			kind:        "url",
+			description: "http or https URL of an external Elasticsearch the logs are sent to instead",
+			validate:    validateLoggingEndpoint,
+			set:         func(cc *config.ClusterConfig, v string) { cc.Logging.Endpoint = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.Logging.Endpoint },
+		},
+	},
+	"gcp-auth": {
+		"webhook-image": {
+			kind:        "image",
+			description: "Image reference of the gcp-auth webhook",
+			validate:    validateImageReference,
+			set:         func(cc *config.ClusterConfig, v string) { setCustomAddonImage(cc, "GCPAuthWebhook", v) },
+			get:         func(cc *config.ClusterConfig) string { return currentAddonImage(cc, "gcp-auth", "GCPAuthWebhook") },
+		},
+		"certgen-image": {
+			kind:        "image",
+			description: "Image reference of the webhook certgen job",
+			validate:    validateImageReference,
+			set:         func(cc *config.ClusterConfig, v string) { setCustomAddonImage(cc, "KubeWebhookCertgen", v) },
+			get:         func(cc *config.ClusterConfig) string { return currentAddonImage(cc, "gcp-auth", "KubeWebhookCertgen") },
+		},
+	},
+	"auto-pause": {
+		"interval": {
+			kind:        "duration",
+			description: "Time without activity after which the cluster is paused",
+			validate: func(v string) error {
+				_, err := time.ParseDuration(v)
+				return err
+			},
+			set: func(cc *config.ClusterConfig, v string) {
+				cc.AutoPauseInterval, _ = time.ParseDuration(v)
+			},
+			get: func(cc *config.ClusterConfig) string { return cc.AutoPauseInterval.String() },
+		},
+		"components": {
+			kind:        "list",
+			description: "Comma separated components paused by auto-pause, including kube-controller-manager or kube-apiserver as the kubelet is always stopped with them",
+			validate: func(v string) error {
+				_, err := parseAutoPauseComponents(v)
+				return err
+			},
+			set: func(cc *config.ClusterConfig, v string) {
+				cc.AutoPauseComponents, _ = parseAutoPauseComponents(v)
+			},
+			get: func(cc *config.ClusterConfig) string { return strings.Join(cc.AutoPauseComponents, ",") },
+		},
+		"warmup": {
+			kind:        "duration",
+			description: "Minimum time the cluster is kept running after it starts before it is first paused, 0s for none",
+			validate:    validateAutoPauseWarmup,
+			set: func(cc *config.ClusterConfig, v string) {
+				cc.AutoPauseWarmup, _ = time.ParseDuration(v)
+			},
+			get: func(cc *config.ClusterConfig) string { return cc.AutoPauseWarmup.String() },
+		},
+	},
+	"cloud-spanner": {
+		"grpc-port": cloudSpannerPortKey("Port of the gRPC API of the emulator, 9010 if empty", func(cc *config.ClusterConfig) *int { return &cc.CloudSpanner.GRPCPort }),
+		"http-port": cloudSpannerPortKey("Port of the REST API of the emulator, 9020 if empty", func(cc *config.ClusterConfig) *int { return &cc.CloudSpanner.HTTPPort }),
+		"project": {
+			kind:        "name",
+			description: "Default project of the clients, given in the cloud-spanner-emulator ConfigMap",
+			validate:    validateGCPProjectID,
+			set:         func(cc *config.ClusterConfig, v string) { cc.CloudSpanner.Project = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.CloudSpanner.Project },
+		},
+		"instance": {
+			kind:        "name",
+			description: "Default instance of the clients, given in the cloud-spanner-emulator ConfigMap",
+			validate:    validateSpannerInstanceID,
+			set:         func(cc *config.ClusterConfig, v string) { cc.CloudSpanner.Instance = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.CloudSpanner.Instance },
+		},
+	},
+	"metrics-server": {
+		"cpu-request": {
+			kind:        "quantity",
+			description: "CPU request of metrics-server",
+			validate:    func(v string) error { return IsValidQuantity("", v) },
+			set:         func(cc *config.ClusterConfig, v string) { cc.MetricsServer.CPURequest = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.MetricsServer.CPURequest },
+		},
+		"memory-request": {
+			kind:        "quantity",
+			description: "Memory request of metrics-server",
+			validate:    func(v string) error { return IsValidQuantity("", v) },
+			set:         func(cc *config.ClusterConfig, v string) { cc.MetricsServer.MemoryRequest = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.MetricsServer.MemoryRequest },
+		},
+		"cpu-limit": {
+			kind:        "quantity",
+			description: "CPU limit of metrics-server, at least its request",
+			validate:    func(v string) error { return IsValidQuantity("", v) },
+			set:         func(cc *config.ClusterConfig, v string) { cc.MetricsServer.CPULimit = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.MetricsServer.CPULimit },
+		},
+		"memory-limit": {
+			kind:        "quantity",
+			description: "Memory limit of metrics-server, at least its request",
+			validate:    func(v string) error { return IsValidQuantity("", v) },
+			set:         func(cc *config.ClusterConfig, v string) { cc.MetricsServer.MemoryLimit = v },
+			get:         func(cc *config.ClusterConfig) string { return cc.MetricsServer.MemoryLimit },
+		},
+		"metric-resolution": {
+			kind:        "duration",
+			description: "Scrape interval of metrics-server, at least 10s",
+			validate: func(v string) error {
+				// metrics-server refuses to start with a metric resolution below 10s
+				d, err := time.ParseDuration(v)
+				if err == nil && d < 10*time.Second {
+					return fmt.Errorf("must be at least 10s")
+				}
+				return err
+			},
+			set: func(cc *config.ClusterConfig, v string) { cc.MetricsServer.MetricResolution, _ = time.ParseDuration(v) },
+			get: func(cc *config.ClusterConfig) string { return cc.MetricsServer.MetricResolution.String() },
+		},
+	},
+	"registry-creds": {
+		"refresh-interval": {
+			kind:        "duration",
+			description: "How often the controller refreshes the credentials, in whole minutes from 1m to 12h",
+			validate: func(v string) error {
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					return err
+				}
+				return validateRegistryCredsRefresh(d)
+			},
+			set: func(cc *config.ClusterConfig, v string) { cc.RegistryCredsRefresh, _ = time.ParseDuration(v) },
+			get: func(cc *config.ClusterConfig) string { return cc.RegistryCredsRefresh.String() },
+		},
+	},
+	"dashboard": {
+		"token-auth": {
+			kind:        "bool",
+			description: "Require a token to log in to the dashboard instead of allowing to skip the login",
+			validate: func(v string) error {
+				_, err := strconv.ParseBool(v)
+				return err
+			},
+			set: func(cc *config.ClusterConfig, v string) { cc.DashboardTokenAuth, _ = strconv.ParseBool(v) },
+			get: func(cc *config.ClusterConfig) string { return strconv.FormatBool(cc.DashboardTokenAuth) },
+		},
+	},
+}
+
+// parseAddonConfigSet parses the --set key=value pairs of the addon, checking every key and value
+// so that nothing is changed unless all of them are valid
+func parseAddonConfigSet(addon string, pairs []string) (map[string]string, error) {
+	keys, ok := addonConfigKeys[addon]
+	if !ok {
+		return nil, fmt.Errorf("%s has no settings which can be changed with --set", addon)
+	}
+	values := map[string]string{}
+	for _, p := range pairs {
+		k, v, err := parseAddonConfigPair(addon, keys, p)
+		if err != nil {
+			return nil, err
+		}
+		values[k] = v
+	}
+	return values, nil
+}
+
+// parseAddonConfigPair parses and checks a single --set key=value pair of the addon
+func parseAddonConfigPair(addon string, keys map[string]addonConfigKey, pair string) (string, string, error) {
+	k, v, found := strings.Cut(pair, "=")
+	if !found {
+		return "", "", fmt.Errorf("%q is not a key=value pair", pair)
+	}
+	k = strings.TrimSpace(k)
+	key, ok := keys[k]
+	if !ok {
+		return "", "", fmt.Errorf("unknown key %q for %s, expected one of %s", k, addon, strings.Join(addonConfigKeyNames(addon), ", "))
+	}
+	v, err := resolveFileValue(v)
+	if err != nil {
+		return "", "", fmt.Errorf("%s: %w", k, err)
+	}
+	if err := key.validate(v); err != nil {
+		return "", "", fmt.Errorf("%s: %w", k, err)
+	}
+	return k, v, nil
+}
+
+// addonConfigKeyNames returns the sorted keys accepted by --set for the addon
+func addonConfigKeyNames(addon string) []string {
+	var names []string
+	for k := range addonConfigKeys[addon] {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setAddonConfig applies the --set key=value pairs to the profile, then re-enables the addon if it is enabled
+// so its manifests are generated with the new values
+func setAddonConfig(profile, addon string, pairs []string) {
+	values, err := parseAddonConfigSet(addon, pairs)
+	if err != nil {
+		exitConfigure("invalid configuration", classifyError(ErrInvalidInput, err))
+	}
+	if err := applyAddonConfigValues(profile, addon, values, false); err != nil {
+		exitConfigure("failed to configure the addon", err)
+	}
+}
+
+// applyAddonConfigValues stores the validated values of the --set keys on the profile, then enables the addon
+// if asked to or re-enables it if it is already enabled
+func applyAddonConfigValues(profile, addon string, values map[string]string, enable bool) error {
+	_, cfg := mustload.Partial(profile)
+	if len(values) == 0 {
+		if enable || assets.Addons[addon].IsEnabled(cfg) {
+			return configureClient.EnableAddon(cfg, addon)
+		}
+		return nil
+	}
+	for k, v := range values {
+		addonConfigKeys[addon][k].set(cfg, v)
+	}
+	// the ports of the emulator are set one key at a time, they may only collide once both are set
+	if addon == "cloud-spanner" {
+		if err := validateCloudSpannerPorts(cfg.CloudSpanner); err != nil {
+			return classifyError(ErrInvalidInput, err)
+		}
+	}
+	return saveAndReenableAddon(profile, cfg, addon, enable)
+}
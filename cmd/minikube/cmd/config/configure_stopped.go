@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/docker/machine/libmachine/state"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// profileOnlyAddons lists the addons whose configure flow only changes the profile. They can be configured
+// while the cluster is stopped, the addon is then applied with the new config on the next start.
+var profileOnlyAddons = map[string]bool{
+	"metallb":          true,
+	"registry-aliases": true,
+	"auto-pause":       true,
+	"metrics-server":   true,
+	"cloud-spanner":    true,
+}
+
+// controlPlaneState returns the state of the primary control plane of the profile, it is replaced by the tests
+var controlPlaneState = func(profile string) (string, error) {
+	cc, err := config.Load(profile)
+	if err != nil {
+		return "", err
+	}
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return "", err
+	}
+	defer api.Close()
+	cp, err := config.PrimaryControlPlane(cc)
+	if err != nil {
+		return "", err
+	}
+	return machine.Status(api, config.MachineName(*cc, cp))
+}
+
+// needsRunningCluster returns whether configuring the addon changes the cluster and not only the profile.
+// --set and --edit only change the profile whatever the addon.
+func needsRunningCluster(addon string) bool {
+	return !profileOnlyAddons[addon] && !configureEdit && len(configureSet) == 0
+}
+
+// checkClusterRunning returns whether the cluster is running. If it is not, it exits when configuring
+// the addon needs a running cluster, else it tells the configuration is applied on the next start.
+func checkClusterRunning(profile, addon string) bool {
+	st, err := controlPlaneState(profile)
+	if err != nil {
+		// let the flow report the error once it reaches the cluster
+		klog.Warningf("unable to get the state of %s: %v", profile, err)
+		return true
+	}
+	if st == state.Running.String() {
+		return true
+	}
+	if needsRunningCluster(addon) {
+		out.Styled(style.Shrug, `Configuring {{.name}} changes the cluster, which must be running (state={{.state}})`, out.V{"name": addon, "state": st})
+		out.Styled(style.Workaround, `To start a cluster, run: "{{.command}}"`, out.V{"command": mustload.ExampleCmd(profile, "start")})
+		exit.Code(reason.ExGuestUnavailable)
+	}
+	out.Styled(style.Notice, "The {{.profile}} cluster is not running, the configuration of {{.name}} will be applied on the next start", out.V{"profile": profile, "name": addon})
+	return false
+}
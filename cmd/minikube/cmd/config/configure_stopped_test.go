@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+func TestNeedsRunningCluster(t *testing.T) {
+	tests := []struct {
+		addon string
+		edit  bool
+		set   []string
+		want  bool
+	}{
+		{addon: "metallb"},
+		{addon: "registry-aliases"},
+		{addon: "auto-pause"},
+		{addon: "registry-creds", want: true},
+		{addon: "gcp-auth", want: true},
+		{addon: namespaceDefaultsTarget, want: true},
+		{addon: nodeDefaultsTarget, want: true},
+		{addon: "registry-creds", edit: true},
+		{addon: "ingress", set: []string{"cert=default/tls"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.addon, func(t *testing.T) {
+			oldEdit, oldSet := configureEdit, configureSet
+			defer func() { configureEdit, configureSet = oldEdit, oldSet }()
+			configureEdit, configureSet = tc.edit, tc.set
+
+			if got := needsRunningCluster(tc.addon); got != tc.want {
+				t.Errorf("needsRunningCluster(%q) = %v, want %v", tc.addon, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCheckClusterRunning(t *testing.T) {
+	tests := []struct {
+		description string
+		state       string
+		err         error
+		want        bool
+	}{
+		{description: "running", state: state.Running.String(), want: true},
+		{description: "stopped", state: state.Stopped.String()},
+		{description: "unknown state", err: fmt.Errorf("no such machine"), want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			oldState := controlPlaneState
+			defer func() { controlPlaneState = oldState }()
+			controlPlaneState = func(string) (string, error) { return tc.state, tc.err }
+
+			if got := checkClusterRunning("p1", "metallb"); got != tc.want {
+				t.Errorf("checkClusterRunning() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
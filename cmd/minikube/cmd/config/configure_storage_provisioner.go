@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/mustload"
+)
+
+// defaultStorageClass is the StorageClass of the default-storageclass addon
+const defaultStorageClass = "standard"
+
+// reclaimPolicies are the reclaim policies accepted for the default StorageClass
+var reclaimPolicies = []string{"Retain", "Delete"}
+
+// reclaimPolicy returns the reclaim policy matching s ignoring case, or an empty string if there is none
+func reclaimPolicy(s string) string {
+	if i := answerIndex(reclaimPolicies, s); i != -1 {
+		return reclaimPolicies[i]
+	}
+	return ""
+}
+
+// isValidHostPath checks if the path is an absolute path of the node, which is always a linux path
+func isValidHostPath(p string) bool {
+	return path.IsAbs(p) && !strings.ContainsAny(p, " \t")
+}
+
+func processStorageProvisionerConfig(profile string) {
+	_, cfg := mustload.Partial(profile)
+
+	policy := AskForStaticValidatedValue("-- Enter the reclaim policy of the default StorageClass (Retain or Delete): ", func(s string) bool {
+		return reclaimPolicy(s) != ""
+	})
+	hostPath := AskForStaticValidatedValue("-- Enter the absolute path of the node directory the volumes are provisioned in (ex. /tmp/hostpath-provisioner): ", isValidHostPath)
+
+	policyChanged := reclaimPolicy(policy) != cfg.StorageProvisioner.ReclaimPolicy
+	pathChanged := path.Clean(hostPath) != cfg.StorageProvisioner.HostPath
+	cfg.StorageProvisioner.ReclaimPolicy = reclaimPolicy(policy)
+	cfg.StorageProvisioner.HostPath = path.Clean(hostPath)
+
+	if err := saveProfileWithBackup(profile, cfg); err != nil {
+		exitConfigure("failed to save config "+profile, err)
+	}
+
+	// The spec of the storage-provisioner pod and the reclaim policy of a StorageClass cannot be updated,
+	// they are deleted before re-enabling the addons recreates them. The existing volumes are left as they are.
+	ctx, cancel := clusterContext()
+	defer cancel()
+	if pathChanged && assets.Addons["storage-provisioner"].IsEnabled(cfg) {
+		if err := deleteStorageProvisionerPod(ctx, profile); err != nil {
+			exitConfigure("failed to delete the storage-provisioner pod", err)
+		}
+		if err := configureClient.EnableAddon(cfg, "storage-provisioner"); err != nil {
+			exitConfigure("failed to configure storage-provisioner", err)
+		}
+	}
+	if policyChanged && assets.Addons["default-storageclass"].IsEnabled(cfg) {
+		if err := deleteDefaultStorageClass(ctx, profile); err != nil {
+			exitConfigure("failed to delete the default StorageClass", err)
+		}
+		if err := configureClient.EnableAddon(cfg, "default-storageclass"); err != nil {
+			exitConfigure("failed to configure default-storageclass", err)
+		}
+	}
+}
+
+// deleteStorageProvisionerPod deletes the storage-provisioner pod, if it exists
+func deleteStorageProvisionerPod(ctx context.Context, profile string) error {
+	client, err := kapi.Client(profile)
+	if err != nil {
+		return err
+	}
+	if err := client.CoreV1().Pods("kube-system").Delete(ctx, "storage-provisioner", meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	recordApplied("deleted the kube-system/storage-provisioner pod to recreate it")
+	return nil
+}
+
+// deleteDefaultStorageClass deletes the StorageClass of the default-storageclass addon, if it exists
+func deleteDefaultStorageClass(ctx context.Context, profile string) error {
+	client, err := kapi.Client(profile)
+	if err != nil {
+		return err
+	}
+	if err := client.StorageV1().StorageClasses().Delete(ctx, defaultStorageClass, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	recordApplied("deleted the " + defaultStorageClass + " StorageClass to recreate it")
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// uiAddonService is the service of a UI addon, exposed by an ingress of the same name in its namespace
+type uiAddonService struct {
+	namespace string
+	name      string
+	port      int32
+}
+
+// uiAddonServices lists the UI addons whose configure flow exposes them through an ingress, as in their manifests
+var uiAddonServices = map[string]uiAddonService{
+	"headlamp": {namespace: "headlamp", name: "headlamp", port: 80},
+	"yakd":     {namespace: "yakd-dashboard", name: "yakd-dashboard", port: 80},
+}
+
+// validateIngressHost checks if s is a valid host of an ingress rule, eg. headlamp.test
+func validateIngressHost(s string) error {
+	if errs := validation.IsDNS1123Subdomain(s); len(errs) > 0 {
+		return fmt.Errorf("invalid host %q: %s", s, strings.Join(errs, ", "))
+	}
+	if !strings.Contains(s, ".") {
+		return fmt.Errorf("invalid host %q: must be a fully qualified name, eg. %s.test", s, s)
+	}
+	return nil
+}
+
+// validateIngressClass checks if s is a valid name of an IngressClass
+func validateIngressClass(s string) error {
+	if errs := validation.IsDNS1123Subdomain(s); len(errs) > 0 {
+		return fmt.Errorf("invalid ingress class %q: %s", s, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// uiIngressSpec returns the spec of the ingress routing every path of the host to the service.
+// The default IngressClass of the cluster is used if class is empty.
+func uiIngressSpec(svc uiAddonService, host, class string) networking.IngressSpec {
+	pathType := networking.PathTypePrefix
+	spec := networking.IngressSpec{Rules: []networking.IngressRule{{
+		Host: host,
+		IngressRuleValue: networking.IngressRuleValue{HTTP: &networking.HTTPIngressRuleValue{Paths: []networking.HTTPIngressPath{{
+			Path:     "/",
+			PathType: &pathType,
+			Backend: networking.IngressBackend{Service: &networking.IngressServiceBackend{
+				Name: svc.name,
+				Port: networking.ServiceBackendPort{Number: svc.port},
+			}},
+		}}}},
+	}}}
+	if class != "" {
+		spec.IngressClassName = &class
+	}
+	return spec
+}
+
+// uiIngressConfigurator exposes a UI addon through an ingress with the host entered by the user
+type uiIngressConfigurator struct {
+	addon string
+	host  string
+	class string
+}
+
+// Validate prompts for the host and the ingress class, warning if no ingress controller is enabled
+func (u *uiIngressConfigurator) Validate(profile string) error {
+	if _, ok := uiAddonServices[u.addon]; !ok {
+		return fmt.Errorf("%s can not be exposed through an ingress", u.addon)
+	}
+	if cfg, err := config.Load(profile); err == nil && !assets.Addons["ingress"].IsEnabled(cfg) {
+		out.WarningT("The ingress addon is not enabled, the ingress is only served once a controller runs: minikube -p {{.profile}} addons enable ingress", out.V{"profile": profile})
+	}
+	u.host = AskForStaticCheckedValue("-- Enter the host to reach "+u.addon+" on (ex. "+u.addon+".test): ", validateIngressHost)
+	u.class = AskForStaticCheckedValueOptional("-- Enter the ingress class, or leave empty for the default class of the cluster (ex. nginx): ", validateIngressClass)
+	return nil
+}
+
+// Apply creates the namespace of the addon if needed, then creates or updates the ingress of its service
+func (u *uiIngressConfigurator) Apply(ctx context.Context, profile string) (applyResult, error) {
+	svc := uiAddonServices[u.addon]
+	if err := configureClient.EnsureNamespace(ctx, profile, svc.namespace); err != nil {
+		return applyResult{}, fmt.Errorf("create namespace %s: %w", svc.namespace, err)
+	}
+	if err := configureClient.CreateIngress(ctx, profile, svc.namespace, svc.name, uiIngressSpec(svc, u.host, u.class)); err != nil {
+		return applyResult{}, fmt.Errorf("create ingress %s/%s: %w", svc.namespace, svc.name, err)
+	}
+	recordApplied("exposed " + u.addon + " on " + u.host + " with the " + svc.namespace + "/" + svc.name + " ingress")
+	return applyResult{uiIngressHost: u.host}, nil
+}
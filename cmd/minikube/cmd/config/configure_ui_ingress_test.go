@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestValidateIngressHost(t *testing.T) {
+	tests := []struct {
+		host string
+		err  bool
+	}{
+		{host: "headlamp.test"},
+		{host: "ui.lab.example.com"},
+		{host: "headlamp", err: true},
+		{host: "Headlamp.test", err: true},
+		{host: "headlamp.test:8080", err: true},
+		{host: "*.test", err: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			if err := validateIngressHost(tc.host); (err != nil) != tc.err {
+				t.Errorf("expected error %t but got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestUIIngressSpec(t *testing.T) {
+	svc := uiAddonServices["headlamp"]
+	spec := uiIngressSpec(svc, "headlamp.test", "")
+	if spec.IngressClassName != nil {
+		t.Errorf("expected the default ingress class, got %q", *spec.IngressClassName)
+	}
+	rule := spec.Rules[0]
+	if rule.Host != "headlamp.test" {
+		t.Errorf("rule host %q, want headlamp.test", rule.Host)
+	}
+	backend := rule.HTTP.Paths[0].Backend.Service
+	if backend.Name != "headlamp" || backend.Port.Number != 80 {
+		t.Errorf("backend %s:%d, want headlamp:80", backend.Name, backend.Port.Number)
+	}
+
+	spec = uiIngressSpec(svc, "headlamp.test", "nginx")
+	if spec.IngressClassName == nil || *spec.IngressClassName != "nginx" {
+		t.Errorf("expected the nginx ingress class, got %v", spec.IngressClassName)
+	}
+}
+
+func TestUIIngressConfiguratorApply(t *testing.T) {
+	f := useFakeClusterClient(t)
+	u := &uiIngressConfigurator{addon: "yakd", host: "yakd.test", class: "nginx"}
+	r, err := u.Apply(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Apply returned unexpected error: %v", err)
+	}
+	want := []string{"EnsureNamespace yakd-dashboard", "CreateIngress yakd-dashboard/yakd-dashboard"}
+	if !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("unexpected cluster operations %v, want %v", f.calls, want)
+	}
+	if got := f.ingresses["yakd-dashboard/yakd-dashboard"].Rules[0].Host; got != "yakd.test" {
+		t.Errorf("ingress host %q, want yakd.test", got)
+	}
+	if r.uiIngressHost != "yakd.test" {
+		t.Errorf("uiIngressHost = %q, want yakd.test", r.uiIngressHost)
+	}
+}
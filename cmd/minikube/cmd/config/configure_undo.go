@@ -0,0 +1,261 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// undoLast is set by --undo to revert the last configure run on the addon
+var undoLast bool
+
+// undoRecord is what the last configure run changed for an addon, so --undo can revert it
+type undoRecord struct {
+	Addon string    `json:"addon"`
+	Time  time.Time `json:"time"`
+	// Backup is the config backup of the profile saved before the first change, empty if the config was not changed
+	Backup string `json:"backup,omitempty"`
+	// Secrets are the secrets created or deleted, with their data before the change
+	Secrets []undoSecret `json:"secrets,omitempty"`
+}
+
+// undoSecret is the data of a secret before configure changed it, nil if the secret did not exist
+type undoSecret struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Data      map[string]string `json:"data"`
+}
+
+// empty returns true if the record holds no change to revert
+func (r *undoRecord) empty() bool {
+	return r.Backup == "" && len(r.Secrets) == 0
+}
+
+// hasSecret returns true if the record already holds the data of the secret before the run
+func (r *undoRecord) hasSecret(namespace, name string) bool {
+	for _, s := range r.Secrets {
+		if s.Namespace == namespace && s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// undoRecords holds the changes of the current run by addon, until the addon is successfully configured
+var undoRecords = struct {
+	sync.Mutex
+	byAddon map[string]*undoRecord
+}{byAddon: map[string]*undoRecord{}}
+
+// currentUndoRecord returns the record of the addon of the current events scope, it must be called with undoRecords locked
+func currentUndoRecord() *undoRecord {
+	addon := configureEventScope.addon
+	r, ok := undoRecords.byAddon[addon]
+	if !ok {
+		r = &undoRecord{Addon: addon}
+		undoRecords.byAddon[addon] = r
+	}
+	return r
+}
+
+// recordUndoBackup records the config backup of the profile, only the first backup of the run is kept
+func recordUndoBackup(path string) {
+	undoRecords.Lock()
+	defer undoRecords.Unlock()
+	r := currentUndoRecord()
+	if r.Backup == "" {
+		r.Backup = filepath.Base(path)
+	}
+}
+
+// undoClient records the data of the secrets before configure changes them, to restore it with --undo
+type undoClient struct {
+	clusterClient
+}
+
+// snapshotSecret records the data of the secret before its first change of the run
+func (c undoClient) snapshotSecret(ctx context.Context, profile, namespace, name string) error {
+	undoRecords.Lock()
+	defer undoRecords.Unlock()
+	r := currentUndoRecord()
+	if r.hasSecret(namespace, name) {
+		return nil
+	}
+	data, err := c.clusterClient.GetSecretData(ctx, profile, namespace, name)
+	if err != nil {
+		return fmt.Errorf("read secret %s/%s: %w", namespace, name, err)
+	}
+	r.Secrets = append(r.Secrets, undoSecret{Namespace: namespace, Name: name, Data: data})
+	return nil
+}
+
+func (c undoClient) CreateSecret(ctx context.Context, profile, namespace, name string, data, labels map[string]string) error {
+	if err := c.snapshotSecret(ctx, profile, namespace, name); err != nil {
+		return err
+	}
+	return c.clusterClient.CreateSecret(ctx, profile, namespace, name, data, labels)
+}
+
+func (c undoClient) DeleteSecret(ctx context.Context, profile, namespace, name string) error {
+	if err := c.snapshotSecret(ctx, profile, namespace, name); err != nil {
+		return err
+	}
+	return c.clusterClient.DeleteSecret(ctx, profile, namespace, name)
+}
+
+// undoRecordPath returns the path of the undo record of the addon in the profile directory
+func undoRecordPath(profile, addon string) string {
+	return filepath.Join(config.ProfileFolderPath(profile), "undo", addon+".json")
+}
+
+// saveUndoRecord saves the changes of the run to the addon, replacing those of the previous run.
+// Failing to save them only warns, the addon was configured.
+func saveUndoRecord(profile, addon string) {
+	undoRecords.Lock()
+	r, ok := undoRecords.byAddon[addon]
+	delete(undoRecords.byAddon, addon)
+	undoRecords.Unlock()
+	if !ok || r.empty() {
+		return
+	}
+	r.Time = time.Now()
+	if err := writeUndoRecord(undoRecordPath(profile, addon), r); err != nil {
+		out.WarningT("Unable to save the changes to {{.name}}, they can not be reverted with --undo: {{.error}}", out.V{"name": addon, "error": err})
+	}
+}
+
+// writeUndoRecord writes the record, only readable by the user as it holds the previous data of the secrets
+func writeUndoRecord(path string, r *undoRecord) error {
+	data, err := json.MarshalIndent(r, "", "	")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the permissions of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// loadUndoRecord reads the undo record of the addon, it returns nil if the addon has no change to revert
+func loadUndoRecord(profile, addon string) (*undoRecord, error) {
+	data, err := os.ReadFile(undoRecordPath(profile, addon))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := &undoRecord{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("invalid undo record of %s: %w", addon, err)
+	}
+	return r, nil
+}
+
+// revertUndoRecord restores the secrets, then the config of the profile, re-enabling the addon if it is enabled
+// in the restored config so its manifests match it. A stopped cluster, running false, applies the restored config on the next start.
+func revertUndoRecord(ctx context.Context, profile string, r *undoRecord, running bool) error {
+	for _, s := range r.Secrets {
+		if s.Data == nil {
+			exists, err := configureClient.CheckSecretExists(ctx, profile, s.Namespace, s.Name)
+			if err != nil {
+				return fmt.Errorf("check secret %s/%s: %w", s.Namespace, s.Name, err)
+			}
+			if !exists {
+				continue
+			}
+			if err := configureClient.DeleteSecret(ctx, profile, s.Namespace, s.Name); err != nil {
+				return fmt.Errorf("delete secret %s/%s: %w", s.Namespace, s.Name, err)
+			}
+			recordApplied("deleted secret " + s.Namespace + "/" + s.Name)
+			continue
+		}
+		if err := configureClient.CreateSecret(ctx, profile, s.Namespace, s.Name, s.Data, nil); err != nil {
+			return classifyError(ErrSecretCreate, fmt.Errorf("restore secret %s/%s: %w", s.Namespace, s.Name, err))
+		}
+		recordApplied("restored secret " + s.Namespace + "/" + s.Name)
+	}
+	if r.Backup == "" {
+		return nil
+	}
+	cfg, err := config.LoadProfileBackup(profile, r.Backup)
+	if err != nil {
+		return fmt.Errorf("load config backup %s: %w", r.Backup, err)
+	}
+	if err := saveProfileWithBackup(profile, cfg); err != nil {
+		return fmt.Errorf("save config %s: %w", profile, err)
+	}
+	if addon, ok := assets.Addons[r.Addon]; ok && addon.IsEnabled(cfg) && running {
+		if err := configureClient.EnableAddon(cfg, r.Addon); err != nil {
+			return fmt.Errorf("enable %s: %w", r.Addon, err)
+		}
+	}
+	return nil
+}
+
+// undoLastConfigure reverts the last configure run on the addon, once confirmed
+func undoLastConfigure(profile, addon string, running bool) {
+	r, err := loadUndoRecord(profile, addon)
+	if err != nil {
+		exit.Error(reason.HostConfigLoad, "failed to load the changes to revert", err)
+	}
+	if r == nil {
+		out.Styled(style.Empty, "No configure change of {{.name}} to undo for {{.profile}}", out.V{"name": addon, "profile": profile})
+		return
+	}
+	out.Styled(style.Option, "Last configured on {{.time}}", out.V{"time": r.Time.Local().Format(time.RFC1123)})
+	if r.Backup != "" {
+		out.Styled(style.Option, "restore the config of {{.profile}} from {{.backup}}", out.V{"profile": profile, "backup": r.Backup})
+	}
+	for _, s := range r.Secrets {
+		if s.Data == nil {
+			out.Styled(style.Option, "delete secret {{.secret}}", out.V{"secret": s.Namespace + "/" + s.Name})
+			continue
+		}
+		out.Styled(style.Option, "restore secret {{.secret}}", out.V{"secret": s.Namespace + "/" + s.Name})
+	}
+	if !AskForYesNoConfirmation("Do you want to revert the last configuration of "+addon+"?", posResponses, negResponses) {
+		return
+	}
+	ctx, cancel := clusterContext()
+	defer cancel()
+	if err := revertUndoRecord(ctx, profile, r, running); err != nil {
+		exitConfigure("Failed to revert the last configuration of "+addon, err)
+	}
+	if err := os.Remove(undoRecordPath(profile, addon)); err != nil {
+		out.WarningT("Unable to remove the reverted changes of {{.name}}: {{.error}}", out.V{"name": addon, "error": err})
+	}
+	emitConfigureEvent("reverted", addon, nil)
+	out.SuccessT("Reverted the last configuration of {{.name}}", out.V{"name": addon})
+}
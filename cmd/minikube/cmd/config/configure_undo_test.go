@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestUndoRecord(t *testing.T) {
+	f := useFakeClusterClient(t)
+	configureClient = undoClient{f}
+	setConfigureEventScope("p1", "registry-creds")
+	t.Cleanup(func() { setConfigureEventScope("", "") })
+
+	before := &config.ClusterConfig{Name: "p1", KubernetesConfig: config.KubernetesConfig{ContainerRuntime: "containerd"}}
+	if err := config.SaveProfile("p1", before); err != nil {
+		t.Fatalf("failed to save profile: %v", err)
+	}
+	f.secrets["kube-system/registry-creds-ecr"] = map[string]string{"aws-region": "us-east-1"}
+
+	ctx := context.Background()
+	for _, s := range []struct{ name, region string }{{"registry-creds-ecr", "eu-west-1"}, {"registry-creds-ecr", "eu-west-2"}, {"registry-creds-ecr-2", "us-west-1"}} {
+		if err := configureClient.CreateSecret(ctx, "p1", "kube-system", s.name, map[string]string{"aws-region": s.region}, nil); err != nil {
+			t.Fatalf("CreateSecret returned unexpected error: %v", err)
+		}
+	}
+	after := &config.ClusterConfig{Name: "p1", KubernetesConfig: config.KubernetesConfig{ContainerRuntime: "crio"}}
+	if err := saveProfileWithBackup("p1", after); err != nil {
+		t.Fatalf("saveProfileWithBackup returned unexpected error: %v", err)
+	}
+	saveUndoRecord("p1", "registry-creds")
+
+	r, err := loadUndoRecord("p1", "registry-creds")
+	if err != nil || r == nil {
+		t.Fatalf("loadUndoRecord = %v, %v, want the saved record", r, err)
+	}
+	wantSecrets := []undoSecret{
+		{Namespace: "kube-system", Name: "registry-creds-ecr", Data: map[string]string{"aws-region": "us-east-1"}},
+		{Namespace: "kube-system", Name: "registry-creds-ecr-2"},
+	}
+	if !reflect.DeepEqual(r.Secrets, wantSecrets) {
+		t.Errorf("recorded secrets %+v, want %+v", r.Secrets, wantSecrets)
+	}
+	if r.Backup == "" {
+		t.Errorf("expected the config backup to be recorded")
+	}
+	if fi, err := os.Stat(undoRecordPath("p1", "registry-creds")); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("undo record must only be readable by the user: %v, %v", fi, err)
+	}
+
+	if err := revertUndoRecord(ctx, "p1", r, true); err != nil {
+		t.Fatalf("revertUndoRecord returned unexpected error: %v", err)
+	}
+	want := map[string]map[string]string{"kube-system/registry-creds-ecr": {"aws-region": "us-east-1"}}
+	if !reflect.DeepEqual(f.secrets, want) {
+		t.Errorf("secrets after undo %v, want %v", f.secrets, want)
+	}
+	cfg, err := config.Load("p1")
+	if err != nil {
+		t.Fatalf("failed to load profile: %v", err)
+	}
+	if cfg.KubernetesConfig.ContainerRuntime != "containerd" {
+		t.Errorf("config after undo has runtime %q, want containerd", cfg.KubernetesConfig.ContainerRuntime)
+	}
+}
+
+func TestLoadUndoRecordMissing(t *testing.T) {
+	useFakeClusterClient(t)
+	r, err := loadUndoRecord("p1", "ingress")
+	if err != nil || r != nil {
+		t.Errorf("loadUndoRecord = %v, %v, want no record", r, err)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// validateOnly makes configure only check its flags, without reading or changing the profile or the cluster
+var validateOnly bool
+
+// fieldValidation is the outcome of checking a single input of configure
+type fieldValidation struct {
+	field string
+	err   error
+}
+
+// validateConfigureFlags checks the addon name and the flags given to configure, one result per field
+func validateConfigureFlags(addon string) []fieldValidation {
+	results := []fieldValidation{}
+	check := func(field string, err error) {
+		results = append(results, fieldValidation{field: field, err: err})
+	}
+
+	if _, ok := assets.Addons[addon]; !ok && addon != namespaceDefaultsTarget && addon != nodeDefaultsTarget && addon != corednsTarget {
+		check("addon", fmt.Errorf("%q is not a valid addon", addon))
+	} else {
+		check("addon", nil)
+	}
+
+	for _, p := range configureSet {
+		keys, ok := addonConfigKeys[addon]
+		if !ok {
+			check("set "+p, fmt.Errorf("%s has no settings which can be changed with --set", addon))
+			continue
+		}
+		_, _, err := parseAddonConfigPair(addon, keys, p)
+		check("set "+p, err)
+	}
+
+	if errs := validation.IsDNS1123Label(registryCredsNamespace); len(errs) > 0 {
+		check("namespace", fmt.Errorf("%s", strings.Join(errs, ", ")))
+	} else {
+		check("namespace", nil)
+	}
+
+	if registryCredsDockerServerFlag != "" {
+		var err error
+		switch {
+		case addon != "registry-creds":
+			err = fmt.Errorf("--docker-server is only used by registry-creds")
+		case !isValidRegistryServer(registryCredsDockerServerFlag):
+			err = fmt.Errorf("invalid docker registry server %q", registryCredsDockerServerFlag)
+		case registryCredsDockerUserFlag == "" || !registryCredsPasswordStdin:
+			err = fmt.Errorf("--docker-server requires --docker-user and --docker-password-stdin")
+		}
+		check("docker-server", err)
+	}
+	if registryCredsDockerCACert != "" {
+		var err error
+		switch {
+		case registryCredsDockerServerFlag == "":
+			err = fmt.Errorf("--docker-ca-cert requires --docker-server")
+		case registryCredsDockerInsecure:
+			err = fmt.Errorf("--docker-ca-cert and --docker-insecure can not be used together")
+		default:
+			_, err = readCACertFile(expandPath(registryCredsDockerCACert))
+		}
+		check("docker-ca-cert", err)
+	}
+	if registryCredsDockerInsecure && registryCredsDockerServerFlag == "" {
+		check("docker-insecure", fmt.Errorf("--docker-insecure requires --docker-server"))
+	}
+
+	if postConfigHook != "" {
+		check("post-config-hook", validatePostConfigHook(postConfigHook))
+	}
+
+	if answersFlag != "" {
+		_, err := readAnswerProfile(answerProfilePath(answersFlag))
+		check("answers", err)
+	}
+	if saveAnswersFlag != "" {
+		check("save-answers", validateAnswerProfileName(saveAnswersFlag))
+	}
+
+	if registryCredsImportDockerConfig != "" {
+		var err error
+		switch {
+		case addon != "registry-creds":
+			err = fmt.Errorf("--import-docker-config is only used by registry-creds")
+		case registryCredsDockerServerFlag != "":
+			err = fmt.Errorf("--import-docker-config and --docker-server can not be used together")
+		default:
+			_, _, err = readDockerConfig(registryCredsImportDockerConfig)
+		}
+		check("import-docker-config", err)
+	}
+	if registryCredsRuntimeConfig && addon != "registry-creds" {
+		check("runtime-registry-config", fmt.Errorf("--runtime-registry-config is only used by registry-creds"))
+	}
+	if registryCredsRefreshInterval != 0 {
+		err := validateRegistryCredsRefresh(registryCredsRefreshInterval)
+		if err == nil && addon != "registry-creds" {
+			err = fmt.Errorf("--refresh-interval is only used by registry-creds")
+		}
+		check("refresh-interval", err)
+	}
+
+	if configureTimeout < 0 {
+		check("timeout", fmt.Errorf("must not be negative"))
+	}
+	if registryCredsHealthCheckTimeout < 0 {
+		check("health-check-timeout", fmt.Errorf("must not be negative"))
+	}
+	return results
+}
+
+// hasInvalidFields returns true if any of the fields failed their validation
+func hasInvalidFields(results []fieldValidation) bool {
+	for _, r := range results {
+		if r.err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// reportConfigureValidation prints whether every field passed, exiting with a usage error if any of them failed
+func reportConfigureValidation(results []fieldValidation) {
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			out.ErrT(style.Failure, "{{.field}}: {{.error}}", out.V{"field": r.field, "error": r.err})
+			continue
+		}
+		out.Styled(style.Check, "{{.field}}: valid", out.V{"field": r.field})
+	}
+	if failed > 0 {
+		exit.Message(reason.Usage, "{{.count}} invalid configure inputs", out.V{"count": failed})
+	}
+	out.SuccessT("All configure inputs are valid")
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateConfigureFlags(t *testing.T) {
+	tests := []struct {
+		description   string
+		addon         string
+		set           []string
+		namespace     string
+		dockerServer  string
+		insecure      bool
+		timeout       time.Duration
+		invalidFields []string
+	}{
+		{
+			description: "valid set",
+			addon:       "metallb",
+			set:         []string{"range=10.0.0.1-10.0.0.10"},
+			namespace:   "kube-system",
+		},
+		{
+			description:   "unknown addon",
+			addon:         "unknown",
+			namespace:     "kube-system",
+			invalidFields: []string{"addon"},
+		},
+		{
+			description:   "invalid set values",
+			addon:         "auto-pause",
+			set:           []string{"interval=1m", "interval=never", "unknown=1"},
+			namespace:     "kube-system",
+			invalidFields: []string{"set interval=never", "set unknown=1"},
+		},
+		{
+			description:   "invalid namespace",
+			addon:         "registry-creds",
+			namespace:     "Kube_System",
+			invalidFields: []string{"namespace"},
+		},
+		{
+			description:   "docker server without credentials",
+			addon:         "registry-creds",
+			namespace:     "kube-system",
+			dockerServer:  "registry.dev",
+			invalidFields: []string{"docker-server"},
+		},
+		{
+			description:   "insecure without docker server",
+			addon:         "registry-creds",
+			namespace:     "kube-system",
+			insecure:      true,
+			invalidFields: []string{"docker-insecure"},
+		},
+		{
+			description:   "negative timeout",
+			addon:         "ingress",
+			namespace:     "kube-system",
+			timeout:       -time.Second,
+			invalidFields: []string{"timeout"},
+		},
+	}
+	defer func(set []string, namespace, server string, insecure bool, timeout time.Duration) {
+		configureSet, registryCredsNamespace, registryCredsDockerServerFlag, registryCredsDockerInsecure, configureTimeout = set, namespace, server, insecure, timeout
+	}(configureSet, registryCredsNamespace, registryCredsDockerServerFlag, registryCredsDockerInsecure, configureTimeout)
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			configureSet, registryCredsNamespace, registryCredsDockerServerFlag, registryCredsDockerInsecure, configureTimeout = tc.set, tc.namespace, tc.dockerServer, tc.insecure, tc.timeout
+
+			var invalid []string
+			for _, r := range validateConfigureFlags(tc.addon) {
+				if r.err != nil {
+					invalid = append(invalid, r.field)
+				}
+			}
+			if !reflect.DeepEqual(invalid, tc.invalidFields) {
+				t.Errorf("validateConfigureFlags(%q) invalid fields = %v, want %v", tc.addon, invalid, tc.invalidFields)
+			}
+		})
+	}
+}
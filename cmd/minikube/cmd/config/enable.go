@@ -18,6 +18,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -83,7 +88,20 @@ You can view the list of minikube maintainers at: https://github.com/kubernetes/
 		if registries != "" {
 			viper.Set(config.AddonRegistries, registries)
 		}
-		err = addons.SetAndSave(ClusterFlagValue(), addon, "true")
+		if out.IsTerminal(os.Stdin) {
+			addons.ConfirmOverwrite = func(question string) bool {
+				return AskForYesNoConfirmation(question, posResponses, negResponses)
+			}
+		}
+		inline, err := inlineAddonConfig(ClusterFlagValue(), addon, cc, os.Stdin)
+		if err != nil {
+			exit.Message(reason.Usage, "Invalid configuration for {{.addon}}: {{.error}}", out.V{"addon": addon, "error": err})
+		}
+		err = addons.SetAndSaveWithConfig(ClusterFlagValue(), addon, "true", inline.configure)
+		if err != nil {
+			// the addon was not enabled, so the configuration made in the cluster is undone
+			inline.rollback()
+		}
 		if err != nil && !errors.Is(err, addons.ErrSkipThisAddon) {
 			exit.Error(reason.InternalAddonEnable, "enable failed", err)
 		}
@@ -100,9 +118,126 @@ func isOfficialMaintainer(maintainer string) bool {
 	return ok
 }
 
+// inlineConfig is the addon configuration passed as flags to enable
+type inlineConfig struct {
+	// configure applies the configuration, it is nil if no configuration was passed
+	configure func(*config.ClusterConfig) error
+	// rollback undoes the changes configure made in the cluster, when the addon fails to be enabled
+	rollback func()
+}
+
+// inlineAddonConfig validates the addon configuration passed as flags to enable and returns how to apply it,
+// so the addon is configured and enabled in a single step. The docker registry password is read from stdin.
+func inlineAddonConfig(profile, addon string, cc *config.ClusterConfig, stdin io.Reader) (inlineConfig, error) {
+	inline := inlineConfig{rollback: func() {}}
+	if metallbRange != "" && addon != "metallb" {
+		return inline, fmt.Errorf("--metallb-range can only be used with the metallb addon")
+	}
+	dockerFlagsSet := registryCredsDockerServer != "" || registryCredsDockerUser != "" || registryCredsDockerPasswordStdin || registryCredsDockerPasswordFile != ""
+	if dockerFlagsSet && addon != "registry-creds" {
+		return inline, fmt.Errorf("--registry-creds-docker-* flags can only be used with the registry-creds addon")
+	}
+
+	switch {
+	case metallbRange != "":
+		startIP, endIP, err := parseLoadBalancerRange(metallbRange)
+		if err != nil {
+			return inline, err
+		}
+		// the range is checked like with addons configure metallb
+		subnet, err := checkLoadBalancerRange(startIP, endIP, cc)
+		if err != nil {
+			if !addons.Force {
+				return inline, fmt.Errorf("%w, use --force to enable it anyway", err)
+			}
+			out.WarningT("Invalid load balancer range: {{.error}}", out.V{"error": err})
+		}
+		if subnet != nil {
+			out.WarningT("The load balancer range overlaps the {{.subnet}} node subnet, make sure no other host on that network uses these addresses", out.V{"subnet": subnet})
+		}
+		inline.configure = func(cc *config.ClusterConfig) error {
+			// only the range changes, the BGP peers are kept
+			cc.MetalLB.Pools = config.DefaultMetalLBConfig(startIP, endIP).Pools
+			return nil
+		}
+	case dockerFlagsSet:
+		if registryCredsDockerServer == "" || registryCredsDockerUser == "" {
+			return inline, fmt.Errorf("--registry-creds-docker-server and --registry-creds-docker-user must be set together")
+		}
+		if !isValidRegistryServer(registryCredsDockerServer) {
+			return inline, fmt.Errorf("invalid docker registry server %q", registryCredsDockerServer)
+		}
+		password, err := inlineDockerPassword(stdin)
+		if err != nil {
+			return inline, err
+		}
+		c := defaultRegistryCredsConfig()
+		c.docker = dockerRegistry{server: registryCredsDockerServer, user: registryCredsDockerUser, password: password}
+		c.enable("dpr")
+		var changes []registryCredsChange
+		inline.configure = func(_ *config.ClusterConfig) error {
+			ctx, cancel := clusterContext()
+			defer cancel()
+			var err error
+			changes, err = applyRegistryCredsConfig(ctx, profile, "kube-system", []registryCredsSecret{c.dockerRegistrySecret()})
+			return err
+		}
+		inline.rollback = func() {
+			rollbackRegistryCredsChanges(configureCtx, profile, "kube-system", changes)
+		}
+	}
+	return inline, nil
+}
+
+// inlineDockerPassword reads the password of --registry-creds-docker-server from stdin or from a file,
+// so that it is not given on the command line
+func inlineDockerPassword(stdin io.Reader) (string, error) {
+	switch {
+	case registryCredsDockerPasswordStdin && registryCredsDockerPasswordFile != "":
+		return "", fmt.Errorf("--registry-creds-docker-password-stdin and --registry-creds-docker-password-file can not be used together")
+	case registryCredsDockerPasswordStdin:
+		return readPasswordStdin(stdin)
+	case registryCredsDockerPasswordFile != "":
+		path := expandPath(registryCredsDockerPasswordFile)
+		data, err := readTextFile(path)
+		if err != nil {
+			return "", err
+		}
+		password := strings.TrimSpace(string(data))
+		if password == "" {
+			return "", fmt.Errorf("%s is empty", path)
+		}
+		return password, nil
+	}
+	return "", fmt.Errorf("--registry-creds-docker-server requires --registry-creds-docker-password-stdin or --registry-creds-docker-password-file")
+}
+
+// parseLoadBalancerRange parses a "START-END" range of IP addresses
+func parseLoadBalancerRange(r string) (string, string, error) {
+	startIP, endIP, found := strings.Cut(r, "-")
+	if !found {
+		return "", "", fmt.Errorf("%q is not a range, expected START-END", r)
+	}
+	startIP = strings.TrimSpace(startIP)
+	endIP = strings.TrimSpace(endIP)
+	if net.ParseIP(startIP) == nil {
+		return "", "", fmt.Errorf("%q is not a valid IP address", startIP)
+	}
+	if net.ParseIP(endIP) == nil {
+		return "", "", fmt.Errorf("%q is not a valid IP address", endIP)
+	}
+	return startIP, endIP, nil
+}
+
 var (
 	images     string
 	registries string
+
+	metallbRange                     string
+	registryCredsDockerServer        string
+	registryCredsDockerUser          string
+	registryCredsDockerPasswordStdin bool
+	registryCredsDockerPasswordFile  string
 )
 
 func init() {
@@ -110,5 +245,11 @@ func init() {
 	addonsEnableCmd.Flags().StringVar(&registries, "registries", "", "Registries used by this addon. Separated by commas.")
 	addonsEnableCmd.Flags().BoolVar(&addons.Force, "force", false, "If true, will perform potentially dangerous operations. Use with discretion.")
 	addonsEnableCmd.Flags().BoolVar(&addons.Refresh, "refresh", false, "If true, pods might get deleted and restarted on addon enable")
+	addonsEnableCmd.Flags().BoolVar(&addons.ReportAllPods, "report-all-pods", false, "If true, list every existing pod affected by the addon, even in large clusters. Currently only used by gcp-auth")
+	addonsEnableCmd.Flags().StringVar(&metallbRange, "metallb-range", "", "Load balancer IP range used by the metallb addon, formatted as START-END (ex. 192.168.49.100-192.168.49.120)")
+	addonsEnableCmd.Flags().StringVar(&registryCredsDockerServer, "registry-creds-docker-server", "", "Docker private registry server url used by the registry-creds addon")
+	addonsEnableCmd.Flags().StringVar(&registryCredsDockerUser, "registry-creds-docker-user", "", "Docker private registry username used by the registry-creds addon")
+	addonsEnableCmd.Flags().BoolVar(&registryCredsDockerPasswordStdin, "registry-creds-docker-password-stdin", false, "Read the docker private registry password used by the registry-creds addon from stdin")
+	addonsEnableCmd.Flags().StringVar(&registryCredsDockerPasswordFile, "registry-creds-docker-password-file", "", "Read the docker private registry password used by the registry-creds addon from a file")
 	AddonsCmd.AddCommand(addonsEnableCmd)
 }
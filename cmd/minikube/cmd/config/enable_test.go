@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestParseLoadBalancerRange(t *testing.T) {
+	tests := []struct {
+		input     string
+		startIP   string
+		endIP     string
+		shouldErr bool
+	}{
+		{"192.168.49.100-192.168.49.120", "192.168.49.100", "192.168.49.120", false},
+		{"10.0.0.1 - 10.0.0.5", "10.0.0.1", "10.0.0.5", false},
+		{"192.168.49.100", "", "", true},
+		{"192.168.49.100-", "", "", true},
+		{"foo-192.168.49.120", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, tc := range tests {
+		startIP, endIP, err := parseLoadBalancerRange(tc.input)
+		if (err != nil) != tc.shouldErr {
+			t.Errorf("parseLoadBalancerRange(%q) error = %v, shouldErr %v", tc.input, err, tc.shouldErr)
+			continue
+		}
+		if startIP != tc.startIP || endIP != tc.endIP {
+			t.Errorf("parseLoadBalancerRange(%q) = %q, %q, want %q, %q", tc.input, startIP, endIP, tc.startIP, tc.endIP)
+		}
+	}
+}
+
+// useInlineFlags sets the inline configuration flags of enable for the duration of the test
+func useInlineFlags(t *testing.T, r, server, user string, stdin bool) {
+	oldRange, oldServer, oldUser, oldStdin, oldFile := metallbRange, registryCredsDockerServer, registryCredsDockerUser, registryCredsDockerPasswordStdin, registryCredsDockerPasswordFile
+	metallbRange, registryCredsDockerServer, registryCredsDockerUser, registryCredsDockerPasswordStdin, registryCredsDockerPasswordFile = r, server, user, stdin, ""
+	t.Cleanup(func() {
+		metallbRange, registryCredsDockerServer, registryCredsDockerUser, registryCredsDockerPasswordStdin, registryCredsDockerPasswordFile = oldRange, oldServer, oldUser, oldStdin, oldFile
+	})
+}
+
+func TestInlineMetalLBRange(t *testing.T) {
+	cc := &config.ClusterConfig{Name: "minikube", Nodes: []config.Node{{IP: "192.168.49.2", ControlPlane: true}}}
+	tests := []struct {
+		r   string
+		err bool
+	}{
+		{r: "192.168.49.100-192.168.49.120"},
+		{r: "192.168.49.1-192.168.49.10", err: true},
+		{r: "192.168.49.120-192.168.49.100", err: true},
+	}
+	for _, tc := range tests {
+		useInlineFlags(t, tc.r, "", "", false)
+		inline, err := inlineAddonConfig("minikube", "metallb", cc, strings.NewReader(""))
+		if (err != nil) != tc.err {
+			t.Errorf("inlineAddonConfig() with --metallb-range %s error = %v, want error %t", tc.r, err, tc.err)
+		}
+		if err == nil && inline.configure == nil {
+			t.Errorf("inlineAddonConfig() with --metallb-range %s returned no configuration", tc.r)
+		}
+	}
+}
+
+func TestInlineRegistryCredsRollback(t *testing.T) {
+	f := useFakeClusterClient(t)
+	useInlineFlags(t, "", "registry.dev", "me", false)
+	if _, err := inlineAddonConfig("minikube", "registry-creds", &config.ClusterConfig{}, strings.NewReader("s3cret\n")); err == nil {
+		t.Errorf("expected an error without --registry-creds-docker-password-stdin or --registry-creds-docker-password-file")
+	}
+
+	useInlineFlags(t, "", "registry.dev", "me", true)
+	inline, err := inlineAddonConfig("minikube", "registry-creds", &config.ClusterConfig{}, strings.NewReader("s3cret\n"))
+	if err != nil {
+		t.Fatalf("inlineAddonConfig() returned unexpected error: %v", err)
+	}
+	if err := inline.configure(&config.ClusterConfig{}); err != nil {
+		t.Fatalf("configure returned unexpected error: %v", err)
+	}
+	if got := f.secrets["kube-system/registry-creds-dpr"]["DOCKER_PRIVATE_REGISTRY_PASSWORD"]; got != "s3cret" {
+		t.Errorf("registry-creds-dpr password = %q, want %q", got, "s3cret")
+	}
+
+	// the addon failed to be enabled
+	inline.rollback()
+	if _, ok := f.secrets["kube-system/registry-creds-dpr"]; ok {
+		t.Errorf("registry-creds-dpr secret exists, want it rolled back")
+	}
+	var changes []string
+	for _, call := range f.calls {
+		if !strings.HasPrefix(call, "GetSecretData ") {
+			changes = append(changes, call)
+		}
+	}
+	if want := []string{"CreateSecret kube-system/registry-creds-dpr", "DeleteSecret kube-system/registry-creds-dpr"}; !reflect.DeepEqual(changes, want) {
+		t.Errorf("unexpected cluster changes %v, want %v", changes, want)
+	}
+}
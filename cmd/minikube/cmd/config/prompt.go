@@ -18,12 +18,20 @@ package config
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
+	"k8s.io/client-go/util/homedir"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/out"
 )
@@ -31,22 +39,43 @@ import (
 // AskForYesNoConfirmation asks the user for confirmation. A user must type in "yes" or "no" and
 // then press enter. It has fuzzy matching, so "y", "Y", "yes", "YES", and "Yes" all count as
 // confirmations. If the input is not recognized, it will ask again. The function does not return
-// until it gets a valid response from the user.
+// until it gets a valid response from the user. If the answer profile has an answer for the prompt,
+// an empty response picks it.
 func AskForYesNoConfirmation(s string, posResponses, negResponses []string) bool {
 	reader := bufio.NewReader(os.Stdin)
 
+	def := promptDefault(s)
+	choices := "[y/n]"
+	switch {
+	case isAnswer(posResponses, def):
+		choices = "[Y/n]"
+	case isAnswer(negResponses, def):
+		choices = "[y/N]"
+	default:
+		def = ""
+	}
 	for {
-		out.String("%s [y/n]: ", s)
-
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			log.Fatal(err)
+		var response string
+		if configureQuiet {
+			response = quietResponse(s, true)
+		} else {
+			out.String("%s %s: ", s, choices)
+			var err error
+			response, err = reader.ReadString('\n')
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if strings.TrimSpace(response) == "" {
+			response = def
 		}
 
-		switch r := strings.ToLower(strings.TrimSpace(response)); {
-		case containsString(posResponses, r):
+		switch {
+		case isAnswer(posResponses, response):
+			acceptAnswer(s, "y")
 			return true
-		case containsString(negResponses, r):
+		case isAnswer(negResponses, response):
+			acceptAnswer(s, "n")
 			return false
 		default:
 			out.Err("Please type yes or no:")
@@ -66,6 +95,7 @@ func AskForStaticValue(s string) string {
 			out.Err("--Error, please enter a value:")
 			continue
 		}
+		acceptAnswer(s, response)
 		return response
 	}
 }
@@ -74,19 +104,94 @@ func AskForStaticValue(s string) string {
 func AskForStaticValueOptional(s string) string {
 	reader := bufio.NewReader(os.Stdin)
 
-	return getStaticValue(reader, s)
+	response := getOptionalStaticValue(reader, s)
+	acceptAnswer(s, response)
+	return response
 }
 
+// getStaticValue reads a single value, an empty response picks the answer of the answer profile for the prompt, if any.
+// A response given as @FILE is replaced by the content of the file, asking again until the file can be read.
 func getStaticValue(reader *bufio.Reader, s string) string {
-	out.String("%s", s)
+	return readStaticValue(reader, s, true)
+}
+
+// getOptionalStaticValue reads a single value like getStaticValue, which may be left empty
+func getOptionalStaticValue(reader *bufio.Reader, s string) string {
+	return readStaticValue(reader, s, false)
+}
+
+// readStaticValue reads a single value, or takes the answer of the answer profile with --quiet
+func readStaticValue(reader *bufio.Reader, s string, required bool) string {
+	def := promptDefault(s)
+	for {
+		var response string
+		if configureQuiet {
+			response = quietResponse(s, required)
+		} else {
+			out.String("%s", withDefault(s, def))
+			var err error
+			response, err = reader.ReadString('\n')
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		response = strings.TrimSpace(response)
+		if response == "" {
+			response = def
+		}
+		value, err := resolveFileValue(response)
+		if err != nil {
+			out.Err("--Invalid input, %v, please enter a value:", err)
+			continue
+		}
+		rememberFileValue(s, response, value)
+		return value
+	}
+}
 
-	response, err := reader.ReadString('\n')
+// fileValuePrefix starts a value read from a file, eg. @/run/secrets/registry-password.
+// Doubling it escapes a value starting with a literal @, eg. @@bc123 for the password @bc123.
+const fileValuePrefix = "@"
+
+// resolveFileValue returns the content of the file, without its surrounding spaces and newlines, if the value
+// is given as @FILE, eg. a password mounted as a CI secret. A value given as @@VALUE is returned as @VALUE,
+// other values are returned as they are.
+func resolveFileValue(v string) (string, error) {
+	if !strings.HasPrefix(v, fileValuePrefix) {
+		return v, nil
+	}
+	if strings.HasPrefix(v, fileValuePrefix+fileValuePrefix) {
+		return strings.TrimPrefix(v, fileValuePrefix), nil
+	}
+	path := expandPath(strings.TrimPrefix(v, fileValuePrefix))
+	if path == "" {
+		return "", fmt.Errorf("%s must be followed by the path of a file, or doubled for a value starting with %s", fileValuePrefix, fileValuePrefix)
+	}
+	data, err := readTextFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return "", fmt.Errorf("%w, a value starting with %s is given as %s%s", err, fileValuePrefix, fileValuePrefix, fileValuePrefix)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
 	}
+	return value, nil
+}
 
-	response = strings.TrimSpace(response)
-	return response
+// fileValue is the last value of a prompt read from a file, so its answer is saved as the file and not its content
+var fileValue struct {
+	prompt string
+	ref    string
+	value  string
+}
+
+// rememberFileValue records the value of the prompt if it was read from the file given as ref
+func rememberFileValue(prompt, ref, value string) {
+	if ref == value {
+		return
+	}
+	fileValue.prompt, fileValue.ref, fileValue.value = answerKey(prompt), ref, value
 }
 
 func concealableAskForStaticValue(readWriter io.ReadWriter, promptString string, hidden bool) (string, error) {
@@ -116,8 +221,25 @@ func concealableAskForStaticValue(readWriter io.ReadWriter, promptString string,
 	}
 }
 
-// AskForPasswordValue asks for a password value, while hiding the input
+// AskForPasswordValue asks for a password value, while hiding the input.
+// The password is never recorded as an answer, so --save-answers does not write it to the answer profile.
 func AskForPasswordValue(s string) string {
+	return askForPasswordValue(s, true)
+}
+
+// AskForPasswordValueOptional asks for an optional password value like AskForPasswordValue, can just skip enter.
+func AskForPasswordValueOptional(s string) string {
+	return askForPasswordValue(s, false)
+}
+
+func askForPasswordValue(s string, required bool) string {
+	if configureQuiet {
+		value, err := resolveFileValue(quietResponse(s, required))
+		if err != nil {
+			exitConfigure("unable to read the password", classifyError(ErrInvalidInput, err))
+		}
+		return value
+	}
 
 	stdInFd := int(os.Stdin.Fd())
 	oldState, err := term.MakeRaw(stdInFd)
@@ -130,11 +252,26 @@ func AskForPasswordValue(s string) string {
 		}
 	}()
 
-	result, err := concealableAskForStaticValue(os.Stdin, s, true)
-	if err != nil {
-		defer log.Fatal(err)
+	for {
+		var result string
+		if required {
+			result, err = concealableAskForStaticValue(os.Stdin, s, true)
+		} else {
+			result, err = term.NewTerminal(os.Stdin, "").ReadPassword(s)
+			result = strings.TrimSpace(result)
+		}
+		if err != nil {
+			defer log.Fatal(err)
+			return result
+		}
+		// the password may be read from a file given as @FILE
+		value, err := resolveFileValue(result)
+		if err != nil {
+			out.WarningT("Unable to read the password: {{.error}}", out.V{"error": err})
+			continue
+		}
+		return value
 	}
-	return result
 }
 
 // posString returns the first index of element in slice.
@@ -153,6 +290,23 @@ func containsString(slice []string, element string) bool {
 	return posString(slice, element) != -1
 }
 
+// answerIndex returns the index of the answer a user typed in the expected answers of a prompt,
+// ignoring case and surrounding whitespace. If none of them matches, returns -1.
+// It is only meant for prompt answers, values such as keys or names are matched exactly with posString.
+func answerIndex(answers []string, input string) int {
+	for index, answer := range answers {
+		if strings.EqualFold(strings.TrimSpace(answer), strings.TrimSpace(input)) {
+			return index
+		}
+	}
+	return -1
+}
+
+// isAnswer returns true if the user typed one of the expected answers of a prompt, ignoring case and surrounding whitespace
+func isAnswer(answers []string, input string) bool {
+	return answerIndex(answers, input) != -1
+}
+
 // AskForStaticValidatedValue asks for a single value to enter and check for valid input
 func AskForStaticValidatedValue(s string, validator func(s string) bool) string {
 	reader := bufio.NewReader(os.Stdin)
@@ -169,6 +323,218 @@ func AskForStaticValidatedValue(s string, validator func(s string) bool) string
 			out.Err("--Invalid input, please enter a value:")
 			continue
 		}
+		acceptAnswer(s, response)
 		return response
 	}
 }
+
+// AskForStaticCheckedValue asks for a single value to enter, printing why the input is invalid and asking again
+// until check accepts it
+func AskForStaticCheckedValue(s string, check func(s string) error) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		response := getStaticValue(reader, s)
+
+		// Can't have zero length
+		if len(response) == 0 {
+			out.Err("--Error, please enter a value:")
+			continue
+		}
+		if err := check(response); err != nil {
+			out.Err("--Invalid input, %v, please enter a value:", err)
+			continue
+		}
+		acceptAnswer(s, response)
+		return response
+	}
+}
+
+// AskForStaticCheckedValueOptional asks for a single optional value, printing why a non-empty input is invalid
+// and asking again until check accepts it. It returns an empty string if no value was entered.
+func AskForStaticCheckedValueOptional(s string, check func(s string) error) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		response := getOptionalStaticValue(reader, s)
+		if response == "" {
+			acceptAnswer(s, "")
+			return ""
+		}
+		if err := check(response); err != nil {
+			out.Err("--Invalid input, %v, please enter a value or leave it empty:", err)
+			continue
+		}
+		acceptAnswer(s, response)
+		return response
+	}
+}
+
+// AskForFilePath asks for the path of a local file, expanding a leading ~ and the environment variables,
+// asking again until the file can be read. It returns the expanded path.
+func AskForFilePath(s string) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		response := getStaticValue(reader, s)
+
+		// Can't have zero length
+		if len(response) == 0 {
+			out.Err("--Error, please enter a value:")
+			continue
+		}
+		path := expandPath(response)
+		if err := checkReadableFile(path); err != nil {
+			out.Err("--Invalid input, %v, please enter a value:", err)
+			continue
+		}
+		acceptAnswer(s, response)
+		return path
+	}
+}
+
+// expandPath expands the environment variables and a leading ~ of a local path, eg. "~/.config" or "$HOME/.config"
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return filepath.Join(homedir.HomeDir(), path[1:])
+	}
+	return path
+}
+
+// maxInputFileSize bounds the size of the local files read by configure, eg. credentials or configs of a few KB
+const maxInputFileSize = 1 << 20
+
+// The errors of the local files read by configure, tested with errors.Is
+var (
+	// ErrFileNotFound is returned when the file does not exist
+	ErrFileNotFound = errors.New("file not found")
+	// ErrFileTooLarge is returned when the file is larger than maxInputFileSize
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrFileNotText is returned when a text file is expected but the file holds binary content
+	ErrFileNotText = errors.New("not a text file")
+)
+
+// checkReadableFile checks if the path is a regular file which can be read and is not larger than maxInputFileSize,
+// the errors show the path as it was resolved
+func checkReadableFile(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: %s", ErrFileNotFound, path)
+	}
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	if fi.Size() > maxInputFileSize {
+		return fmt.Errorf("%w: %s is %d bytes, at most %d bytes are read", ErrFileTooLarge, path, fi.Size(), maxInputFileSize)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// readTextFile reads a local text file, eg. credentials or a config, refusing files larger than maxInputFileSize
+// and binary content
+func readTextFile(path string) ([]byte, error) {
+	if err := checkReadableFile(path); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	defer f.Close()
+	// the file may have grown since it was checked
+	data, err := io.ReadAll(io.LimitReader(f, maxInputFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	if len(data) > maxInputFileSize {
+		return nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrFileTooLarge, path, maxInputFileSize)
+	}
+	if bytes.IndexByte(data, 0) != -1 || !utf8.Valid(data) {
+		return nil, fmt.Errorf("%s is %w", path, ErrFileNotText)
+	}
+	return data, nil
+}
+
+// AskForDurationValue asks for a duration greater than 0 (ex. 1m0s), asking again until the input is valid
+func AskForDurationValue(s string) time.Duration {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		response := getStaticValue(reader, s)
+
+		// Can't have zero length
+		if len(response) == 0 {
+			out.Err("--Error, please enter a value:")
+			continue
+		}
+		d, err := time.ParseDuration(response)
+		if err != nil || d <= 0 {
+			out.Err("--Invalid duration, please enter a value greater than 0s (ex. 1m0s):")
+			continue
+		}
+		acceptAnswer(s, response)
+		return d
+	}
+}
+
+// AskForIntValue asks for an integer between min and max inclusive, asking again until the input is valid
+func AskForIntValue(s string, min, max int) int {
+	reader := bufio.NewReader(os.Stdin)
+
+	for {
+		response := getStaticValue(reader, s)
+
+		// Can't have zero length
+		if len(response) == 0 {
+			out.Err("--Error, please enter a value:")
+			continue
+		}
+		i, err := strconv.Atoi(response)
+		if err != nil || i < min || i > max {
+			out.Err("--Invalid number, please enter a value between %d and %d:", min, max)
+			continue
+		}
+		acceptAnswer(s, response)
+		return i
+	}
+}
+
+// AskForChoice asks to pick one of choices, by its number in the list or by its name, asking again until the input is valid
+func AskForChoice(s string, choices []string) string {
+	reader := bufio.NewReader(os.Stdin)
+
+	for i, c := range choices {
+		out.String("  %d) %s\n", i+1, c)
+	}
+	for {
+		response := getStaticValue(reader, s)
+
+		i := choiceIndex(choices, response)
+		if i == -1 {
+			out.Err("--Invalid choice, please enter a number between 1 and %d or a name from the list:", len(choices))
+			continue
+		}
+		acceptAnswer(s, choices[i])
+		return choices[i]
+	}
+}
+
+// choiceIndex returns the index of the choice picked by response, either its 1-based number or its name.
+// If response does not pick any choice, returns -1.
+func choiceIndex(choices []string, response string) int {
+	if i, err := strconv.Atoi(strings.TrimSpace(response)); err == nil {
+		if i < 1 || i > len(choices) {
+			return -1
+		}
+		return i - 1
+	}
+	return answerIndex(choices, response)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnswerIndex(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"yes", 0},
+		{"y", 1},
+		{" Yes ", 0},
+		{"Y\n", 1},
+		{"\tYES", 0},
+		{"no", -1},
+		{"", -1},
+		{"ye s", -1},
+	}
+	for _, tc := range tests {
+		if got := answerIndex(posResponses, tc.input); got != tc.want {
+			t.Errorf("answerIndex(%v, %q) = %d, want %d", posResponses, tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestIsAnswer(t *testing.T) {
+	if !isAnswer(negResponses, " No ") {
+		t.Errorf("expected %q to match one of %v", " No ", negResponses)
+	}
+	if isAnswer(negResponses, "nope") {
+		t.Errorf("expected %q to not match any of %v", "nope", negResponses)
+	}
+}
+
+func TestContainsStringIsExact(t *testing.T) {
+	public := registryCredsPublicKeys["dpr"]
+	if !containsString(public, "DOCKER_PRIVATE_REGISTRY_USER") {
+		t.Errorf("expected DOCKER_PRIVATE_REGISTRY_USER to be one of %v", public)
+	}
+	for _, key := range []string{"docker_private_registry_user", " DOCKER_PRIVATE_REGISTRY_USER"} {
+		if containsString(public, key) {
+			t.Errorf("expected %q to not match any of %v, the match must be exact", key, public)
+		}
+	}
+}
+
+func TestChoiceIndex(t *testing.T) {
+	choices := []string{"minikube", "dev", "staging"}
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"1", 0},
+		{" 3\n", 2},
+		{"dev", 1},
+		{"Staging", 2},
+		{"0", -1},
+		{"4", -1},
+		{"prod", -1},
+		{"", -1},
+	}
+	for _, tc := range tests {
+		if got := choiceIndex(choices, tc.input); got != tc.want {
+			t.Errorf("choiceIndex(%v, %q) = %d, want %d", choices, tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("CREDS_DIR", "/etc/creds")
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"~", home},
+		{"~/.config/gcloud/creds.json", filepath.Join(home, ".config/gcloud/creds.json")},
+		{"$HOME/sp.json", filepath.Join(home, "sp.json")},
+		{"${CREDS_DIR}/sp.json", "/etc/creds/sp.json"},
+		{"/etc/~user/sp.json", "/etc/~user/sp.json"},
+		{"~user/sp.json", "~user/sp.json"},
+	}
+	for _, tc := range tests {
+		if got := expandPath(tc.input); got != tc.want {
+			t.Errorf("expandPath(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestCheckReadableFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "creds.json")
+	if err := os.WriteFile(file, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.json")
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{path: file},
+		{path: missing, wantErr: "file not found: " + missing},
+		{path: dir, wantErr: "is not a regular file"},
+	}
+	for _, tc := range tests {
+		err := checkReadableFile(tc.path)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("checkReadableFile(%q) returned unexpected error: %v", tc.path, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("checkReadableFile(%q) = %v, want an error containing %q", tc.path, err, tc.wantErr)
+		}
+	}
+}
+
+func TestReadTextFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		description string
+		path        string
+		err         error
+	}{
+		{description: "text", path: write("creds.json", []byte(`{"type": "service_account"}`))},
+		{description: "missing", path: filepath.Join(dir, "missing.json"), err: ErrFileNotFound},
+		{description: "too large", path: write("large.json", make([]byte, maxInputFileSize+1)), err: ErrFileTooLarge},
+		{description: "binary", path: write("key.p12", []byte{0x30, 0x82, 0x00, 0x0a}), err: ErrFileNotText},
+		{description: "invalid utf-8", path: write("latin1.txt", []byte{'c', 'a', 'f', 0xe9}), err: ErrFileNotText},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := readTextFile(tc.path)
+			if tc.err == nil && err != nil {
+				t.Errorf("readTextFile(%q) returned unexpected error: %v", tc.path, err)
+			}
+			if tc.err != nil && !errors.Is(err, tc.err) {
+				t.Errorf("readTextFile(%q) = %v, want an error matching %v", tc.path, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestResolveFileValue(t *testing.T) {
+	dir := t.TempDir()
+	password := filepath.Join(dir, "password")
+	if err := os.WriteFile(password, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte(" \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		description string
+		value       string
+		want        string
+		err         bool
+	}{
+		{description: "plain value", value: "s3cret", want: "s3cret"},
+		{description: "file", value: "@" + password, want: "s3cret"},
+		{description: "empty file", value: "@" + empty, err: true},
+		{description: "missing file", value: "@" + filepath.Join(dir, "missing"), err: true},
+		{description: "no path", value: "@", err: true},
+		{description: "literal @", value: "@@bc123", want: "@bc123"},
+		{description: "literal @@", value: "@@@", want: "@@"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := resolveFileValue(tc.value)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %t but got %v", tc.err, err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveFileValue(%q) = %q, want %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
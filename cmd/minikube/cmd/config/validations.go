@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	units "github.com/docker/go-units"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/driver"
@@ -155,3 +156,12 @@ func IsValidRuntime(_, runtime string) error {
 	}
 	return nil
 }
+
+// IsValidQuantity checks if a string parses as a Kubernetes resource quantity (eg. 100m, 200Mi)
+func IsValidQuantity(_, quantity string) error {
+	_, err := resource.ParseQuantity(quantity)
+	if err != nil {
+		return fmt.Errorf("invalid quantity: %v", err)
+	}
+	return nil
+}
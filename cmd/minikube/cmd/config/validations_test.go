@@ -174,3 +174,17 @@ func TestIsValidMemory(t *testing.T) {
 
 	runValidations(t, tests, "memory", IsValidMemory)
 }
+
+func TestIsValidQuantity(t *testing.T) {
+	tests := []validationTest{
+		{"100m", false},
+		{"0.5", false},
+		{"200Mi", false},
+		{"1Gi", false},
+		{"200MB", true},
+		{"abc", true},
+		{"", true},
+	}
+
+	runValidations(t, tests, "quantity", IsValidQuantity)
+}
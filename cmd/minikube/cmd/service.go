@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -96,7 +97,7 @@ var serviceCmd = &cobra.Command{
 		}
 
 		var services service.URLs
-		services, err := service.GetServiceURLs(co.API, co.Config.Name, namespace, serviceURLTemplate)
+		services, err := service.GetServiceURLs(context.Background(), co.API, co.Config.Name, namespace, serviceURLTemplate)
 		if err != nil {
 			out.ErrT(style.Fatal, "Failed to get service URL - check that minikube is running and that you have specified the correct namespace (-n flag) if required: {{.error}}", out.V{"error": err})
 			os.Exit(reason.ExSvcUnavailable)
@@ -119,7 +120,7 @@ You may select another namespace by using 'minikube service {{.service}} -n <nam
 
 		var data [][]string
 		for _, svc := range services {
-			openUrls, err := service.WaitForService(co.API, co.Config.Name, namespace, svc.Name, serviceURLTemplate, serviceURLMode, https, wait, interval)
+			openUrls, err := service.WaitForService(context.Background(), co.API, co.Config.Name, namespace, svc.Name, serviceURLTemplate, serviceURLMode, https, wait, interval)
 
 			if err != nil {
 				var s *service.SVCNotFoundError
@@ -148,7 +149,7 @@ You may select another namespace by using 'minikube service {{.service}} -n <nam
 				}
 			}
 			// check whether there are running pods for this service
-			if err := service.CheckServicePods(cname, svc.Name, namespace); err != nil {
+			if err := service.CheckServicePods(context.Background(), cname, svc.Name, namespace); err != nil {
 				exit.Error(reason.SvcUnreachable, "service not available", err)
 			}
 		}
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -45,7 +46,7 @@ var serviceListCmd = &cobra.Command{
 		co := mustload.Healthy(ClusterFlagValue())
 		output := strings.ToLower(profileOutput)
 
-		serviceURLs, err := service.GetServiceURLs(co.API, co.Config.Name, serviceListNamespace, serviceURLTemplate)
+		serviceURLs, err := service.GetServiceURLs(context.Background(), co.API, co.Config.Name, serviceListNamespace, serviceURLTemplate)
 		if err != nil {
 			out.ErrT(style.Fatal, "Failed to get service URL - check that minikube is running and that you have specified the correct namespace (-n flag) if required: {{.error}}", out.V{"error": err})
 			os.Exit(reason.ExSvcUnavailable)
@@ -53,6 +53,7 @@ import (
 
 	"k8s.io/klog/v2"
 	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/addons"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
@@ -1320,6 +1321,9 @@ func validateFlags(cmd *cobra.Command, drvName string) { //nolint:gocyclo
 		if err := validateAutoPauseInterval(viper.GetDuration(autoPauseInterval)); err != nil {
 			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 		}
+		if viper.GetDuration(autoPauseInterval) < addons.AutoPauseCheckInterval {
+			out.WarningT("auto-pause-interval is shorter than the {{.check}} the auto-pause proxy needs to detect activity, the cluster may pause while requests are in flight", out.V{"check": addons.AutoPauseCheckInterval})
+		}
 	}
 
 	if driver.IsSSH(drvName) {
@@ -25,7 +25,7 @@ import (
 	"k8s.io/minikube/pkg/storage"
 )
 
-var pvDir = "/tmp/hostpath-provisioner"
+var pvDir = flag.String("pv-dir", "/tmp/hostpath-provisioner", "Directory of the host the volumes are provisioned in")
 
 func main() {
 	// Glog requires that /tmp exists.
@@ -35,7 +35,7 @@ func main() {
 	}
 	flag.Parse()
 
-	if err := storage.StartStorageProvisioner(pvDir); err != nil {
+	if err := storage.StartStorageProvisioner(*pvDir); err != nil {
 		klog.Exit(err)
 	}
 
@@ -28,7 +28,7 @@ var (
 	DashboardAssets embed.FS
 
 	// DefaultStorageClassAssets assets for default-storageclass addon
-	//go:embed storageclass/storageclass.yaml
+	//go:embed storageclass/storageclass.yaml.tmpl
 	DefaultStorageClassAssets embed.FS
 
 	// PodSecurityPolicyAssets assets for pod-security-policy addon
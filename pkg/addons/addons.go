@@ -61,6 +61,14 @@ var Force = false
 // Currently only used for gcp-auth
 var Refresh = false
 
+// ReportAllPods is used to list every pod affected by an addon, even in large clusters
+// Currently only used for gcp-auth
+var ReportAllPods = false
+
+// ConfirmOverwrite asks the user whether to overwrite what an addon finds on the cluster, eg. the credentials mounted by gcp-auth.
+// It is only set when minikube runs interactively, the addons warn and overwrite otherwise.
+var ConfirmOverwrite func(question string) bool
+
 // ErrSkipThisAddon is a special error that tells us to not error out, but to also not mark the addon as enabled
 var ErrSkipThisAddon = errors.New("skipping this addon")
 
@@ -178,11 +186,23 @@ func Set(cc *config.ClusterConfig, name string, value string) error {
 
 // SetAndSave sets a value and saves the config
 func SetAndSave(profile string, name string, value string) error {
+	return SetAndSaveWithConfig(profile, name, value, nil)
+}
+
+// SetAndSaveWithConfig sets a value and saves the config like SetAndSave, but first calls configure with the loaded
+// config so an addon can be configured and enabled in a single step. Nothing is saved if configure fails.
+func SetAndSaveWithConfig(profile string, name string, value string, configure func(*config.ClusterConfig) error) error {
 	cc, err := config.Load(profile)
 	if err != nil {
 		return errors.Wrap(err, "loading profile")
 	}
 
+	if configure != nil {
+		if err := configure(cc); err != nil {
+			return errors.Wrapf(err, "configure %s", name)
+		}
+	}
+
 	if err := RunCallbacks(cc, name, value); err != nil {
 		if errors.Is(err, ErrSkipThisAddon) {
 			return err
@@ -594,60 +614,106 @@ func UpdateConfigToDisable(cc *config.ClusterConfig) {
 
 // VerifyNotPaused verifies the cluster is not paused before enable/disable an addon.
 func VerifyNotPaused(profile string, enable bool) error {
+	runtimePaused, err := IsPaused(profile)
+	if err != nil {
+		return err
+	}
+	if !runtimePaused {
+		return nil
+	}
+	action := "disable"
+	if enable {
+		action = "enable"
+	}
+	msg := fmt.Sprintf("Can't %s addon on a paused cluster, please unpause the cluster first.", action)
+	out.Styled(style.Shrug, msg)
+	return errors.New(msg)
+}
+
+// IsPaused checks whether the control plane of the cluster is paused, a cluster which is not running is never paused.
+func IsPaused(profile string) (bool, error) {
 	klog.Info("checking whether the cluster is paused")
 
 	cc, err := config.Load(profile)
 	if err != nil {
-		return errors.Wrap(err, "loading profile")
+		return false, errors.Wrap(err, "loading profile")
 	}
 
 	api, err := machine.NewAPIClient()
 	if err != nil {
-		return errors.Wrap(err, "machine client")
+		return false, errors.Wrap(err, "machine client")
 	}
 	defer api.Close()
 
 	cp, err := config.PrimaryControlPlane(cc)
 	if err != nil {
-		return errors.Wrap(err, "control plane")
+		return false, errors.Wrap(err, "control plane")
 	}
 
 	host, err := machine.LoadHost(api, config.MachineName(*cc, cp))
 	if err != nil {
-		return errors.Wrap(err, "get host")
+		return false, errors.Wrap(err, "get host")
 	}
 
 	s, err := host.Driver.GetState()
 	if err != nil {
-		return errors.Wrap(err, "get state")
+		return false, errors.Wrap(err, "get state")
 	}
 	if s != state.Running {
 		// can't check the status of pods on a non-running cluster
-		return nil
+		return false, nil
 	}
 
 	runner, err := machine.CommandRunner(host)
 	if err != nil {
-		return errors.Wrap(err, "command runner")
+		return false, errors.Wrap(err, "command runner")
 	}
 
 	crName := cc.KubernetesConfig.ContainerRuntime
 	cr, err := cruntime.New(cruntime.Config{Type: crName, Runner: runner})
 	if err != nil {
-		return errors.Wrap(err, "container runtime")
+		return false, errors.Wrap(err, "container runtime")
 	}
 	runtimePaused, err := cluster.CheckIfPaused(cr, []string{"kube-system"})
 	if err != nil {
-		return errors.Wrap(err, "check paused")
+		return false, errors.Wrap(err, "check paused")
 	}
-	if !runtimePaused {
-		return nil
+	return runtimePaused, nil
+}
+
+// WriteNodeFiles writes the files on every node of the cluster, then restarts the service so it reads them,
+// eg. the registry config of the container runtime. The service is not restarted if it is empty.
+func WriteNodeFiles(cc *config.ClusterConfig, files []assets.CopyableFile, service string) error {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return errors.Wrap(err, "machine client")
 	}
-	action := "disable"
-	if enable {
-		action = "enable"
+	defer api.Close()
+
+	for _, n := range cc.Nodes {
+		host, err := machine.LoadHost(api, config.MachineName(*cc, n))
+		if err != nil {
+			return errors.Wrapf(err, "get host of node %q", n.Name)
+		}
+		runner, err := machine.CommandRunner(host)
+		if err != nil {
+			return errors.Wrapf(err, "command runner of node %q", n.Name)
+		}
+		for _, f := range files {
+			// the kic runner does not create the directory of the file
+			if _, err := runner.RunCmd(exec.Command("sudo", "mkdir", "-p", f.GetTargetDir())); err != nil {
+				return errors.Wrapf(err, "create %s on node %q", f.GetTargetDir(), n.Name)
+			}
+			if err := runner.Copy(f); err != nil {
+				return errors.Wrapf(err, "copy %s to node %q", path.Join(f.GetTargetDir(), f.GetTargetName()), n.Name)
+			}
+		}
+		if service == "" {
+			continue
+		}
+		if err := sysinit.New(runner).Restart(service); err != nil {
+			return errors.Wrapf(err, "restart %s on node %q", service, n.Name)
+		}
 	}
-	msg := fmt.Sprintf("Can't %s addon on a paused cluster, please unpause the cluster first.", action)
-	out.Styled(style.Shrug, msg)
-	return errors.New(msg)
+	return nil
 }
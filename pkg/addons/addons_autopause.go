@@ -18,6 +18,7 @@ package addons
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -31,6 +32,11 @@ import (
 	"k8s.io/minikube/pkg/minikube/sysinit"
 )
 
+// AutoPauseCheckInterval is how long the auto-pause proxy may hold a new apiserver connection while it
+// checks for activity and unpauses the cluster, see the inspect-delay in deploy/addons/auto-pause/haproxy.cfg.tmpl.
+// An auto-pause interval shorter than this can pause the cluster again before a held request went through.
+const AutoPauseCheckInterval = 10 * time.Second
+
 // enableOrDisableAutoPause enables the service after the config was copied by generic enable.
 func enableOrDisableAutoPause(cc *config.ClusterConfig, name, val string) error {
 	enable, err := strconv.ParseBool(val)
@@ -19,20 +19,25 @@ package addons
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_core "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"github.com/pkg/errors"
 	"golang.org/x/oauth2/google"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/exit"
@@ -51,6 +56,9 @@ const (
 
 	// readPermission correlates to read-only file system permissions
 	readPermission = "0444"
+
+	// credentialsB64Env holds base64-encoded JSON credentials, used instead of the default credentials when set, eg. in CI
+	credentialsB64Env = "MINIKUBE_GCP_CREDENTIALS_B64"
 )
 
 // enableOrDisableGCPAuth enables or disables the gcp-auth addon depending on the val parameter
@@ -65,14 +73,50 @@ func enableOrDisableGCPAuth(cfg *config.ClusterConfig, name, val string) error {
 	return disableAddonGCPAuth(cfg)
 }
 
+// gcpAuthEndpoint is the endpoint the pods exchange the mounted credentials for tokens with
+const gcpAuthEndpoint = "oauth2.googleapis.com"
+
+// gcpReachable checks whether the node can connect to the GCP auth endpoint, and warns if it can not, eg. in an
+// air-gapped environment, as the pods would then fail to authenticate although the credentials are mounted
+func gcpReachable(r command.Runner) bool {
+	// any HTTP response will do, only the connection is checked
+	opts := []string{"-sS", "-m", "5", "-o", "/dev/null"}
+	proxy := os.Getenv("HTTPS_PROXY")
+	if proxy != "" && !strings.HasPrefix(proxy, "localhost") && !strings.HasPrefix(proxy, "127.0") {
+		opts = append([]string{"-x", proxy}, opts...)
+	}
+	opts = append(opts, "https://"+gcpAuthEndpoint+"/")
+	rr, err := r.RunCmd(exec.Command("curl", opts...))
+	if err == nil {
+		return true
+	}
+	klog.Warningf("%s failed: %v", rr.Args, err)
+	out.WarningT("The cluster can not reach {{.endpoint}}, the pods will fail to authenticate to GCP with the mounted credentials", out.V{"endpoint": gcpAuthEndpoint})
+	out.ErrT(style.Tip, "If the cluster is behind a proxy, see https://minikube.sigs.k8s.io/docs/reference/networking/proxy/, or skip this check with --force")
+	return false
+}
+
 func enableAddonGCPAuth(cfg *config.ClusterConfig) error {
+	// mustload.Running does not notice a paused control plane, the apiserver calls below would time out
+	if paused, err := IsPaused(cfg.Name); err != nil {
+		klog.Warningf("unable to check whether the cluster is paused: %v", err)
+	} else if paused {
+		return errors.New("the cluster is paused, unpause it first: minikube unpause")
+	}
+
 	// Grab command runner from running cluster
 	cc := mustload.Running(cfg.Name)
 	r := cc.CP.Runner
 
-	// Grab credentials from where GCP would normally look
+	// Grab credentials from the env var if set, else from where GCP would normally look
 	ctx := context.Background()
-	creds, err := google.FindDefaultCredentials(ctx)
+	creds, err := credentialsFromB64Env(ctx, os.Getenv(credentialsB64Env))
+	if err != nil {
+		return errors.Wrapf(err, "reading the credentials of %s", credentialsB64Env)
+	}
+	if creds == nil {
+		creds, err = google.FindDefaultCredentials(ctx)
+	}
 	if err != nil {
 		if detect.IsCloudShell() {
 			if c := os.Getenv("CLOUDSDK_CONFIG"); c != "" {
@@ -86,6 +130,11 @@ func enableAddonGCPAuth(cfg *config.ClusterConfig) error {
 		}
 	}
 
+	if creds != nil && creds.JSON != nil && !confirmCredentialsOverwrite(r, creds.JSON) {
+		out.Styled(style.Option, "Keeping the GCP credentials mounted in {{.name}}", out.V{"name": cfg.Name})
+		return ErrSkipThisAddon
+	}
+
 	// Patch service accounts for all namespaces to include the image pull secret.
 	// The image registry pull secret is added to the namespaces in the webhook.
 	if err := patchServiceAccounts(cfg); err != nil {
@@ -93,7 +142,7 @@ func enableAddonGCPAuth(cfg *config.ClusterConfig) error {
 	}
 
 	// If the env var is explicitly set, even in GCE, then defer to the user and continue
-	if !Force && detect.IsOnGCE() && os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" {
+	if !Force && detect.IsOnGCE() && os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" && os.Getenv(credentialsB64Env) == "" {
 		out.WarningT("It seems that you are running in GCE, which means authentication should work without the GCP Auth addon. If you would still like to authenticate using a credentials file, use the --force flag.")
 		return nil
 	}
@@ -103,6 +152,10 @@ func enableAddonGCPAuth(cfg *config.ClusterConfig) error {
 		return nil
 	}
 
+	if !Force {
+		gcpReachable(r)
+	}
+
 	// Actually copy the creds over
 	f := assets.NewMemoryAssetTarget(creds.JSON, credentialsPath, readPermission)
 
@@ -137,6 +190,62 @@ or set the GOOGLE_CLOUD_PROJECT environment variable.`)
 
 }
 
+// confirmCredentialsOverwrite returns whether the credentials can be written to the node: when none are mounted yet,
+// the mounted ones are the same, --force is set or the user confirms overwriting them. It warns and overwrites them
+// when minikube is not run interactively.
+func confirmCredentialsOverwrite(r command.Runner, creds []byte) bool {
+	rr, err := r.RunCmd(exec.Command("sudo", "cat", credentialsPath))
+	if err != nil {
+		// nothing mounted yet
+		return true
+	}
+	current := bytes.TrimSpace(rr.Stdout.Bytes())
+	if len(current) == 0 || bytes.Equal(current, bytes.TrimSpace(creds)) || Force {
+		return true
+	}
+	mounted, replacement := credentialsIdentity(current), credentialsIdentity(creds)
+	if ConfirmOverwrite == nil {
+		out.WarningT("Overwriting the GCP credentials mounted in the cluster ({{.mounted}}) with {{.new}}", out.V{"mounted": mounted, "new": replacement})
+		return true
+	}
+	return ConfirmOverwrite(fmt.Sprintf("The cluster already has other GCP credentials mounted (%s), do you want to overwrite them with %s?", mounted, replacement))
+}
+
+// credentialsIdentity describes whose the JSON credentials are: the email of a service account, else their type
+func credentialsIdentity(creds []byte) string {
+	var c struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+	}
+	if err := json.Unmarshal(creds, &c); err != nil {
+		return "invalid credentials"
+	}
+	if c.ClientEmail != "" {
+		return c.ClientEmail
+	}
+	if c.Type != "" {
+		return c.Type + " credentials"
+	}
+	return "unknown credentials"
+}
+
+// credentialsFromB64Env returns the credentials of the base64-encoded JSON value of credentialsB64Env,
+// or nil if the value is empty
+func credentialsFromB64Env(ctx context.Context, value string) (*google.Credentials, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding base64")
+	}
+	if !json.Valid(data) {
+		return nil, errors.New("the decoded credentials are not valid JSON")
+	}
+	return google.CredentialsFromJSON(ctx, data)
+}
+
 func patchServiceAccounts(cc *config.ClusterConfig) error {
 	client, err := service.K8s.GetCoreClient(cc.Name)
 	if err != nil {
@@ -186,27 +295,25 @@ func patchServiceAccounts(cc *config.ClusterConfig) error {
 	return nil
 }
 
-func refreshExistingPods(cc *config.ClusterConfig) error {
-	klog.Info("refreshing existing pods")
-	client, err := service.K8s.GetCoreClient(cc.Name)
-	if err != nil {
-		return fmt.Errorf("failed to get k8s client: %v", err)
-	}
+// gcpAuthReportLimit is the number of affected pods above which they are only listed with --report-all-pods
+const gcpAuthReportLimit = 50
 
+// gcpAuthAffectedPods returns the existing pods that would get the credentials mounted when recreated
+func gcpAuthAffectedPods(client typed_core.CoreV1Interface) ([]corev1.Pod, error) {
 	namespaces, err := client.Namespaces().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get namespaces: %v", err)
+		return nil, fmt.Errorf("failed to get namespaces: %v", err)
 	}
+	var affected []corev1.Pod
 	for _, n := range namespaces.Items {
 		// Ignore kube-system and gcp-auth namespaces
 		if skipNamespace(n.Name) {
 			continue
 		}
 
-		pods := client.Pods(n.Name)
-		podList, err := pods.List(context.TODO(), metav1.ListOptions{})
+		podList, err := client.Pods(n.Name).List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to list pods: %v", err)
+			return nil, fmt.Errorf("failed to list pods: %v", err)
 		}
 
 		for _, p := range podList.Items {
@@ -214,31 +321,73 @@ func refreshExistingPods(cc *config.ClusterConfig) error {
 			if _, ok := p.Labels["gcp-auth-skip-secret"]; ok {
 				continue
 			}
+			affected = append(affected, p)
+		}
+	}
+	return affected, nil
+}
 
-			klog.Infof("refreshing pod %q", p.Name)
+// reportAffectedPods lists the existing pods lacking the gcp-auth-skip-secret label
+func reportAffectedPods(cc *config.ClusterConfig) {
+	client, err := service.K8s.GetCoreClient(cc.Name)
+	if err != nil {
+		klog.Warningf("failed to get k8s client: %v", err)
+		return
+	}
+	affected, err := gcpAuthAffectedPods(client)
+	if err != nil {
+		klog.Warningf("failed to list the pods affected by gcp-auth: %v", err)
+		return
+	}
+	if len(affected) == 0 {
+		return
+	}
+	if len(affected) > gcpAuthReportLimit && !ReportAllPods {
+		out.Styled(style.Notice, "{{.count}} existing pods lack the `gcp-auth-skip-secret` label and will get your credentials when recreated, rerun addons enable with --report-all-pods to list them.", out.V{"count": len(affected)})
+		return
+	}
+	out.Styled(style.Notice, "These existing pods lack the `gcp-auth-skip-secret` label and will get your credentials when recreated:")
+	for _, p := range affected {
+		out.Styled(style.Option, "{{.namespace}}/{{.name}}", out.V{"namespace": p.Namespace, "name": p.Name})
+	}
+}
 
-			// Recreating the pod should pickup the necessary changes
-			err := pods.Delete(context.TODO(), p.Name, metav1.DeleteOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to delete pod %q: %v", p.Name, err)
-			}
+func refreshExistingPods(cc *config.ClusterConfig) error {
+	klog.Info("refreshing existing pods")
+	client, err := service.K8s.GetCoreClient(cc.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get k8s client: %v", err)
+	}
 
-			p.ResourceVersion = ""
+	affected, err := gcpAuthAffectedPods(client)
+	if err != nil {
+		return err
+	}
+	for _, p := range affected {
+		pods := client.Pods(p.Namespace)
+		klog.Infof("refreshing pod %q", p.Name)
 
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
+		// Recreating the pod should pickup the necessary changes
+		err := pods.Delete(context.TODO(), p.Name, metav1.DeleteOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to delete pod %q: %v", p.Name, err)
+		}
 
-			for err == nil {
-				if ctx.Err() == context.DeadlineExceeded {
-					return fmt.Errorf("pod %q failed to restart", p.Name)
-				}
-				_, err = pods.Get(context.TODO(), p.Name, metav1.GetOptions{})
-				time.Sleep(time.Second)
-			}
+		p.ResourceVersion = ""
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
 
-			if _, err := pods.Create(context.TODO(), &p, metav1.CreateOptions{}); err != nil {
-				return fmt.Errorf("failed to create pod %q: %v", p.Name, err)
+		for err == nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("pod %q failed to restart", p.Name)
 			}
+			_, err = pods.Get(context.TODO(), p.Name, metav1.GetOptions{})
+			time.Sleep(time.Second)
+		}
+
+		if _, err := pods.Create(context.TODO(), &p, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create pod %q: %v", p.Name, err)
 		}
 	}
 	return nil
@@ -329,6 +478,7 @@ func verifyGCPAuthAddon(cc *config.ClusterConfig, name, val string) error {
 		out.Styled(style.Notice, "Your GCP credentials will now be mounted into every pod created in the {{.name}} cluster.", out.V{"name": cc.Name})
 		out.Styled(style.Notice, "If you don't want your credentials mounted into a specific pod, add a label with the `gcp-auth-skip-secret` key to your pod configuration.")
 		if !Refresh {
+			reportAffectedPods(cc)
 			out.Styled(style.Notice, "If you want existing pods to be mounted with credentials, either recreate them or rerun addons enable with --refresh.")
 		}
 	}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestGCPAuthAffectedPods(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: metav1.NamespaceSystem}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "affected", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "skipped", Namespace: "default", Labels: map[string]string{"gcp-auth-skip-secret": "true"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: metav1.NamespaceSystem}},
+	}
+	client := fake.NewSimpleClientset(objects...).CoreV1()
+
+	affected, err := gcpAuthAffectedPods(client)
+	if err != nil {
+		t.Fatalf("gcpAuthAffectedPods returned unexpected error: %v", err)
+	}
+	if len(affected) != 1 || affected[0].Name != "affected" {
+		t.Errorf("expected only the affected pod, got %v", affected)
+	}
+}
+
+func TestCredentialsFromB64Env(t *testing.T) {
+	userCreds := `{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"token"}`
+	tests := []struct {
+		description string
+		value       string
+		wantCreds   bool
+		wantErr     bool
+	}{
+		{description: "unset"},
+		{description: "valid", value: base64.StdEncoding.EncodeToString([]byte(userCreds)), wantCreds: true},
+		{description: "trailing newline", value: base64.StdEncoding.EncodeToString([]byte(userCreds)) + "\n", wantCreds: true},
+		{description: "not base64", value: "not base64!", wantErr: true},
+		{description: "not JSON", value: base64.StdEncoding.EncodeToString([]byte("creds")), wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			creds, err := credentialsFromB64Env(context.Background(), tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("credentialsFromB64Env() expected error %t but got %v", tc.wantErr, err)
+			}
+			if (creds != nil) != tc.wantCreds {
+				t.Fatalf("credentialsFromB64Env() returned credentials %t, want %t", creds != nil, tc.wantCreds)
+			}
+			if creds != nil && string(creds.JSON) != userCreds {
+				t.Errorf("credentials JSON = %s, want %s", creds.JSON, userCreds)
+			}
+		})
+	}
+}
+
+func TestGCPReachable(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	curl := "curl -sS -m 5 -o /dev/null https://oauth2.googleapis.com/"
+
+	r := command.NewFakeCommandRunner()
+	r.SetCommandToOutput(map[string]string{curl: ""})
+	if !gcpReachable(r) {
+		t.Errorf("gcpReachable() = false, want true when curl succeeds")
+	}
+
+	r = command.NewFakeCommandRunner()
+	r.SetCommandToOutput(map[string]string{"true": ""})
+	if gcpReachable(r) {
+		t.Errorf("gcpReachable() = true, want false when curl fails")
+	}
+}
+
+func TestConfirmCredentialsOverwrite(t *testing.T) {
+	cat := "sudo cat " + credentialsPath
+	mounted := `{"type":"service_account","client_email":"old@project.iam.gserviceaccount.com"}`
+	replacement := `{"type":"service_account","client_email":"new@project.iam.gserviceaccount.com"}`
+	tests := []struct {
+		description string
+		mounted     *string
+		force       bool
+		confirm     func(string) bool
+		want        bool
+		wantAsked   bool
+	}{
+		{description: "nothing mounted", want: true},
+		{description: "same credentials", mounted: &replacement, confirm: func(string) bool { return false }, want: true},
+		{description: "confirmed", mounted: &mounted, confirm: func(string) bool { return true }, want: true, wantAsked: true},
+		{description: "declined", mounted: &mounted, confirm: func(string) bool { return false }, want: false, wantAsked: true},
+		{description: "forced", mounted: &mounted, force: true, confirm: func(string) bool { return false }, want: true},
+		{description: "not interactive", mounted: &mounted, want: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			r := command.NewFakeCommandRunner()
+			r.SetCommandToOutput(map[string]string{"true": ""})
+			if tc.mounted != nil {
+				r.SetCommandToOutput(map[string]string{cat: *tc.mounted + "\n"})
+			}
+			oldForce, oldConfirm := Force, ConfirmOverwrite
+			defer func() { Force, ConfirmOverwrite = oldForce, oldConfirm }()
+			Force = tc.force
+			ConfirmOverwrite = nil
+			asked := ""
+			if tc.confirm != nil {
+				ConfirmOverwrite = func(q string) bool {
+					asked = q
+					return tc.confirm(q)
+				}
+			}
+			if got := confirmCredentialsOverwrite(r, []byte(replacement)); got != tc.want {
+				t.Errorf("confirmCredentialsOverwrite() = %t, want %t", got, tc.want)
+			}
+			if (asked != "") != tc.wantAsked {
+				t.Errorf("expected to be asked %t, got question %q", tc.wantAsked, asked)
+			}
+			if tc.wantAsked && (!strings.Contains(asked, "old@project") || !strings.Contains(asked, "new@project")) {
+				t.Errorf("expected the question to name both identities, got %q", asked)
+			}
+		})
+	}
+}
+
+func TestCredentialsIdentity(t *testing.T) {
+	tests := []struct {
+		creds string
+		want  string
+	}{
+		{creds: `{"type":"service_account","client_email":"sa@project.iam.gserviceaccount.com"}`, want: "sa@project.iam.gserviceaccount.com"},
+		{creds: `{"type":"authorized_user","client_id":"id"}`, want: "authorized_user credentials"},
+		{creds: `{}`, want: "unknown credentials"},
+		{creds: `creds`, want: "invalid credentials"},
+	}
+	for _, tc := range tests {
+		if got := credentialsIdentity([]byte(tc.creds)); got != tc.want {
+			t.Errorf("credentialsIdentity(%s) = %q, want %q", tc.creds, got, tc.want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package addons
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -115,6 +116,46 @@ func TestSetAndSave(t *testing.T) {
 	}
 }
 
+func TestSetAndSaveWithConfig(t *testing.T) {
+	profile := createTestProfile(t)
+
+	// a failing configure must not save anything
+	failing := func(cc *config.ClusterConfig) error {
+		cc.MetalLB = config.DefaultMetalLBConfig("10.0.0.1", "10.0.0.10")
+		return fmt.Errorf("invalid config")
+	}
+	if err := SetAndSaveWithConfig(profile, "dashboard", "true", failing); err == nil {
+		t.Errorf("expected an error from a failing configure")
+	}
+
+	c, err := config.DefaultLoader.LoadConfigFromFile(profile)
+	if err != nil {
+		t.Errorf("unable to load profile: %v", err)
+	}
+	if c.Addons["dashboard"] || len(c.MetalLB.Pools) != 0 {
+		t.Errorf("expected profile to be unchanged, got addons %v and metallb pools %v", c.Addons, c.MetalLB.Pools)
+	}
+
+	configure := func(cc *config.ClusterConfig) error {
+		cc.MetalLB = config.DefaultMetalLBConfig("10.0.0.1", "10.0.0.10")
+		return nil
+	}
+	if err := SetAndSaveWithConfig(profile, "dashboard", "true", configure); err != nil {
+		t.Errorf("SetAndSaveWithConfig returned unexpected error: %v", err)
+	}
+
+	c, err = config.DefaultLoader.LoadConfigFromFile(profile)
+	if err != nil {
+		t.Errorf("unable to load profile: %v", err)
+	}
+	if !c.Addons["dashboard"] {
+		t.Errorf("expected dashboard to be enabled")
+	}
+	if len(c.MetalLB.Pools) != 1 || c.MetalLB.Pools[0].Ranges[0] != "10.0.0.1-10.0.0.10" {
+		t.Errorf("expected metallb pool to be saved, got %v", c.MetalLB.Pools)
+	}
+}
+
 func TestStartWithAddonsEnabled(t *testing.T) {
 	// this test will write a config.json into MinikubeHome, create a temp dir for it
 	tests.MakeTempDir(t)
@@ -160,3 +201,19 @@ func TestStartWithAllAddonsDisabled(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigurableConfigMaps(t *testing.T) {
+	for name, cm := range ConfigurableConfigMaps {
+		if _, ok := assets.Addons[name]; !ok {
+			t.Errorf("%s is not a known addon", name)
+		}
+		if cm.Namespace == "" || cm.Name == "" || cm.Deployment == "" || len(cm.Values) == 0 {
+			t.Errorf("%s: ConfigMap must have a namespace, a name, a deployment and values", name)
+		}
+		for _, v := range cm.Values {
+			if v.Key == "" || v.Prompt == "" || v.Validate == nil {
+				t.Errorf("%s: value %q must have a key, a prompt and a validation", name, v.Key)
+			}
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package addons
 
 import (
+	"encoding/json"
+
 	"k8s.io/minikube/pkg/minikube/config"
 )
 
@@ -39,6 +41,41 @@ var addonPodLabels = map[string]string{
 	"csi-hostpath-driver": "kubernetes.io/minikube-addons=csi-hostpath-driver",
 }
 
+// ConfigMapValue is a key of an addon ConfigMap which can be set by addons configure
+type ConfigMapValue struct {
+	Key      string
+	Prompt   string
+	Validate func(string) bool
+}
+
+// ConfigurableConfigMap is an addon ConfigMap with keys which can be set by addons configure
+type ConfigurableConfigMap struct {
+	Namespace string
+	Name      string
+	// Deployment is the deployment of the namespace reading the ConfigMap, restarted once it is patched
+	Deployment string
+	Values     []ConfigMapValue
+}
+
+// ConfigurableConfigMaps holds the settings ConfigMaps patched by the configure flow of their addon.
+// Only keys absent from the addon manifests may be listed, re-enabling the addon would revert the others.
+var ConfigurableConfigMaps = map[string]ConfigurableConfigMap{
+	"dashboard": {
+		Namespace:  "kubernetes-dashboard",
+		Name:       "kubernetes-dashboard-settings",
+		Deployment: "kubernetes-dashboard",
+		Values: []ConfigMapValue{
+			{
+				Key:    "_global",
+				Prompt: "-- Enter global dashboard settings as JSON (ex. {\"clusterName\":\"minikube\",\"itemsPerPage\":25}): ",
+				Validate: func(s string) bool {
+					return json.Valid([]byte(s))
+				},
+			},
+		},
+	},
+}
+
 // Addons is a list of all addons
 var Addons = []*Addon{
 	{
@@ -161,7 +161,7 @@ var Addons = map[string]*Addon{
 	}),
 	"default-storageclass": NewAddon([]*BinAsset{
 		MustBinAsset(addons.DefaultStorageClassAssets,
-			"storageclass/storageclass.yaml",
+			"storageclass/storageclass.yaml.tmpl",
 			vmpath.GuestAddonsDir,
 			"storageclass.yaml",
 			"0640"),
@@ -244,7 +244,7 @@ var Addons = map[string]*Addon{
 			"fluentd-es-rc.yaml",
 			"0640"),
 		MustBinAsset(addons.EfkAssets,
-			"efk/fluentd-es-configmap.yaml",
+			"efk/fluentd-es-configmap.yaml.tmpl",
 			vmpath.GuestAddonsDir,
 			"fluentd-es-configmap.yaml",
 			"0640"),
@@ -903,6 +903,13 @@ func SelectAndPersistImages(addon *Addon, cc *config.ClusterConfig) (images, cus
 	return images, customRegistries, nil
 }
 
+// ingressBackendService returns the namespace/service of a namespace/service:port default backend,
+// as the ingress controller only takes the service and serves its first port
+func ingressBackendService(backend string) string {
+	svc, _, _ := strings.Cut(backend, ":")
+	return svc
+}
+
 // GenerateTemplateData generates template data for template assets
 func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo NetworkInfo, images, customRegistries map[string]string, enable bool) interface{} {
 	cfg := cc.KubernetesConfig
@@ -925,9 +932,12 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		Arch                    string
 		ExoticArch              string
 		ImageRepository         string
-		LoadBalancerStartIP     string
-		LoadBalancerEndIP       string
+		MetalLB                 config.MetalLBConfig
+		StorageProvisioner      config.StorageProvisionerConfig
+		Logging                 config.LoggingConfig
 		CustomIngressCert       string
+		CustomIngressController string
+		CustomIngressBackend    string
 		IngressAPIVersion       string
 		ContainerRuntime        string
 		RegistryAliases         string
@@ -939,28 +949,43 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		LegacyPodSecurityPolicy bool
 		LegacyRuntimeClass      bool
 		AutoPauseInterval       time.Duration
+		AutoPauseComponents     string
+		AutoPauseWarmup         time.Duration
+		MetricsServer           config.MetricsServerConfig
+		CloudSpanner            config.CloudSpannerConfig
+		RegistryCredsRefresh    int
+		DashboardTokenAuth      bool
 	}{
-		KubernetesVersion:      make(map[string]uint64),
-		PreOneTwentyKubernetes: false,
-		Arch:                   a,
-		ExoticArch:             ea,
-		ImageRepository:        cfg.ImageRepository,
-		LoadBalancerStartIP:    cfg.LoadBalancerStartIP,
-		LoadBalancerEndIP:      cfg.LoadBalancerEndIP,
-		CustomIngressCert:      cfg.CustomIngressCert,
-		RegistryAliases:        cfg.RegistryAliases,
-		IngressAPIVersion:      "v1", // api version for ingress (eg, "v1beta1"; defaults to "v1" for k8s 1.19+)
-		ContainerRuntime:       cfg.ContainerRuntime,
-		Images:                 images,
-		Registries:             addon.Registries,
-		CustomRegistries:       customRegistries,
-		NetworkInfo:            make(map[string]string),
+		KubernetesVersion:       make(map[string]uint64),
+		PreOneTwentyKubernetes:  false,
+		Arch:                    a,
+		ExoticArch:              ea,
+		ImageRepository:         cfg.ImageRepository,
+		MetalLB:                 cc.MetalLB,
+		StorageProvisioner:      cc.StorageProvisioner,
+		Logging:                 cc.Logging,
+		CustomIngressCert:       cfg.CustomIngressCert,
+		CustomIngressController: cfg.CustomIngressController,
+		CustomIngressBackend:    ingressBackendService(cfg.CustomIngressBackend),
+		RegistryAliases:         cfg.RegistryAliases,
+		IngressAPIVersion:       "v1", // api version for ingress (eg, "v1beta1"; defaults to "v1" for k8s 1.19+)
+		ContainerRuntime:        cfg.ContainerRuntime,
+		Images:                  images,
+		Registries:              addon.Registries,
+		CustomRegistries:        customRegistries,
+		NetworkInfo:             make(map[string]string),
 		Environment: map[string]string{
 			"MockGoogleToken": os.Getenv("MOCK_GOOGLE_TOKEN"),
 		},
 		LegacyPodSecurityPolicy: v.LT(semver.Version{Major: 1, Minor: 25}),
 		LegacyRuntimeClass:      v.LT(semver.Version{Major: 1, Minor: 25}),
 		AutoPauseInterval:       cc.AutoPauseInterval,
+		AutoPauseComponents:     strings.Join(cc.AutoPauseComponents, ","),
+		AutoPauseWarmup:         cc.AutoPauseWarmup,
+		MetricsServer:           cc.MetricsServer,
+		CloudSpanner:            cc.CloudSpanner,
+		RegistryCredsRefresh:    int(cc.RegistryCredsRefresh / time.Minute), // the controller takes minutes
+		DashboardTokenAuth:      cc.DashboardTokenAuth,
 	}
 	if opts.ImageRepository != "" && !strings.HasSuffix(opts.ImageRepository, "/") {
 		opts.ImageRepository += "/"
@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -44,6 +45,78 @@ func Pause(cr cruntime.Manager, r command.Runner, namespaces []string) ([]string
 
 // pause pauses a Kubernetes cluster
 func pause(cr cruntime.Manager, r command.Runner, namespaces []string) ([]string, error) {
+	opts := []cruntime.ListContainersOptions{{State: cruntime.Running, Namespaces: namespaces}}
+	return pauseContainers(cr, r, opts, doesNamespaceContainKubeSystem(namespaces))
+}
+
+// PausableComponents are the control plane components which can be paused, they are containers of the kube-system namespace
+var PausableComponents = []string{"kube-apiserver", "etcd", "kube-controller-manager", "kube-scheduler", "kube-proxy", "coredns"}
+
+// CheckPauseComponents checks the control plane components can be paused together.
+// The kubelet is always stopped with them, so it is not a component: left running, its liveness probes would fail
+// on the paused containers and it would kill them. With the kubelet stopped the node turns NotReady, and a running
+// kube-controller-manager would evict its pods, so it must be paused too unless the apiserver it talks to is.
+func CheckPauseComponents(components []string) error {
+	manager := false
+	for _, c := range components {
+		if c == "kubelet" {
+			return errors.New("the kubelet is always stopped with the paused components, it is not one of them")
+		}
+		if !isPausableComponent(c) {
+			return errors.Errorf("unknown component %q, expected one of %s", c, strings.Join(PausableComponents, ", "))
+		}
+		manager = manager || c == "kube-apiserver" || c == "kube-controller-manager"
+	}
+	if !manager {
+		return errors.New("kube-controller-manager or kube-apiserver must be paused, otherwise the pods of the node are evicted once the stopped kubelet makes it NotReady")
+	}
+	return nil
+}
+
+// isPausableComponent returns whether c is one of PausableComponents
+func isPausableComponent(c string) bool {
+	for _, p := range PausableComponents {
+		if c == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PauseComponents pauses the given control plane components, retrying if necessary.
+// All of the kube-system containers are paused when no component is given, and the kubelet is always stopped.
+func PauseComponents(cr cruntime.Manager, r command.Runner, components []string) ([]string, error) {
+	if len(components) == 0 {
+		return Pause(cr, r, []string{"kube-system"})
+	}
+	if err := CheckPauseComponents(components); err != nil {
+		return nil, err
+	}
+
+	apiserver := false
+	var opts []cruntime.ListContainersOptions
+	for _, c := range components {
+		if c == "kube-apiserver" {
+			apiserver = true
+		}
+		opts = append(opts, cruntime.ListContainersOptions{State: cruntime.Running, Name: c, Namespaces: []string{"kube-system"}})
+	}
+
+	var ids []string
+	tryPause := func() (err error) {
+		ids, err = pauseContainers(cr, r, opts, apiserver)
+		return err
+	}
+
+	if err := retry.Expo(tryPause, 250*time.Millisecond, 2*time.Second); err != nil {
+		return ids, err
+	}
+	return ids, nil
+}
+
+// pauseContainers disables the kubelet and pauses the running containers matching any of opts,
+// and marks the apiserver as paused if requested
+func pauseContainers(cr cruntime.Manager, r command.Runner, opts []cruntime.ListContainersOptions, markPaused bool) ([]string, error) {
 	ids := []string{}
 
 	// Disable the kubelet so it does not attempt to restart paused pods
@@ -54,9 +127,12 @@ func pause(cr cruntime.Manager, r command.Runner, namespaces []string) ([]string
 		return ids, errors.Wrap(err, "kubelet disable --now")
 	}
 
-	ids, err := cr.ListContainers(cruntime.ListContainersOptions{State: cruntime.Running, Namespaces: namespaces})
-	if err != nil {
-		return ids, errors.Wrap(err, "list running")
+	for _, o := range opts {
+		matching, err := cr.ListContainers(o)
+		if err != nil {
+			return ids, errors.Wrap(err, "list running")
+		}
+		ids = append(ids, matching...)
 	}
 
 	if len(ids) == 0 {
@@ -68,7 +144,7 @@ func pause(cr cruntime.Manager, r command.Runner, namespaces []string) ([]string
 		return ids, errors.Wrap(err, "pausing containers")
 	}
 
-	if doesNamespaceContainKubeSystem(namespaces) {
+	if markPaused {
 		pkgpause.CreatePausedFile(r)
 	}
 
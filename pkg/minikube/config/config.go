@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
@@ -227,12 +228,40 @@ func (c *simpleConfigLoader) LoadConfigFromFile(profileName string, miniHome ...
 	if err := json.Unmarshal(data, &cc); err != nil {
 		return nil, errors.Wrap(err, "unmarshal")
 	}
+	migrateMetalLBConfig(&cc)
 	return &cc, nil
 }
 
+// migrateMetalLBConfig copies the load balancer range of profiles written before MetalLBConfig to its default pool
+func migrateMetalLBConfig(cc *ClusterConfig) {
+	k := &cc.KubernetesConfig
+	if k.LoadBalancerStartIP == "" && k.LoadBalancerEndIP == "" {
+		return
+	}
+	if len(cc.MetalLB.Pools) == 0 {
+		cc.MetalLB = DefaultMetalLBConfig(k.LoadBalancerStartIP, k.LoadBalancerEndIP)
+	}
+}
+
+// legacyMetalLBRange sets the deprecated load balancer range from the first range of the metallb pools, or clears it
+// if that range is not a START-END range. It is still written so that a downgraded minikube keeps the range.
+func legacyMetalLBRange(cc *ClusterConfig) {
+	k := &cc.KubernetesConfig
+	k.LoadBalancerStartIP, k.LoadBalancerEndIP = "", ""
+	if len(cc.MetalLB.Pools) == 0 || len(cc.MetalLB.Pools[0].Ranges) == 0 {
+		return
+	}
+	if start, end, ok := strings.Cut(cc.MetalLB.Pools[0].Ranges[0], "-"); ok {
+		k.LoadBalancerStartIP, k.LoadBalancerEndIP = start, end
+	}
+}
+
 func (c *simpleConfigLoader) WriteConfigToFile(profileName string, cc *ClusterConfig, miniHome ...string) error {
 	path := profileFilePath(profileName, miniHome...)
-	contents, err := json.MarshalIndent(cc, "", "	")
+	saved := *cc
+	migrateMetalLBConfig(&saved)
+	legacyMetalLBRange(&saved)
+	contents, err := json.MarshalIndent(&saved, "", "	")
 	if err != nil {
 		return err
 	}
@@ -241,3 +241,39 @@ func TestMachineName(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggingConfig(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		scheme   string
+		host     string
+		port     string
+	}{
+		{endpoint: "https://logs.example.com:9200", scheme: "https", host: "logs.example.com", port: "9200"},
+		{endpoint: "https://logs.example.com", scheme: "https", host: "logs.example.com", port: "443"},
+		{endpoint: "http://10.0.0.5", scheme: "http", host: "10.0.0.5", port: "80"},
+		{endpoint: "http://[fd00::5]:9200/", scheme: "http", host: "fd00::5", port: "9200"},
+	}
+	for _, tc := range tests {
+		l := LoggingConfig{Endpoint: tc.endpoint}
+		if l.Scheme() != tc.scheme || l.Host() != tc.host || l.Port() != tc.port {
+			t.Errorf("LoggingConfig{%q} = %s, %s, %s, want %s, %s, %s", tc.endpoint, l.Scheme(), l.Host(), l.Port(), tc.scheme, tc.host, tc.port)
+		}
+	}
+}
+
+func TestMetalLBConfigProtocol(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: "", want: MetalLBModeL2},
+		{mode: MetalLBModeL2, want: MetalLBModeL2},
+		{mode: MetalLBModeBGP, want: MetalLBModeBGP},
+	}
+	for _, tc := range tests {
+		if got := (MetalLBConfig{Mode: tc.mode}).Protocol(); got != tc.want {
+			t.Errorf("MetalLBConfig{Mode: %q}.Protocol() = %q, want %q", tc.mode, got, tc.want)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
@@ -140,7 +141,10 @@ func SaveNode(cfg *ClusterConfig, node *Node) error {
 
 // SaveProfile creates an profile out of the cfg and stores in $MINIKUBE_HOME/profiles/<profilename>/config.json
 func SaveProfile(name string, cfg *ClusterConfig, miniHome ...string) error {
-	data, err := json.MarshalIndent(cfg, "", "    ")
+	saved := *cfg
+	migrateMetalLBConfig(&saved)
+	legacyMetalLBRange(&saved)
+	data, err := json.MarshalIndent(&saved, "", "    ")
 	if err != nil {
 		return err
 	}
@@ -185,6 +189,69 @@ func DeleteProfile(profile string, miniHome ...string) error {
 	return os.RemoveAll(ProfileFolderPath(profile, miniPath))
 }
 
+// profileBackupsPath returns the directory holding the config backups of a profile
+func profileBackupsPath(profile string, miniHome ...string) string {
+	return filepath.Join(ProfileFolderPath(profile, miniHome...), "backups")
+}
+
+// BackupProfile copies the config of a profile to a timestamped file in its backups directory.
+// It returns the path of the backup, or an empty path if the profile has no config yet.
+func BackupProfile(name string, miniHome ...string) (string, error) {
+	data, err := os.ReadFile(profileFilePath(name, miniHome...))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	dir := profileBackupsPath(name, miniHome...)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("config-%s.json", time.Now().UTC().Format("20060102-150405.000000")))
+	klog.Infof("Backing up config to %s ...", path)
+	if err := lock.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ListProfileBackups returns the names of the config backups of a profile, oldest first
+func ListProfileBackups(name string, miniHome ...string) ([]string, error) {
+	entries, err := os.ReadDir(profileBackupsPath(name, miniHome...))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			backups = append(backups, e.Name())
+		}
+	}
+	return backups, nil
+}
+
+// LoadProfileBackup loads a config backup of a profile, as listed by ListProfileBackups
+func LoadProfileBackup(name, backup string, miniHome ...string) (*ClusterConfig, error) {
+	if filepath.Base(backup) != backup {
+		return nil, fmt.Errorf("invalid backup name %q", backup)
+	}
+	data, err := os.ReadFile(filepath.Join(profileBackupsPath(name, miniHome...), backup))
+	if err != nil {
+		return nil, err
+	}
+	cc := &ClusterConfig{}
+	if err := json.Unmarshal(data, cc); err != nil {
+		return nil, fmt.Errorf("unmarshal backup %s: %w", backup, err)
+	}
+	migrateMetalLBConfig(cc)
+	return cc, nil
+}
+
 // DockerContainers lists all containers created by docker driver
 var DockerContainers = func() ([]string, error) {
 	return oci.ListOwnedContainers(oci.Docker)
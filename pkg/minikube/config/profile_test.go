@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -266,6 +267,77 @@ func TestDeleteProfile(t *testing.T) {
 
 }
 
+func TestProfileBackups(t *testing.T) {
+	miniDir := t.TempDir()
+
+	path, err := BackupProfile("backup_prof", miniDir)
+	if err != nil || path != "" {
+		t.Fatalf("expected no backup of a missing profile, got %q and error %v", path, err)
+	}
+
+	cc := &ClusterConfig{Name: "backup_prof", CPUs: 2}
+	if err := SaveProfile("backup_prof", cc, miniDir); err != nil {
+		t.Fatalf("error saving profile: %v", err)
+	}
+	if _, err := BackupProfile("backup_prof", miniDir); err != nil {
+		t.Fatalf("error backing up profile: %v", err)
+	}
+	cc.CPUs = 4
+	if err := SaveProfile("backup_prof", cc, miniDir); err != nil {
+		t.Fatalf("error saving profile: %v", err)
+	}
+
+	backups, err := ListProfileBackups("backup_prof", miniDir)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected a single backup, got %v and error %v", backups, err)
+	}
+	restored, err := LoadProfileBackup("backup_prof", backups[0], miniDir)
+	if err != nil {
+		t.Fatalf("error loading backup: %v", err)
+	}
+	if restored.CPUs != 2 {
+		t.Errorf("expected the backup to have 2 CPUs, got %d", restored.CPUs)
+	}
+
+	if _, err := LoadProfileBackup("backup_prof", "../config.json", miniDir); err == nil {
+		t.Errorf("expected an error loading a backup outside of the backups directory")
+	}
+}
+
+func TestMigrateMetalLBConfig(t *testing.T) {
+	miniDir := t.TempDir()
+
+	legacy := &ClusterConfig{Name: "metallb_prof", KubernetesConfig: KubernetesConfig{LoadBalancerStartIP: "10.0.0.1", LoadBalancerEndIP: "10.0.0.10"}}
+	if err := SaveProfile("metallb_prof", legacy, miniDir); err != nil {
+		t.Fatalf("error saving profile: %v", err)
+	}
+
+	cc, err := DefaultLoader.LoadConfigFromFile("metallb_prof", miniDir)
+	if err != nil {
+		t.Fatalf("error loading profile: %v", err)
+	}
+	if len(cc.MetalLB.Pools) != 1 || cc.MetalLB.Pools[0].Name != "default" || len(cc.MetalLB.Pools[0].Ranges) != 1 || cc.MetalLB.Pools[0].Ranges[0] != "10.0.0.1-10.0.0.10" {
+		t.Errorf("expected the range to be migrated to the default pool, got %+v", cc.MetalLB)
+	}
+
+	// the range is still saved in the deprecated fields, so a downgraded minikube keeps it
+	cc.MetalLB = DefaultMetalLBConfig("10.0.0.20", "10.0.0.30")
+	if err := SaveProfile("metallb_prof", cc, miniDir); err != nil {
+		t.Fatalf("error saving profile: %v", err)
+	}
+	data, err := os.ReadFile(profileFilePath("metallb_prof", miniDir))
+	if err != nil {
+		t.Fatalf("error reading profile: %v", err)
+	}
+	var saved ClusterConfig
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("error unmarshaling profile: %v", err)
+	}
+	if saved.KubernetesConfig.LoadBalancerStartIP != "10.0.0.20" || saved.KubernetesConfig.LoadBalancerEndIP != "10.0.0.30" {
+		t.Errorf("expected the legacy range to be saved as 10.0.0.20-10.0.0.30, got %s-%s", saved.KubernetesConfig.LoadBalancerStartIP, saved.KubernetesConfig.LoadBalancerEndIP)
+	}
+}
+
 func TestGetPrimaryControlPlane(t *testing.T) {
 	miniDir, err := filepath.Abs("./testdata/.minikube")
 	if err != nil {
@@ -18,6 +18,7 @@ package config
 
 import (
 	"net"
+	"net/url"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -108,29 +109,40 @@ type ClusterConfig struct {
 	SSHAuthSock             string
 	SSHAgentPID             int
 	GPUs                    string
-	AutoPauseInterval       time.Duration // Specifies interval of time to wait before checking if cluster should be paused
+	AutoPauseInterval       time.Duration            // Specifies interval of time to wait before checking if cluster should be paused
+	AutoPauseComponents     []string                 // components paused by the auto-pause addon, all of them if empty
+	AutoPauseWarmup         time.Duration            // minimum time the auto-pause addon keeps the cluster running after it starts
+	MetricsServer           MetricsServerConfig      // used by metrics-server addon
+	RegistryCredsRefresh    time.Duration            // used by registry-creds addon, how often the controller refreshes the credentials, its default if 0
+	DashboardTokenAuth      bool                     // used by dashboard addon, requires a token to log in instead of allowing to skip the login
+	MetalLB                 MetalLBConfig            // used by metallb addon
+	StorageProvisioner      StorageProvisionerConfig // used by storage-provisioner and default-storageclass addons
+	Logging                 LoggingConfig            // used by efk addon
+	CloudSpanner            CloudSpannerConfig       // used by cloud-spanner addon
 }
 
 // KubernetesConfig contains the parameters used to configure the VM Kubernetes.
 type KubernetesConfig struct {
-	KubernetesVersion   string
-	ClusterName         string
-	Namespace           string
-	APIServerName       string
-	APIServerNames      []string
-	APIServerIPs        []net.IP
-	DNSDomain           string
-	ContainerRuntime    string
-	CRISocket           string
-	NetworkPlugin       string
-	FeatureGates        string // https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
-	ServiceCIDR         string // the subnet which Kubernetes services will be deployed to
-	ImageRepository     string
-	LoadBalancerStartIP string // currently only used by MetalLB addon
-	LoadBalancerEndIP   string // currently only used by MetalLB addon
-	CustomIngressCert   string // used by Ingress addon
-	RegistryAliases     string // currently only used by registry-aliases addon
-	ExtraOptions        ExtraOptionSlice
+	KubernetesVersion       string
+	ClusterName             string
+	Namespace               string
+	APIServerName           string
+	APIServerNames          []string
+	APIServerIPs            []net.IP
+	DNSDomain               string
+	ContainerRuntime        string
+	CRISocket               string
+	NetworkPlugin           string
+	FeatureGates            string // https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
+	ServiceCIDR             string // the subnet which Kubernetes services will be deployed to
+	ImageRepository         string
+	LoadBalancerStartIP     string // Deprecated: use ClusterConfig.MetalLB, still saved for older minikube versions
+	LoadBalancerEndIP       string // Deprecated: use ClusterConfig.MetalLB, still saved for older minikube versions
+	CustomIngressCert       string // used by Ingress addon
+	CustomIngressController string // namespace/deployment of the controller using CustomIngressCert, empty for the Ingress addon controller
+	CustomIngressBackend    string // namespace/service:port serving the requests matching no ingress rule, used by Ingress addon
+	RegistryAliases         string // currently only used by registry-aliases addon
+	ExtraOptions            ExtraOptionSlice
 
 	ShouldLoadCachedImages bool
 
@@ -185,3 +197,121 @@ type ScheduledStopConfig struct {
 	InitiationTime int64
 	Duration       time.Duration
 }
+
+// MetricsServerConfig contains the resources and scrape interval used by the metrics-server addon
+// empty values fall back to the defaults in the addon manifest
+type MetricsServerConfig struct {
+	CPURequest       string
+	MemoryRequest    string
+	CPULimit         string
+	MemoryLimit      string
+	MetricResolution time.Duration
+}
+
+// CloudSpannerConfig contains the ports and the default project and instance of the cloud-spanner emulator addon
+// empty values fall back to the defaults in the addon manifest
+type CloudSpannerConfig struct {
+	GRPCPort int    // port of the gRPC API, 9010 if 0
+	HTTPPort int    // port of the REST API, 9020 if 0
+	Project  string // default project of the clients, given in the cloud-spanner-emulator ConfigMap
+	Instance string // default instance of the clients, given in the cloud-spanner-emulator ConfigMap
+}
+
+// StorageProvisionerConfig contains the settings of the storage-provisioner and default-storageclass addons
+// empty values fall back to the defaults in the addon manifests
+type StorageProvisionerConfig struct {
+	ReclaimPolicy string // reclaim policy of the default StorageClass, Retain or Delete
+	HostPath      string // directory of the node the volumes are provisioned in
+}
+
+// LoggingConfig contains the Elasticsearch or OpenSearch endpoint the efk addon ships the logs to
+// an empty endpoint keeps the elasticsearch deployed by the addon
+type LoggingConfig struct {
+	Endpoint    string // URL of the endpoint, eg. https://logs.example.com:9200
+	Credentials bool   // authenticate with the user and password of the efk-elasticsearch-credentials secret
+}
+
+// Scheme returns the scheme of the endpoint, http or https
+func (l LoggingConfig) Scheme() string {
+	u, err := url.Parse(l.Endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme
+}
+
+// Host returns the host of the endpoint, without its port
+func (l LoggingConfig) Host() string {
+	u, err := url.Parse(l.Endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// Port returns the port of the endpoint, the default port of its scheme if it has none
+func (l LoggingConfig) Port() string {
+	u, err := url.Parse(l.Endpoint)
+	if err != nil {
+		return ""
+	}
+	if p := u.Port(); p != "" {
+		return p
+	}
+	if u.Scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// MetalLBConfig contains the address pools of the metallb addon
+type MetalLBConfig struct {
+	Pools []MetalLBPool
+	Mode  string        // how the addresses are advertised, MetalLBModeL2 if empty
+	Peers []MetalLBPeer // the BGP routers the addresses are advertised to, only used with MetalLBModeBGP
+	// Controller and Speaker are the resources of the metallb containers
+	Controller MetalLBResources
+	Speaker    MetalLBResources
+}
+
+// MetalLBResources contains the resources of a metallb container
+// empty values fall back to the defaults in the addon manifest
+type MetalLBResources struct {
+	CPURequest    string
+	MemoryRequest string
+	CPULimit      string
+	MemoryLimit   string
+}
+
+const (
+	// MetalLBModeL2 advertises the addresses by answering ARP/NDP requests on the node network
+	MetalLBModeL2 = "layer2"
+	// MetalLBModeBGP advertises the addresses to the BGP peers
+	MetalLBModeBGP = "bgp"
+)
+
+// MetalLBPeer is a BGP router metallb advertises the addresses to
+type MetalLBPeer struct {
+	Address string
+	ASN     uint32
+	MyASN   uint32
+}
+
+// Protocol returns the protocol the address pools are advertised with
+func (m MetalLBConfig) Protocol() string {
+	if m.Mode == MetalLBModeBGP {
+		return MetalLBModeBGP
+	}
+	return MetalLBModeL2
+}
+
+// MetalLBPool is a named pool of addresses metallb assigns to the LoadBalancer services
+type MetalLBPool struct {
+	Name   string
+	Ranges []string // each range is either "START-END" or a CIDR
+}
+
+// DefaultMetalLBConfig returns the metallb config with a single range in the default pool
+func DefaultMetalLBConfig(startIP, endIP string) MetalLBConfig {
+	return MetalLBConfig{Pools: []MetalLBPool{{Name: "default", Ranges: []string{startIP + "-" + endIP}}}}
+}
@@ -69,3 +69,9 @@ func PrintWarning(warning string) {
 	w := NewWarning(warning)
 	printAndRecordCloudEvent(w, w.data)
 }
+
+// ConfigureEvent returns a Configure type in JSON format, to be emitted wherever configure sends its events
+func ConfigureEvent(addon, profile, action, target, outcome, message string) ([]byte, error) {
+	c := NewConfigure(addon, profile, action, target, outcome, message)
+	return CloudEvent(c, c.data).MarshalJSON()
+}
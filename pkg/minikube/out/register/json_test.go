@@ -117,3 +117,18 @@ func TestWarning(t *testing.T) {
 
 	tests.CompareJSON(t, actual, []byte(expected))
 }
+
+func TestConfigureEvent(t *testing.T) {
+	expected := `{"data":{"action":"secret-created","addon":"registry-creds","message":"","outcome":"success","profile":"minikube","target":"kube-system/registry-creds-ecr"},"datacontenttype":"application/json","id":"random-id","source":"https://minikube.sigs.k8s.io/","specversion":"1.0","type":"io.k8s.sigs.minikube.configure"}`
+
+	GetUUID = func() string {
+		return "random-id"
+	}
+
+	actual, err := ConfigureEvent("registry-creds", "minikube", "secret-created", "kube-system/registry-creds-ecr", "success", "")
+	if err != nil {
+		t.Fatalf("ConfigureEvent() unexpected error: %v", err)
+	}
+
+	tests.CompareJSON(t, actual, []byte(expected))
+}
@@ -22,7 +22,7 @@ import (
 )
 
 // Log represents the different types of logs that can be output as JSON
-// This includes: Step, Download, DownloadProgress, Warning, Info, Error
+// This includes: Step, Download, DownloadProgress, Warning, Info, Error, Configure
 type Log interface {
 	Type() string
 }
@@ -154,3 +154,27 @@ func NewErrorExitCode(err string, exitcode int, additionalData ...map[string]str
 func (s *Error) Type() string {
 	return "io.k8s.sigs.minikube.error"
 }
+
+// Configure will be used to report a change applied by addons configure
+type Configure struct {
+	data map[string]string
+}
+
+// NewConfigure returns a new Configure type
+func NewConfigure(addon, profile, action, target, outcome, message string) *Configure {
+	return &Configure{
+		map[string]string{
+			"addon":   addon,
+			"profile": profile,
+			"action":  action,
+			"target":  target,
+			"outcome": outcome,
+			"message": strings.TrimSpace(message),
+		},
+	}
+}
+
+// Type returns the cloud events compatible type of this struct
+func (s *Configure) Type() string {
+	return "io.k8s.sigs.minikube.configure"
+}
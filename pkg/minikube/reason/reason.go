@@ -89,6 +89,12 @@ var (
 	InternalAddonEnablePaused = Kind{ID: "MK_ADDON_ENABLE_PAUSED", ExitCode: ExProgramConflict}
 	// minikube could not disable an addon on a paused cluster
 	InternalAddonDisablePaused = Kind{ID: "MK_ADDON_DISABLE_PAUSED", ExitCode: ExProgramConflict}
+	// minikube could not configure an addon, e.g. registry-creds addon
+	InternalAddonConfigure = Kind{ID: "MK_ADDON_CONFIGURE", ExitCode: ExProgramError}
+	// minikube could not configure an addon as the cluster could not be reached
+	InternalAddonConfigureUnavailable = Kind{ID: "MK_ADDON_CONFIGURE_UNAVAILABLE", ExitCode: ExControlPlaneUnavailable}
+	// minikube could not create the secret of an addon, e.g. registry-creds addon
+	InternalAddonConfigureSecret = Kind{ID: "MK_ADDON_CONFIGURE_SECRET", ExitCode: ExControlPlaneError}
 
 	// minikube failed to update internal configuration, such as the cached images config map
 	InternalAddConfig = Kind{ID: "MK_ADD_CONFIG", ExitCode: ExProgramError}
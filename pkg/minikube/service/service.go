@@ -19,6 +19,7 @@ package service
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
@@ -28,13 +29,24 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/docker/machine/libmachine"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	typed_apps "k8s.io/client-go/kubernetes/typed/apps/v1"
 	typed_core "k8s.io/client-go/kubernetes/typed/core/v1"
+	typed_networking "k8s.io/client-go/kubernetes/typed/networking/v1"
+	typed_rbac "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	clientretry "k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/machine"
@@ -89,7 +101,7 @@ type URLs []SvcURL
 
 // GetServiceURLs returns a SvcURL object for every service in a particular namespace.
 // Accepts a template for formatting
-func GetServiceURLs(api libmachine.API, cname string, namespace string, t *template.Template) (URLs, error) {
+func GetServiceURLs(ctx context.Context, api libmachine.API, cname string, namespace string, t *template.Template) (URLs, error) {
 	host, err := machine.LoadHost(api, cname)
 	if err != nil {
 		return nil, err
@@ -107,14 +119,14 @@ func GetServiceURLs(api libmachine.API, cname string, namespace string, t *templ
 
 	serviceInterface := client.Services(namespace)
 
-	svcs, err := serviceInterface.List(context.Background(), meta.ListOptions{})
+	svcs, err := serviceInterface.List(ctx, meta.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
 
 	var serviceURLs []SvcURL
 	for _, svc := range svcs.Items {
-		svcURL, err := printURLsForService(client, ip, svc.Name, svc.Namespace, t)
+		svcURL, err := printURLsForService(ctx, client, ip, svc.Name, svc.Namespace, t)
 		if err != nil {
 			return nil, err
 		}
@@ -125,7 +137,7 @@ func GetServiceURLs(api libmachine.API, cname string, namespace string, t *templ
 }
 
 // GetServiceURLsForService returns a SvcURL object for a service in a namespace. Supports optional formatting.
-func GetServiceURLsForService(api libmachine.API, cname string, namespace, service string, t *template.Template) (SvcURL, error) {
+func GetServiceURLsForService(ctx context.Context, api libmachine.API, cname string, namespace, service string, t *template.Template) (SvcURL, error) {
 	host, err := machine.LoadHost(api, cname)
 	if err != nil {
 		return SvcURL{}, errors.Wrap(err, "Error checking if api exist and loading it")
@@ -141,20 +153,20 @@ func GetServiceURLsForService(api libmachine.API, cname string, namespace, servi
 		return SvcURL{}, err
 	}
 
-	return printURLsForService(client, ip, service, namespace, t)
+	return printURLsForService(ctx, client, ip, service, namespace, t)
 }
 
-func printURLsForService(c typed_core.CoreV1Interface, ip, service, namespace string, t *template.Template) (SvcURL, error) {
+func printURLsForService(ctx context.Context, c typed_core.CoreV1Interface, ip, service, namespace string, t *template.Template) (SvcURL, error) {
 	if t == nil {
 		return SvcURL{}, errors.New("Error, attempted to generate service url with nil --format template")
 	}
 
-	svc, err := c.Services(namespace).Get(context.Background(), service, meta.GetOptions{})
+	svc, err := c.Services(namespace).Get(ctx, service, meta.GetOptions{})
 	if err != nil {
 		return SvcURL{}, errors.Wrapf(err, "service '%s' could not be found running", service)
 	}
 
-	endpoints, err := c.Endpoints(namespace).Get(context.Background(), service, meta.GetOptions{})
+	endpoints, err := c.Endpoints(namespace).Get(ctx, service, meta.GetOptions{})
 	m := make(map[int32]string)
 	if err == nil && endpoints != nil && len(endpoints.Subsets) > 0 {
 		for _, ept := range endpoints.Subsets {
@@ -196,13 +208,13 @@ func printURLsForService(c typed_core.CoreV1Interface, ip, service, namespace st
 }
 
 // CheckService checks if a service is listening on a port.
-func CheckService(cname string, namespace string, service string) error {
+func CheckService(ctx context.Context, cname string, namespace string, service string) error {
 	client, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return errors.Wrap(err, "Error getting Kubernetes client")
 	}
 
-	svc, err := client.Services(namespace).Get(context.Background(), service, meta.GetOptions{})
+	svc, err := client.Services(namespace).Get(ctx, service, meta.GetOptions{})
 	if err != nil {
 		return &retry.RetriableError{
 			Err: errors.Wrapf(err, "Error getting service %s", service),
@@ -253,7 +265,7 @@ func (t SVCNotFoundError) Error() string {
 }
 
 // WaitForService waits for a service, and return the urls when available
-func WaitForService(api libmachine.API, cname string, namespace string, service string, urlTemplate *template.Template, urlMode bool, https bool,
+func WaitForService(ctx context.Context, api libmachine.API, cname string, namespace string, service string, urlTemplate *template.Template, urlMode bool, https bool,
 	wait int, interval int) ([]string, error) {
 	var urlList []string
 	// Convert "Amount of time to wait" and "interval of each check" to attempts
@@ -261,13 +273,22 @@ func WaitForService(api libmachine.API, cname string, namespace string, service
 		interval = 1
 	}
 
-	chkSVC := func() error { return CheckService(cname, namespace, service) }
+	chkSVC := func() error {
+		// stop retrying once the caller gave up, eg. on interrupt
+		if err := ctx.Err(); err != nil {
+			return backoff.Permanent(err)
+		}
+		return CheckService(ctx, cname, namespace, service)
+	}
 
 	if err := retry.Expo(chkSVC, time.Duration(interval)*time.Second, time.Duration(wait)*time.Second); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		return nil, &SVCNotFoundError{err}
 	}
 
-	serviceURL, err := GetServiceURLsForService(api, cname, namespace, service, urlTemplate)
+	serviceURL, err := GetServiceURLsForService(ctx, api, cname, namespace, service, urlTemplate)
 	if err != nil {
 		return urlList, errors.Wrap(err, "Check that minikube is running and that you have specified the correct namespace")
 	}
@@ -295,17 +316,17 @@ func WaitForService(api libmachine.API, cname string, namespace string, service
 }
 
 // GetServiceListByLabel returns a ServiceList by label
-func GetServiceListByLabel(cname string, namespace string, key string, value string) (*core.ServiceList, error) {
+func GetServiceListByLabel(ctx context.Context, cname string, namespace string, key string, value string) (*core.ServiceList, error) {
 	client, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return &core.ServiceList{}, &retry.RetriableError{Err: err}
 	}
-	return getServiceListFromServicesByLabel(client.Services(namespace), key, value)
+	return getServiceListFromServicesByLabel(ctx, client.Services(namespace), key, value)
 }
 
-func getServiceListFromServicesByLabel(services typed_core.ServiceInterface, key string, value string) (*core.ServiceList, error) {
+func getServiceListFromServicesByLabel(ctx context.Context, services typed_core.ServiceInterface, key string, value string) (*core.ServiceList, error) {
 	selector := labels.SelectorFromSet(labels.Set(map[string]string{key: value}))
-	serviceList, err := services.List(context.Background(), meta.ListOptions{LabelSelector: selector.String()})
+	serviceList, err := services.List(ctx, meta.ListOptions{LabelSelector: selector.String()})
 	if err != nil {
 		return &core.ServiceList{}, &retry.RetriableError{Err: err}
 	}
@@ -313,22 +334,53 @@ func getServiceListFromServicesByLabel(services typed_core.ServiceInterface, key
 	return serviceList, nil
 }
 
+// EnsureNamespace creates the namespace if it does not exist yet
+func EnsureNamespace(ctx context.Context, cname string, namespace string) error {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return ensureNamespace(ctx, client.Namespaces(), namespace)
+}
+
+func ensureNamespace(ctx context.Context, namespaces typed_core.NamespaceInterface, namespace string) error {
+	_, err := namespaces.Get(ctx, namespace, meta.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return &retry.RetriableError{Err: err}
+	}
+
+	klog.Infof("Creating namespace %s", namespace)
+	ns := &core.Namespace{
+		ObjectMeta: meta.ObjectMeta{
+			Name: namespace,
+		},
+	}
+	_, err = namespaces.Create(ctx, ns, meta.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return &retry.RetriableError{Err: err}
+	}
+	return nil
+}
+
 // CreateSecret creates or modifies secrets
-func CreateSecret(cname string, namespace, name string, dataValues map[string]string, labels map[string]string) error {
+func CreateSecret(ctx context.Context, cname string, namespace, name string, dataValues map[string]string, labels map[string]string) error {
 	client, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return &retry.RetriableError{Err: err}
 	}
 
 	secrets := client.Secrets(namespace)
-	secret, err := secrets.Get(context.Background(), name, meta.GetOptions{})
+	secret, err := secrets.Get(ctx, name, meta.GetOptions{})
 	if err != nil {
 		klog.Infof("Failed to retrieve existing secret: %v", err)
 	}
 
 	// Delete existing secret
 	if len(secret.Name) > 0 {
-		err = DeleteSecret(cname, namespace, name)
+		err = DeleteSecret(ctx, cname, namespace, name)
 		if err != nil {
 			return &retry.RetriableError{Err: err}
 		}
@@ -350,7 +402,7 @@ func CreateSecret(cname string, namespace, name string, dataValues map[string]st
 		Type: core.SecretTypeOpaque,
 	}
 
-	_, err = secrets.Create(context.Background(), secretObj, meta.CreateOptions{})
+	_, err = secrets.Create(ctx, secretObj, meta.CreateOptions{})
 	if err != nil {
 		return &retry.RetriableError{Err: err}
 	}
@@ -359,29 +411,282 @@ func CreateSecret(cname string, namespace, name string, dataValues map[string]st
 }
 
 // DeleteSecret deletes a secret from a namespace
-func DeleteSecret(cname string, namespace, name string) error {
+func DeleteSecret(ctx context.Context, cname string, namespace, name string) error {
 	client, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return &retry.RetriableError{Err: err}
 	}
 
 	secrets := client.Secrets(namespace)
-	err = secrets.Delete(context.Background(), name, meta.DeleteOptions{})
+	err = secrets.Delete(ctx, name, meta.DeleteOptions{})
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+
+	return nil
+}
+
+// CreateServiceAccountToken creates the service account if needed and returns a long-lived token for it
+func CreateServiceAccountToken(ctx context.Context, cname, namespace, serviceAccount string) (string, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return "", &retry.RetriableError{Err: err}
+	}
+	if err := ensureServiceAccount(ctx, client.ServiceAccounts(namespace), serviceAccount); err != nil {
+		return "", err
+	}
+
+	secrets := client.Secrets(namespace)
+	name := serviceAccount + "-token"
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{core.ServiceAccountNameKey: serviceAccount},
+		},
+		Type: core.SecretTypeServiceAccountToken,
+	}
+	if _, err := secrets.Create(ctx, secret, meta.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", &retry.RetriableError{Err: err}
+	}
+
+	// the token controller fills in the token asynchronously
+	var token string
+	getToken := func(ctx context.Context) (bool, error) {
+		s, err := secrets.Get(ctx, name, meta.GetOptions{})
+		if err != nil {
+			klog.Infof("token of %s is not available yet: %v", name, err)
+			return false, nil
+		}
+		token = string(s.Data[core.ServiceAccountTokenKey])
+		return token != "", nil
+	}
+	if err := wait.PollUntilContextTimeout(ctx, time.Second, 30*time.Second, true, getToken); err != nil {
+		return "", errors.Wrapf(err, "waiting for the token of %s", name)
+	}
+	return token, nil
+}
+
+func ensureServiceAccount(ctx context.Context, serviceAccounts typed_core.ServiceAccountInterface, name string) error {
+	_, err := serviceAccounts.Get(ctx, name, meta.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return &retry.RetriableError{Err: err}
+	}
+
+	klog.Infof("Creating service account %s", name)
+	sa := &core.ServiceAccount{
+		ObjectMeta: meta.ObjectMeta{
+			Name: name,
+		},
+	}
+	_, err = serviceAccounts.Create(ctx, sa, meta.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return &retry.RetriableError{Err: err}
+	}
+	return nil
+}
+
+// DeleteServiceAccountToken deletes the token secret created by CreateServiceAccountToken and its service account,
+// ignoring those which do not exist
+func DeleteServiceAccountToken(ctx context.Context, cname, namespace, serviceAccount string) error {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return deleteServiceAccountToken(ctx, client, namespace, serviceAccount)
+}
+
+func deleteServiceAccountToken(ctx context.Context, client typed_core.CoreV1Interface, namespace, serviceAccount string) error {
+	name := serviceAccount + "-token"
+	if err := client.Secrets(namespace).Delete(ctx, name, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "delete secret %s", name)}
+	}
+	if err := client.ServiceAccounts(namespace).Delete(ctx, serviceAccount, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "delete service account %s", serviceAccount)}
+	}
+	return nil
+}
+
+// CreateClusterRoleBinding binds the cluster role to the service account of the namespace, unless the binding exists
+func CreateClusterRoleBinding(ctx context.Context, cname, name, clusterRole, namespace, serviceAccount string, labels map[string]string) error {
+	client, err := kapi.Client(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return createClusterRoleBinding(ctx, client.RbacV1().ClusterRoleBindings(), name, clusterRole, namespace, serviceAccount, labels)
+}
+
+func createClusterRoleBinding(ctx context.Context, bindings typed_rbac.ClusterRoleBindingInterface, name, clusterRole, namespace, serviceAccount string, labels map[string]string) error {
+	binding := &rbac.ClusterRoleBinding{
+		ObjectMeta: meta.ObjectMeta{Name: name, Labels: labels},
+		RoleRef:    rbac.RoleRef{APIGroup: rbac.GroupName, Kind: "ClusterRole", Name: clusterRole},
+		Subjects:   []rbac.Subject{{Kind: rbac.ServiceAccountKind, Name: serviceAccount, Namespace: namespace}},
+	}
+	if _, err := bindings.Create(ctx, binding, meta.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "create cluster role binding %s", name)}
+	}
+	return nil
+}
+
+// DeleteClusterRoleBinding deletes the cluster role binding, if it exists
+func DeleteClusterRoleBinding(ctx context.Context, cname, name string) error {
+	client, err := kapi.Client(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return deleteClusterRoleBinding(ctx, client.RbacV1().ClusterRoleBindings(), name)
+}
+
+func deleteClusterRoleBinding(ctx context.Context, bindings typed_rbac.ClusterRoleBindingInterface, name string) error {
+	if err := bindings.Delete(ctx, name, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "delete cluster role binding %s", name)}
+	}
+	return nil
+}
+
+// PatchConfigMap sets the given keys of a ConfigMap, leaving its other keys untouched
+func PatchConfigMap(ctx context.Context, cname, namespace, name string, data map[string]string) error {
+	client, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return &retry.RetriableError{Err: err}
 	}
+	return patchConfigMap(ctx, client.ConfigMaps(namespace), name, data)
+}
 
+func patchConfigMap(ctx context.Context, configMaps typed_core.ConfigMapInterface, name string, data map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+	if _, err := configMaps.Patch(ctx, name, types.MergePatchType, patch, meta.PatchOptions{}); err != nil {
+		return &retry.RetriableError{Err: err}
+	}
 	return nil
 }
 
+// GetConfigMapData returns the data of a ConfigMap in a namespace, or nil if the ConfigMap was not found
+func GetConfigMapData(ctx context.Context, cname, namespace, name string) (map[string]string, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return nil, &retry.RetriableError{Err: err}
+	}
+	return getConfigMapData(ctx, client.ConfigMaps(namespace), name)
+}
+
+func getConfigMapData(ctx context.Context, configMaps typed_core.ConfigMapInterface, name string) (map[string]string, error) {
+	cm, err := configMaps.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get configmap %s", name)
+	}
+	return cm.Data, nil
+}
+
+// GetServicePorts returns the ports of a service in a namespace, or nil if the service was not found
+func GetServicePorts(ctx context.Context, cname, namespace, name string) ([]core.ServicePort, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return nil, &retry.RetriableError{Err: err}
+	}
+	return getServicePorts(ctx, client.Services(namespace), name)
+}
+
+func getServicePorts(ctx context.Context, services typed_core.ServiceInterface, name string) ([]core.ServicePort, error) {
+	svc, err := services.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get service %s", name)
+	}
+	return svc.Spec.Ports, nil
+}
+
+// CheckSecretExists checks whether a secret exists in a namespace, trying again on transient apiserver errors.
+// It returns false and no error only if the secret was not found.
+func CheckSecretExists(ctx context.Context, cname, namespace, name string) (bool, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return false, &retry.RetriableError{Err: err}
+	}
+	return checkSecretExists(ctx, client.Secrets(namespace), name)
+}
+
+func checkSecretExists(ctx context.Context, secrets typed_core.SecretInterface, name string) (bool, error) {
+	secret, err := getSecret(ctx, secrets, name)
+	return secret != nil, err
+}
+
+// GetSecretData returns the data of a secret in a namespace, or nil if the secret was not found
+func GetSecretData(ctx context.Context, cname, namespace, name string) (map[string]string, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return nil, &retry.RetriableError{Err: err}
+	}
+	return getSecretData(ctx, client.Secrets(namespace), name)
+}
+
+func getSecretData(ctx context.Context, secrets typed_core.SecretInterface, name string) (map[string]string, error) {
+	secret, err := getSecret(ctx, secrets, name)
+	if err != nil || secret == nil {
+		return nil, err
+	}
+	data := map[string]string{}
+	for k, v := range secret.Data {
+		data[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		data[k] = v
+	}
+	return data, nil
+}
+
+// secretRetryTimeout bounds the time spent retrying to get a secret on transient apiserver errors
+var secretRetryTimeout = 10 * time.Second
+
+// getSecret gets a secret, trying again on transient errors so that an apiserver hiccup is not taken for a missing secret.
+// It returns nil and no error only if the secret was not found.
+func getSecret(ctx context.Context, secrets typed_core.SecretInterface, name string) (*core.Secret, error) {
+	var secret *core.Secret
+	get := func() error {
+		s, err := secrets.Get(ctx, name, meta.GetOptions{})
+		switch {
+		case err == nil:
+			secret = s
+			return nil
+		case apierrors.IsNotFound(err):
+			return nil
+		case isTransientAPIError(err) && ctx.Err() == nil:
+			return &retry.RetriableError{Err: err}
+		default:
+			return backoff.Permanent(errors.Wrapf(err, "get secret %s", name))
+		}
+	}
+	if err := retry.Expo(get, 250*time.Millisecond, secretRetryTimeout); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// isTransientAPIError checks whether an apiserver request may succeed when tried again, eg. while the apiserver restarts
+func isTransientAPIError(err error) bool {
+	return apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err) ||
+		utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
 // check whether there are running pods for a service
-func CheckServicePods(cname, svcName, namespace string) error {
+func CheckServicePods(ctx context.Context, cname, svcName, namespace string) error {
 	clientset, err := K8s.GetCoreClient(cname)
 	if err != nil {
 		return errors.Wrap(err, "failed to get k8s client")
 	}
 
-	svc, err := clientset.Services(namespace).Get(context.Background(), svcName, meta.GetOptions{})
+	svc, err := clientset.Services(namespace).Get(ctx, svcName, meta.GetOptions{})
 	if err != nil {
 		return errors.Wrap(err, "Get service")
 	}
@@ -392,7 +697,7 @@ func CheckServicePods(cname, svcName, namespace string) error {
 	if svc.Spec.Type != core.ServiceTypeNodePort && svc.Spec.Type != core.ServiceTypeLoadBalancer {
 		return nil
 	}
-	pods, err := clientset.Pods(namespace).List(context.Background(), meta.ListOptions{
+	pods, err := clientset.Pods(namespace).List(ctx, meta.ListOptions{
 		LabelSelector: labels.Set(svc.Spec.Selector).AsSelector().String(),
 	})
 	if err != nil {
@@ -406,3 +711,277 @@ func CheckServicePods(cname, svcName, namespace string) error {
 	}
 	return fmt.Errorf("no running pod for service %s found", svcName)
 }
+
+// GetPodLogs returns the last lines of the logs of the first pod matching the label selector
+func GetPodLogs(ctx context.Context, cname, namespace, selector string, tailLines int64) (string, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get k8s client")
+	}
+	return getPodLogs(ctx, client.Pods(namespace), selector, tailLines)
+}
+
+func getPodLogs(ctx context.Context, pods typed_core.PodInterface, selector string, tailLines int64) (string, error) {
+	podList, err := pods.List(ctx, meta.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", errors.Wrap(err, "List Pods")
+	}
+	if len(podList.Items) == 0 {
+		return "", fmt.Errorf("no pod found for label selector %s", selector)
+	}
+
+	logs, err := pods.GetLogs(podList.Items[0].Name, &core.PodLogOptions{TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "Get logs of %s", podList.Items[0].Name)
+	}
+	return string(logs), nil
+}
+
+// ExposeContainerPorts adds the ports to the first container of a deployment, skipping the ones it already exposes
+func ExposeContainerPorts(ctx context.Context, cname, namespace, name string, ports []core.ContainerPort) error {
+	client, err := kapi.Client(cname)
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client")
+	}
+	return exposeContainerPorts(ctx, client.AppsV1().Deployments(namespace), name, ports)
+}
+
+func exposeContainerPorts(ctx context.Context, deployments typed_apps.DeploymentInterface, name string, ports []core.ContainerPort) error {
+	d, err := deployments.Get(ctx, name, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get deployment %s", name)
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("deployment %s has no containers", name)
+	}
+	c := &d.Spec.Template.Spec.Containers[0]
+	added := false
+	for _, p := range ports {
+		if hasContainerPort(c.Ports, p) {
+			continue
+		}
+		c.Ports = append(c.Ports, p)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+	if _, err := deployments.Update(ctx, d, meta.UpdateOptions{}); err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "update deployment %s", name)}
+	}
+	return nil
+}
+
+// hasContainerPort checks whether ports already has a port with the same number and protocol as p
+func hasContainerPort(ports []core.ContainerPort, p core.ContainerPort) bool {
+	for _, existing := range ports {
+		protocol := existing.Protocol
+		if protocol == "" {
+			protocol = core.ProtocolTCP
+		}
+		if existing.ContainerPort == p.ContainerPort && protocol == p.Protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// RestartDeployment restarts the pods of a deployment like kubectl rollout restart, by changing an annotation of its pod template
+func RestartDeployment(ctx context.Context, cname, namespace, name string) error {
+	client, err := kapi.Client(cname)
+	if err != nil {
+		return errors.Wrap(err, "failed to get k8s client")
+	}
+	return restartDeployment(ctx, client.AppsV1().Deployments(namespace), name, time.Now())
+}
+
+func restartDeployment(ctx context.Context, deployments typed_apps.DeploymentInterface, name string, now time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{"kubectl.kubernetes.io/restartedAt": now.Format(time.RFC3339)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := deployments.Patch(ctx, name, types.StrategicMergePatchType, patch, meta.PatchOptions{}); err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "restart deployment %s", name)}
+	}
+	return nil
+}
+
+// ApplyLimitRange creates the LimitRange, or replaces the spec of the existing one
+func ApplyLimitRange(ctx context.Context, cname, namespace, name string, spec core.LimitRangeSpec) error {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return applyLimitRange(ctx, client.LimitRanges(namespace), name, spec)
+}
+
+func applyLimitRange(ctx context.Context, limitRanges typed_core.LimitRangeInterface, name string, spec core.LimitRangeSpec) error {
+	lr, err := limitRanges.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lr = &core.LimitRange{ObjectMeta: meta.ObjectMeta{Name: name}, Spec: spec}
+		if _, err := limitRanges.Create(ctx, lr, meta.CreateOptions{}); err != nil {
+			return &retry.RetriableError{Err: errors.Wrapf(err, "create limitrange %s", name)}
+		}
+		return nil
+	}
+	if err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "get limitrange %s", name)}
+	}
+	lr.Spec = spec
+	if _, err := limitRanges.Update(ctx, lr, meta.UpdateOptions{}); err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "update limitrange %s", name)}
+	}
+	return nil
+}
+
+// CreateIngress creates the Ingress, or replaces the spec of the existing one
+func CreateIngress(ctx context.Context, cname, namespace, name string, spec networking.IngressSpec) error {
+	client, err := kapi.Client(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return createIngress(ctx, client.NetworkingV1().Ingresses(namespace), name, spec)
+}
+
+func createIngress(ctx context.Context, ingresses typed_networking.IngressInterface, name string, spec networking.IngressSpec) error {
+	ing, err := ingresses.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ing = &networking.Ingress{ObjectMeta: meta.ObjectMeta{Name: name}, Spec: spec}
+		if _, err := ingresses.Create(ctx, ing, meta.CreateOptions{}); err != nil {
+			return &retry.RetriableError{Err: errors.Wrapf(err, "create ingress %s", name)}
+		}
+		return nil
+	}
+	if err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "get ingress %s", name)}
+	}
+	ing.Spec = spec
+	if _, err := ingresses.Update(ctx, ing, meta.UpdateOptions{}); err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "update ingress %s", name)}
+	}
+	return nil
+}
+
+// ApplyResourceQuota creates the ResourceQuota, or replaces the spec of the existing one
+func ApplyResourceQuota(ctx context.Context, cname, namespace, name string, spec core.ResourceQuotaSpec) error {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return &retry.RetriableError{Err: err}
+	}
+	return applyResourceQuota(ctx, client.ResourceQuotas(namespace), name, spec)
+}
+
+func applyResourceQuota(ctx context.Context, quotas typed_core.ResourceQuotaInterface, name string, spec core.ResourceQuotaSpec) error {
+	q, err := quotas.Get(ctx, name, meta.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		q = &core.ResourceQuota{ObjectMeta: meta.ObjectMeta{Name: name}, Spec: spec}
+		if _, err := quotas.Create(ctx, q, meta.CreateOptions{}); err != nil {
+			return &retry.RetriableError{Err: errors.Wrapf(err, "create resourcequota %s", name)}
+		}
+		return nil
+	}
+	if err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "get resourcequota %s", name)}
+	}
+	q.Spec = spec
+	if _, err := quotas.Update(ctx, q, meta.UpdateOptions{}); err != nil {
+		return &retry.RetriableError{Err: errors.Wrapf(err, "update resourcequota %s", name)}
+	}
+	return nil
+}
+
+// NodeChanges holds the labels and taints to set on nodes, and those to remove
+type NodeChanges struct {
+	Labels       map[string]string
+	RemoveLabels []string
+	// Taints replace the existing taints of the same key and effect
+	Taints []core.Taint
+	// RemoveTaints are matched by key, and by effect if it is set
+	RemoveTaints []core.Taint
+}
+
+// UpdateNodes applies the changes to the named nodes, or to all the nodes if names is empty. It returns the names of the updated nodes.
+func UpdateNodes(ctx context.Context, cname string, names []string, changes NodeChanges) ([]string, error) {
+	client, err := K8s.GetCoreClient(cname)
+	if err != nil {
+		return nil, &retry.RetriableError{Err: err}
+	}
+	return updateNodes(ctx, client.Nodes(), names, changes)
+}
+
+func updateNodes(ctx context.Context, nodes typed_core.NodeInterface, names []string, changes NodeChanges) ([]string, error) {
+	if len(names) == 0 {
+		list, err := nodes.List(ctx, meta.ListOptions{})
+		if err != nil {
+			return nil, &retry.RetriableError{Err: errors.Wrap(err, "list nodes")}
+		}
+		for _, n := range list.Items {
+			names = append(names, n.Name)
+		}
+	}
+	var updated []string
+	for _, name := range names {
+		// the node is read again on a conflict, as the kubelet updates its status concurrently
+		var getErr error
+		err := clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+			n, err := nodes.Get(ctx, name, meta.GetOptions{})
+			if err != nil {
+				getErr = errors.Wrapf(err, "get node %s", name)
+				return getErr
+			}
+			applyNodeChanges(n, changes)
+			_, err = nodes.Update(ctx, n, meta.UpdateOptions{})
+			return err
+		})
+		if getErr != nil {
+			return updated, getErr
+		}
+		if err != nil {
+			return updated, &retry.RetriableError{Err: errors.Wrapf(err, "update node %s", name)}
+		}
+		updated = append(updated, name)
+	}
+	return updated, nil
+}
+
+// applyNodeChanges sets and removes the labels and taints of the node
+func applyNodeChanges(n *core.Node, changes NodeChanges) {
+	if n.Labels == nil {
+		n.Labels = map[string]string{}
+	}
+	for k, v := range changes.Labels {
+		n.Labels[k] = v
+	}
+	for _, k := range changes.RemoveLabels {
+		delete(n.Labels, k)
+	}
+
+	removed := func(t core.Taint) bool {
+		for _, r := range changes.RemoveTaints {
+			if r.Key == t.Key && (r.Effect == "" || r.Effect == t.Effect) {
+				return true
+			}
+		}
+		for _, s := range changes.Taints {
+			if s.Key == t.Key && s.Effect == t.Effect {
+				return true
+			}
+		}
+		return false
+	}
+	var taints []core.Taint
+	for _, t := range n.Spec.Taints {
+		if !removed(t) {
+			taints = append(taints, t)
+		}
+	}
+	n.Spec.Taints = append(taints, changes.Taints...)
+}
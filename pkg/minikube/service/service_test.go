@@ -31,9 +31,16 @@ import (
 	"github.com/docker/machine/libmachine/host"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	typed_core "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	testing_fake "k8s.io/client-go/testing"
@@ -285,11 +292,11 @@ func TestGetServiceListFromServicesByLabel(t *testing.T) {
 	serviceIface := MockServiceInterface{
 		ServiceList: serviceList,
 	}
-	if _, err := getServiceListFromServicesByLabel(&serviceIface, "nothing", "nothing"); err != nil {
+	if _, err := getServiceListFromServicesByLabel(context.Background(), &serviceIface, "nothing", "nothing"); err != nil {
 		t.Fatalf("Service had no label match, but getServiceListFromServicesByLabel returned an error")
 	}
 
-	if _, err := getServiceListFromServicesByLabel(&serviceIface, "foo", "bar"); err != nil {
+	if _, err := getServiceListFromServicesByLabel(context.Background(), &serviceIface, "foo", "bar"); err != nil {
 		t.Fatalf("Endpoint was ready with at least one Address, but getServiceListFromServicesByLabel returned an error")
 	}
 }
@@ -345,7 +352,7 @@ func TestPrintURLsForService(t *testing.T) {
 		test := test
 		t.Run(test.description, func(t *testing.T) {
 			t.Parallel()
-			svcURL, err := printURLsForService(client, "127.0.0.1", test.serviceName, test.namespace, test.tmpl)
+			svcURL, err := printURLsForService(context.Background(), client, "127.0.0.1", test.serviceName, test.namespace, test.tmpl)
 			if err != nil && !test.err {
 				t.Errorf("Error: %v", err)
 			}
@@ -475,7 +482,7 @@ func TestGetServiceURLs(t *testing.T) {
 				servicesMap:  serviceNamespaces,
 				endpointsMap: endpointNamespaces,
 			}
-			urls, err := GetServiceURLs(test.api, "minikube", test.namespace, defaultTemplate)
+			urls, err := GetServiceURLs(context.Background(), test.api, "minikube", test.namespace, defaultTemplate)
 			if err != nil && !test.err {
 				t.Errorf("Error GetServiceURLs %v", err)
 			}
@@ -543,7 +550,7 @@ func TestGetServiceURLsForService(t *testing.T) {
 				servicesMap:  serviceNamespaces,
 				endpointsMap: endpointNamespaces,
 			}
-			svcURL, err := GetServiceURLsForService(test.api, "minikube", test.namespace, test.service, defaultTemplate)
+			svcURL, err := GetServiceURLsForService(context.Background(), test.api, "minikube", test.namespace, test.service, defaultTemplate)
 			if err != nil && !test.err {
 				t.Errorf("Error GetServiceURLsForService %v", err)
 			}
@@ -682,7 +689,7 @@ func TestGetServiceListByLabel(t *testing.T) {
 				secretsMap:   secretsNamespaces,
 			}
 			getCoreClientFail = test.failedGetClient
-			svcs, err := GetServiceListByLabel("minikube", test.ns, test.name, test.label)
+			svcs, err := GetServiceListByLabel(context.Background(), "minikube", test.ns, test.name, test.label)
 			if err != nil && !test.err {
 				t.Fatalf("Test %v got unexpected error: %v", test.description, err)
 			}
@@ -732,7 +739,7 @@ func TestCheckService(t *testing.T) {
 				secretsMap:   secretsNamespaces,
 			}
 			getCoreClientFail = test.failedGetClient
-			err := CheckService("minikube", test.ns, test.name)
+			err := CheckService(context.Background(), "minikube", test.ns, test.name)
 			if err == nil && test.err {
 				t.Fatalf("Test %v expected error but got nil", test.description)
 			}
@@ -771,7 +778,7 @@ func TestDeleteSecret(t *testing.T) {
 				secretsMap:   secretsNamespaces,
 			}
 			getCoreClientFail = test.failedGetClient
-			err := DeleteSecret("minikube", test.ns, test.name)
+			err := DeleteSecret(context.Background(), "minikube", test.ns, test.name)
 			if err == nil && test.err {
 				t.Fatalf("Test %v expected error but got nil", test.description)
 			}
@@ -782,6 +789,198 @@ func TestDeleteSecret(t *testing.T) {
 	}
 }
 
+func TestEnsureServiceAccount(t *testing.T) {
+	serviceAccounts := k8sfake.NewSimpleClientset().CoreV1().ServiceAccounts("kubernetes-dashboard")
+
+	// creating it twice must not fail
+	for i := 0; i < 2; i++ {
+		if err := ensureServiceAccount(context.Background(), serviceAccounts, "dashboard-admin"); err != nil {
+			t.Fatalf("ensureServiceAccount returned unexpected error: %v", err)
+		}
+	}
+	if _, err := serviceAccounts.Get(context.Background(), "dashboard-admin", meta.GetOptions{}); err != nil {
+		t.Errorf("expected service account to exist: %v", err)
+	}
+}
+
+func TestDeleteServiceAccountToken(t *testing.T) {
+	sa := &core.ServiceAccount{ObjectMeta: meta.ObjectMeta{Name: "dashboard-admin", Namespace: "kubernetes-dashboard"}}
+	token := &core.Secret{ObjectMeta: meta.ObjectMeta{Name: "dashboard-admin-token", Namespace: "kubernetes-dashboard"}}
+	client := k8sfake.NewSimpleClientset(sa, token).CoreV1()
+
+	// deleting them twice must not fail
+	for i := 0; i < 2; i++ {
+		if err := deleteServiceAccountToken(context.Background(), client, "kubernetes-dashboard", "dashboard-admin"); err != nil {
+			t.Fatalf("deleteServiceAccountToken returned unexpected error: %v", err)
+		}
+	}
+	if _, err := client.Secrets("kubernetes-dashboard").Get(context.Background(), "dashboard-admin-token", meta.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the token secret to be deleted, got %v", err)
+	}
+	if _, err := client.ServiceAccounts("kubernetes-dashboard").Get(context.Background(), "dashboard-admin", meta.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the service account to be deleted, got %v", err)
+	}
+}
+
+func TestClusterRoleBinding(t *testing.T) {
+	bindings := k8sfake.NewSimpleClientset().RbacV1().ClusterRoleBindings()
+
+	// creating it twice must not fail
+	for i := 0; i < 2; i++ {
+		if err := createClusterRoleBinding(context.Background(), bindings, "dashboard-admin", "cluster-admin", "kubernetes-dashboard", "dashboard-admin", nil); err != nil {
+			t.Fatalf("createClusterRoleBinding returned unexpected error: %v", err)
+		}
+	}
+	b, err := bindings.Get(context.Background(), "dashboard-admin", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the binding to exist: %v", err)
+	}
+	if b.RoleRef.Name != "cluster-admin" || len(b.Subjects) != 1 || b.Subjects[0].Namespace != "kubernetes-dashboard" {
+		t.Errorf("unexpected binding %+v", b)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := deleteClusterRoleBinding(context.Background(), bindings, "dashboard-admin"); err != nil {
+			t.Fatalf("deleteClusterRoleBinding returned unexpected error: %v", err)
+		}
+	}
+	if _, err := bindings.Get(context.Background(), "dashboard-admin", meta.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the binding to be deleted, got %v", err)
+	}
+}
+
+func TestPatchConfigMap(t *testing.T) {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: "settings", Namespace: "default"},
+		Data:       map[string]string{"keep": "me", "update": "old"},
+	}
+	configMaps := k8sfake.NewSimpleClientset(cm).CoreV1().ConfigMaps("default")
+
+	if err := patchConfigMap(context.Background(), configMaps, "settings", map[string]string{"update": "new", "add": "value"}); err != nil {
+		t.Fatalf("patchConfigMap returned unexpected error: %v", err)
+	}
+	got, err := configMaps.Get(context.Background(), "settings", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+	want := map[string]string{"keep": "me", "update": "new", "add": "value"}
+	if !reflect.DeepEqual(got.Data, want) {
+		t.Errorf("expected data %v but got %v", want, got.Data)
+	}
+
+	if err := patchConfigMap(context.Background(), configMaps, "missing", map[string]string{"add": "value"}); err == nil {
+		t.Errorf("expected an error patching a missing ConfigMap")
+	}
+}
+
+func TestGetConfigMapData(t *testing.T) {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+		Data:       map[string]string{"Corefile": ".:53 {}"},
+	}
+	configMaps := k8sfake.NewSimpleClientset(cm).CoreV1().ConfigMaps("kube-system")
+
+	data, err := getConfigMapData(context.Background(), configMaps, "coredns")
+	if err != nil || !reflect.DeepEqual(data, cm.Data) {
+		t.Errorf("expected data %v, got %v and error %v", cm.Data, data, err)
+	}
+	data, err = getConfigMapData(context.Background(), configMaps, "missing")
+	if err != nil || data != nil {
+		t.Errorf("expected no data for a missing ConfigMap, got %v and error %v", data, err)
+	}
+}
+
+func TestGetServicePorts(t *testing.T) {
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "backend", Namespace: "default"},
+		Spec:       core.ServiceSpec{Ports: []core.ServicePort{{Name: "http", Port: 8080}}},
+	}
+	services := k8sfake.NewSimpleClientset(svc).CoreV1().Services("default")
+
+	ports, err := getServicePorts(context.Background(), services, "backend")
+	if err != nil || !reflect.DeepEqual(ports, svc.Spec.Ports) {
+		t.Errorf("expected ports %v, got %v and error %v", svc.Spec.Ports, ports, err)
+	}
+	ports, err = getServicePorts(context.Background(), services, "missing")
+	if err != nil || ports != nil {
+		t.Errorf("expected no ports for a missing service, got %v and error %v", ports, err)
+	}
+}
+
+func TestCheckSecretExists(t *testing.T) {
+	secret := &core.Secret{ObjectMeta: meta.ObjectMeta{Name: "foo", Namespace: "default"}}
+	secrets := k8sfake.NewSimpleClientset(secret).CoreV1().Secrets("default")
+
+	exists, err := checkSecretExists(context.Background(), secrets, "foo")
+	if err != nil || !exists {
+		t.Errorf("expected secret foo to exist, got %t and error %v", exists, err)
+	}
+	exists, err = checkSecretExists(context.Background(), secrets, "bar")
+	if err != nil || exists {
+		t.Errorf("expected secret bar to not exist, got %t and error %v", exists, err)
+	}
+}
+
+func TestCheckSecretExistsRetries(t *testing.T) {
+	orig := secretRetryTimeout
+	secretRetryTimeout = time.Second
+	t.Cleanup(func() { secretRetryTimeout = orig })
+
+	gr := schema.GroupResource{Resource: "secrets"}
+	tests := []struct {
+		description string
+		errs        []error
+		exists      bool
+		wantErr     bool
+		wantCalls   int
+	}{
+		{description: "transient error then found", errs: []error{apierrors.NewServiceUnavailable("restarting")}, exists: true, wantCalls: 2},
+		{description: "transient error then not found", errs: []error{apierrors.NewTimeoutError("slow", 1), apierrors.NewNotFound(gr, "foo")}, wantCalls: 2},
+		{description: "permanent error", errs: []error{apierrors.NewForbidden(gr, "foo", fmt.Errorf("denied"))}, wantErr: true, wantCalls: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			client := k8sfake.NewSimpleClientset(&core.Secret{ObjectMeta: meta.ObjectMeta{Name: "foo", Namespace: "default"}})
+			calls := 0
+			client.PrependReactor("get", "secrets", func(testing_fake.Action) (bool, runtime.Object, error) {
+				calls++
+				if calls <= len(tc.errs) {
+					return true, nil, tc.errs[calls-1]
+				}
+				return false, nil, nil
+			})
+
+			exists, err := checkSecretExists(context.Background(), client.CoreV1().Secrets("default"), "foo")
+			if (err != nil) != tc.wantErr || exists != tc.exists {
+				t.Errorf("checkSecretExists() = %t, %v, want %t and error %t", exists, err, tc.exists, tc.wantErr)
+			}
+			if calls != tc.wantCalls {
+				t.Errorf("got %d calls to the apiserver, want %d", calls, tc.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetSecretData(t *testing.T) {
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{Name: "foo", Namespace: "default"},
+		Data:       map[string][]byte{"user": []byte("admin")},
+	}
+	secrets := k8sfake.NewSimpleClientset(secret).CoreV1().Secrets("default")
+
+	data, err := getSecretData(context.Background(), secrets, "foo")
+	if err != nil {
+		t.Fatalf("getSecretData returned unexpected error: %v", err)
+	}
+	if data["user"] != "admin" {
+		t.Errorf("expected user to be admin but got %q", data["user"])
+	}
+	data, err = getSecretData(context.Background(), secrets, "bar")
+	if err != nil || data != nil {
+		t.Errorf("expected no data for missing secret bar, got %v and error %v", data, err)
+	}
+}
+
 func TestCreateSecret(t *testing.T) {
 	var tests = []struct {
 		description, ns, name string
@@ -810,7 +1009,7 @@ func TestCreateSecret(t *testing.T) {
 				secretsMap:   secretsNamespaces,
 			}
 			getCoreClientFail = test.failedGetClient
-			err := CreateSecret("minikube", test.ns, test.name, map[string]string{"ns": "secret"}, map[string]string{"ns": "baz"})
+			err := CreateSecret(context.Background(), "minikube", test.ns, test.name, map[string]string{"ns": "secret"}, map[string]string{"ns": "baz"})
 			if err == nil && test.err {
 				t.Fatalf("Test %v expected error but got nil", test.description)
 			}
@@ -821,6 +1020,72 @@ func TestCreateSecret(t *testing.T) {
 	}
 }
 
+func TestEnsureNamespace(t *testing.T) {
+	var tests = []struct {
+		description, ns string
+		existing        []runtime.Object
+		created         bool
+	}{
+		{
+			description: "existing namespace",
+			ns:          "kube-system",
+			existing:    []runtime.Object{&core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "kube-system"}}},
+		},
+		{
+			description: "missing namespace",
+			ns:          "registry-creds",
+			existing:    []runtime.Object{&core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "kube-system"}}},
+			created:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.description, func(t *testing.T) {
+			client := k8sfake.NewSimpleClientset(test.existing...)
+			namespaces := client.CoreV1().Namespaces()
+			if err := ensureNamespace(context.Background(), namespaces, test.ns); err != nil {
+				t.Fatalf("Test %v got unexpected error: %v", test.description, err)
+			}
+			if _, err := namespaces.Get(context.Background(), test.ns, meta.GetOptions{}); err != nil {
+				t.Fatalf("Test %v expected namespace %s to exist: %v", test.description, test.ns, err)
+			}
+			created := false
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "create" {
+					created = true
+				}
+			}
+			if created != test.created {
+				t.Errorf("Test %v expected created to be %v but got %v", test.description, test.created, created)
+			}
+		})
+	}
+}
+
+func TestGetPodLogs(t *testing.T) {
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "registry-creds-abc",
+			Namespace: "kube-system",
+			Labels:    map[string]string{"name": "registry-creds"},
+		},
+	}
+	pods := k8sfake.NewSimpleClientset(pod).CoreV1().Pods("kube-system")
+
+	logs, err := getPodLogs(context.Background(), pods, "name=registry-creds", 10)
+	if err != nil {
+		t.Fatalf("getPodLogs returned unexpected error: %v", err)
+	}
+	// the fake client returns a fixed body for any logs request
+	if logs != "fake logs" {
+		t.Errorf("expected fake logs but got %q", logs)
+	}
+
+	if _, err := getPodLogs(context.Background(), pods, "name=missing", 10); err == nil {
+		t.Errorf("expected an error when no pod matches the selector")
+	}
+}
+
 func TestWaitAndMaybeOpenService(t *testing.T) {
 	defaultAPI := &tests.MockAPI{
 		FakeStore: tests.FakeStore{
@@ -945,7 +1210,7 @@ func TestWaitAndMaybeOpenService(t *testing.T) {
 			}()
 
 			var urlList []string
-			urlList, err := WaitForService(test.api, "minikube", test.namespace, test.service, defaultTemplate, test.urlMode, test.https, 5, 0)
+			urlList, err := WaitForService(context.Background(), test.api, "minikube", test.namespace, test.service, defaultTemplate, test.urlMode, test.https, 5, 0)
 			if test.err && err == nil {
 				t.Fatalf("WaitForService expected to fail for test: %v", test)
 			}
@@ -1010,7 +1275,7 @@ func TestWaitAndMaybeOpenServiceForNotDefaultNamspace(t *testing.T) {
 				servicesMap:  serviceNamespaceOther,
 				endpointsMap: endpointNamespaces,
 			}
-			_, err := WaitForService(test.api, "minikube", test.namespace, test.service, defaultTemplate, test.urlMode, test.https, 1, 0)
+			_, err := WaitForService(context.Background(), test.api, "minikube", test.namespace, test.service, defaultTemplate, test.urlMode, test.https, 1, 0)
 			if test.err && err == nil {
 				t.Fatalf("WaitForService expected to fail for test: %v", test)
 			}
@@ -1021,3 +1286,206 @@ func TestWaitAndMaybeOpenServiceForNotDefaultNamspace(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitForServiceCanceled(t *testing.T) {
+	defer revertK8sClient(K8s)
+	K8s = &MockClientGetter{
+		servicesMap:  serviceNamespaceOther,
+		endpointsMap: endpointNamespaces,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	tmpl := template.Must(template.New("svc-template").Parse("http://{{.IP}}:{{.Port}}"))
+	_, err := WaitForService(ctx, &tests.MockAPI{}, "minikube", "default", "non-namespace-dashboard-no-ports", tmpl, false, false, 60, 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitForService() = %v, want a context.Canceled error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("WaitForService kept retrying for %s after the context was canceled", elapsed)
+	}
+}
+
+func TestExposeContainerPorts(t *testing.T) {
+	d := &apps.Deployment{
+		ObjectMeta: meta.ObjectMeta{Name: "controller", Namespace: "ingress-nginx"},
+		Spec: apps.DeploymentSpec{Template: core.PodTemplateSpec{Spec: core.PodSpec{Containers: []core.Container{{
+			Name:  "controller",
+			Ports: []core.ContainerPort{{ContainerPort: 80, HostPort: 80}},
+		}}}}},
+	}
+	deployments := k8sfake.NewSimpleClientset(d).AppsV1().Deployments("ingress-nginx")
+
+	ports := []core.ContainerPort{
+		{Name: "tcp-80", ContainerPort: 80, HostPort: 80, Protocol: core.ProtocolTCP},
+		{Name: "tcp-6379", ContainerPort: 6379, HostPort: 6379, Protocol: core.ProtocolTCP},
+		{Name: "udp-6379", ContainerPort: 6379, HostPort: 6379, Protocol: core.ProtocolUDP},
+	}
+	for i := 0; i < 2; i++ {
+		if err := exposeContainerPorts(context.Background(), deployments, "controller", ports); err != nil {
+			t.Fatalf("exposeContainerPorts returned unexpected error: %v", err)
+		}
+	}
+	got, err := deployments.Get(context.Background(), "controller", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	want := []core.ContainerPort{{ContainerPort: 80, HostPort: 80}, ports[1], ports[2]}
+	if !reflect.DeepEqual(got.Spec.Template.Spec.Containers[0].Ports, want) {
+		t.Errorf("expected ports %v but got %v", want, got.Spec.Template.Spec.Containers[0].Ports)
+	}
+
+	if err := exposeContainerPorts(context.Background(), deployments, "missing", ports); err == nil {
+		t.Errorf("expected an error exposing the ports of a missing deployment")
+	}
+}
+
+func TestApplyLimitRange(t *testing.T) {
+	limitRanges := k8sfake.NewSimpleClientset().CoreV1().LimitRanges("lab")
+	spec := func(cpu string) core.LimitRangeSpec {
+		return core.LimitRangeSpec{Limits: []core.LimitRangeItem{{
+			Type:           core.LimitTypeContainer,
+			DefaultRequest: core.ResourceList{core.ResourceCPU: resource.MustParse(cpu)},
+		}}}
+	}
+	// the first call creates the LimitRange, the second one updates it
+	for _, cpu := range []string{"100m", "200m"} {
+		if err := applyLimitRange(context.Background(), limitRanges, "defaults", spec(cpu)); err != nil {
+			t.Fatalf("applyLimitRange returned unexpected error: %v", err)
+		}
+		got, err := limitRanges.Get(context.Background(), "defaults", meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get LimitRange: %v", err)
+		}
+		if q := got.Spec.Limits[0].DefaultRequest[core.ResourceCPU]; q.String() != cpu {
+			t.Errorf("expected a default CPU request of %s but got %s", cpu, q.String())
+		}
+	}
+}
+
+func TestCreateIngress(t *testing.T) {
+	ingresses := k8sfake.NewSimpleClientset().NetworkingV1().Ingresses("headlamp")
+	// the first call creates the Ingress, the second one updates it
+	for _, host := range []string{"headlamp.test", "ui.test"} {
+		spec := networking.IngressSpec{Rules: []networking.IngressRule{{Host: host}}}
+		if err := createIngress(context.Background(), ingresses, "headlamp", spec); err != nil {
+			t.Fatalf("createIngress returned unexpected error: %v", err)
+		}
+		got, err := ingresses.Get(context.Background(), "headlamp", meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get Ingress: %v", err)
+		}
+		if h := got.Spec.Rules[0].Host; h != host {
+			t.Errorf("expected the host %s but got %s", host, h)
+		}
+	}
+}
+
+func TestApplyResourceQuota(t *testing.T) {
+	quotas := k8sfake.NewSimpleClientset().CoreV1().ResourceQuotas("lab")
+	for _, memory := range []string{"1Gi", "2Gi"} {
+		spec := core.ResourceQuotaSpec{Hard: core.ResourceList{core.ResourceLimitsMemory: resource.MustParse(memory)}}
+		if err := applyResourceQuota(context.Background(), quotas, "quota", spec); err != nil {
+			t.Fatalf("applyResourceQuota returned unexpected error: %v", err)
+		}
+		got, err := quotas.Get(context.Background(), "quota", meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get ResourceQuota: %v", err)
+		}
+		if q := got.Spec.Hard[core.ResourceLimitsMemory]; q.String() != memory {
+			t.Errorf("expected a memory limit of %s but got %s", memory, q.String())
+		}
+	}
+}
+
+func TestUpdateNodes(t *testing.T) {
+	node := func(name string, labels map[string]string, taints ...core.Taint) *core.Node {
+		return &core.Node{ObjectMeta: meta.ObjectMeta{Name: name, Labels: labels}, Spec: core.NodeSpec{Taints: taints}}
+	}
+	nodes := k8sfake.NewSimpleClientset(
+		node("minikube", map[string]string{"pool": "default", "old": "x"}, core.Taint{Key: "gpu", Value: "false", Effect: core.TaintEffectNoSchedule}),
+		node("minikube-m02", nil, core.Taint{Key: "spot", Effect: core.TaintEffectNoExecute}),
+	).CoreV1().Nodes()
+
+	changes := NodeChanges{
+		Labels:       map[string]string{"pool": "gpu"},
+		RemoveLabels: []string{"old"},
+		Taints:       []core.Taint{{Key: "gpu", Value: "true", Effect: core.TaintEffectNoSchedule}},
+		RemoveTaints: []core.Taint{{Key: "spot"}},
+	}
+	updated, err := updateNodes(context.Background(), nodes, nil, changes)
+	if err != nil {
+		t.Fatalf("updateNodes returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updated, []string{"minikube", "minikube-m02"}) {
+		t.Errorf("updated nodes %v, want all the nodes", updated)
+	}
+	wantTaints := []core.Taint{{Key: "gpu", Value: "true", Effect: core.TaintEffectNoSchedule}}
+	for _, name := range updated {
+		n, err := nodes.Get(context.Background(), name, meta.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(n.Labels, map[string]string{"pool": "gpu"}) {
+			t.Errorf("labels of %s = %v, want only pool=gpu", name, n.Labels)
+		}
+		if !reflect.DeepEqual(n.Spec.Taints, wantTaints) {
+			t.Errorf("taints of %s = %v, want %v", name, n.Spec.Taints, wantTaints)
+		}
+	}
+
+	if _, err := updateNodes(context.Background(), nodes, []string{"missing"}, changes); err == nil {
+		t.Errorf("expected an error for a missing node")
+	}
+}
+
+func TestUpdateNodesConflict(t *testing.T) {
+	client := k8sfake.NewSimpleClientset(&core.Node{ObjectMeta: meta.ObjectMeta{Name: "minikube"}})
+	// the first update conflicts, as if the kubelet had updated the node since it was read
+	conflicts := 0
+	client.PrependReactor("update", "nodes", func(testing_fake.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		return true, nil, apierrors.NewConflict(core.Resource("nodes"), "minikube", errors.New("the object has been modified"))
+	})
+	nodes := client.CoreV1().Nodes()
+
+	updated, err := updateNodes(context.Background(), nodes, []string{"minikube"}, NodeChanges{Labels: map[string]string{"pool": "gpu"}})
+	if err != nil {
+		t.Fatalf("updateNodes returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(updated, []string{"minikube"}) {
+		t.Errorf("updated nodes %v, want minikube", updated)
+	}
+	n, err := nodes.Get(context.Background(), "minikube", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get node minikube: %v", err)
+	}
+	if n.Labels["pool"] != "gpu" {
+		t.Errorf("labels of minikube = %v, want pool=gpu", n.Labels)
+	}
+}
+
+func TestRestartDeployment(t *testing.T) {
+	d := &apps.Deployment{ObjectMeta: meta.ObjectMeta{Name: "controller", Namespace: "ingress-nginx"}}
+	deployments := k8sfake.NewSimpleClientset(d).AppsV1().Deployments("ingress-nginx")
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := restartDeployment(context.Background(), deployments, "controller", now); err != nil {
+		t.Fatalf("restartDeployment returned unexpected error: %v", err)
+	}
+	got, err := deployments.Get(context.Background(), "controller", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if a := got.Spec.Template.Annotations["kubectl.kubernetes.io/restartedAt"]; a != "2024-05-01T12:00:00Z" {
+		t.Errorf("expected the restartedAt annotation to be set, got %q", a)
+	}
+
+	if err := restartDeployment(context.Background(), deployments, "missing", now); err == nil {
+		t.Errorf("expected an error restarting a missing deployment")
+	}
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package util
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
 	"net"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"k8s.io/minikube/pkg/minikube/constants"
 )
@@ -133,3 +135,24 @@ func TestGenerateSignedCert(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	certPEM, keyPEM, err := GenerateSelfSignedCert("minikube-ingress", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateSelfSignedCert() error = %v", err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("the cert and key do not match: %v", err)
+	}
+	data, _ := pem.Decode(certPEM)
+	c, err := x509.ParseCertificate(data.Bytes)
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %v", err)
+	}
+	if c.IsCA || c.Subject.CommonName != "minikube-ingress" {
+		t.Errorf("expected a serving cert for minikube-ingress, got CA %t and CN %s", c.IsCA, c.Subject.CommonName)
+	}
+	if left := time.Until(c.NotAfter); left > 24*time.Hour || left < 23*time.Hour {
+		t.Errorf("expected the cert to expire in 24h, it expires in %s", left)
+	}
+}
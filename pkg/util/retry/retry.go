@@ -66,3 +66,6 @@ type RetriableError struct {
 }
 
 func (r RetriableError) Error() string { return "Temporary Error: " + r.Err.Error() }
+
+// Unwrap returns the underlying error, so the error can be inspected with errors.Is and errors.As
+func (r RetriableError) Unwrap() error { return r.Err }